@@ -2,16 +2,36 @@ package api
 
 import (
 	"context"
+
 	"github.com/yitech/okex"
 	"github.com/yitech/okex/api/rest"
 	"github.com/yitech/okex/api/ws"
 )
 
-// Client is the main api wrapper of okex
+// Client is the main api wrapper of okex.
+//
+// The API groups are exposed as interfaces as well, they can be replaced with fakes, decorators or a simulated exchange.
 type Client struct {
 	Rest *rest.ClientRest
 	Ws   *ws.ClientWs
-	ctx  context.Context
+	// WsBusiness is connected to the business endpoint, serving the algo order, deposit-info and grid channels among
+	// others
+	WsBusiness *ws.ClientWs
+	Trade      TradeAPI
+	Account    AccountAPI
+	SubAccount SubAccountAPI
+	Funding    FundingAPI
+	Finance    FinanceAPI
+	Convert    ConvertAPI
+	TradingBot TradingBotAPI
+	Market     MarketAPI
+	PublicData PublicDataAPI
+	TradeData  TradeDataAPI
+	Public     PublicStream
+	Private    PrivateStream
+	Business   PrivateStream
+	WsTrade    TradeStream
+	ctx        context.Context
 }
 
 // NewClient returns a pointer to a fresh Client
@@ -19,19 +39,42 @@ func NewClient(ctx context.Context, apiKey, secretKey, passphrase string, destin
 	restURL := okex.RestURL
 	wsPubURL := okex.PublicWsURL
 	wsPriURL := okex.PrivateWsURL
+	wsBizURL := okex.BusinessWsURL
 	switch destination {
 	case okex.AwsServer:
 		restURL = okex.AwsRestURL
 		wsPubURL = okex.AwsPublicWsURL
 		wsPriURL = okex.AwsPrivateWsURL
+		wsBizURL = okex.AwsBusinessWsURL
 	case okex.DemoServer:
 		restURL = okex.DemoRestURL
 		wsPubURL = okex.DemoPublicWsURL
 		wsPriURL = okex.DemoPrivateWsURL
+		wsBizURL = okex.DemoBusinessWsURL
 	}
 
 	r := rest.NewClient(apiKey, secretKey, passphrase, restURL, destination)
 	c := ws.NewClient(ctx, apiKey, secretKey, passphrase, map[bool]okex.BaseURL{true: wsPriURL, false: wsPubURL})
+	b := ws.NewClient(ctx, apiKey, secretKey, passphrase, map[bool]okex.BaseURL{true: wsBizURL, false: wsBizURL})
 
-	return &Client{r, c, ctx}, nil
+	return &Client{
+		Rest:       r,
+		Ws:         c,
+		WsBusiness: b,
+		Trade:      r.Trade,
+		Account:    r.Account,
+		SubAccount: r.SubAccount,
+		Funding:    r.Funding,
+		Finance:    r.Finance,
+		Convert:    r.Convert,
+		TradingBot: r.TradingBot,
+		Market:     r.Market,
+		PublicData: r.PublicData,
+		TradeData:  r.TradeData,
+		Public:     c.Public,
+		Private:    c.Private,
+		Business:   b.Private,
+		WsTrade:    c.Trade,
+		ctx:        ctx,
+	}, nil
 }
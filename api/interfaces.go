@@ -0,0 +1,307 @@
+package api
+
+import (
+	"github.com/yitech/okex/api/rest"
+	"github.com/yitech/okex/api/ws"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/events/public"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	convertRequests "github.com/yitech/okex/requests/rest/convert"
+	financeRequests "github.com/yitech/okex/requests/rest/finance"
+	fundingRequests "github.com/yitech/okex/requests/rest/funding"
+	marketRequests "github.com/yitech/okex/requests/rest/market"
+	publicRequests "github.com/yitech/okex/requests/rest/public"
+	subAccountRequests "github.com/yitech/okex/requests/rest/subaccount"
+	tradeRequests "github.com/yitech/okex/requests/rest/trade"
+	tradeDataRequests "github.com/yitech/okex/requests/rest/tradedata"
+	tradingBotRequests "github.com/yitech/okex/requests/rest/tradingbot"
+	privateWsRequests "github.com/yitech/okex/requests/ws/private"
+	publicWsRequests "github.com/yitech/okex/requests/ws/public"
+	tradeWsRequests "github.com/yitech/okex/requests/ws/trade"
+	accountResponses "github.com/yitech/okex/responses/account"
+	convertResponses "github.com/yitech/okex/responses/convert"
+	financeResponses "github.com/yitech/okex/responses/finance"
+	fundingResponses "github.com/yitech/okex/responses/funding"
+	marketResponses "github.com/yitech/okex/responses/market"
+	publicDataResponses "github.com/yitech/okex/responses/public_data"
+	subAccountResponses "github.com/yitech/okex/responses/sub_account"
+	tradeResponses "github.com/yitech/okex/responses/trade"
+	tradeDataResponses "github.com/yitech/okex/responses/trade_data"
+	tradingBotResponses "github.com/yitech/okex/responses/tradingbot"
+)
+
+type (
+	// OrderAPI places, amends, cancels and queries orders. It is the part of TradeAPI a simulated exchange implements.
+	OrderAPI interface {
+		PlaceOrder(req []tradeRequests.PlaceOrderRequest) (response tradeResponses.PlaceOrderResponse, err error)
+		PlaceMultipleOrders(req []tradeRequests.PlaceOrderRequest) (response tradeResponses.PlaceOrderResponse, err error)
+		CancelOrder(req tradeRequests.CancelOrderRequest) (response tradeResponses.CancelOrderResponse, err error)
+		CancelBatchOrders(req []tradeRequests.CancelOrderRequest) (response tradeResponses.CancelOrderResponse, err error)
+		AmendOrder(req []tradeRequests.AmendOrderRequest) (response tradeResponses.AmendOrderResponse, err error)
+		ClosePosition(req tradeRequests.ClosePositionRequest) (response tradeResponses.ClosePositionResponse, err error)
+		GetOrderDetail(req tradeRequests.OrderDetailsRequest) (response tradeResponses.OrderListResponse, err error)
+		GetOrderList(req tradeRequests.OrderListRequest) (response tradeResponses.OrderListResponse, err error)
+		GetOrderHistory(req tradeRequests.OrderListRequest, arch bool) (response tradeResponses.OrderListResponse, err error)
+		GetTransactionDetails(req tradeRequests.TransactionDetailsRequest, arch bool) (response tradeResponses.TransactionDetailResponse, err error)
+	}
+
+	// AlgoOrderAPI places, cancels and queries algo orders
+	AlgoOrderAPI interface {
+		PlaceAlgoOrder(req tradeRequests.PlaceAlgoOrderRequest) (response tradeResponses.PlaceAlgoOrderResponse, err error)
+		AmendAlgoOrder(req tradeRequests.AmendAlgoOrderRequest) (response tradeResponses.AmendAlgoOrderResponse, err error)
+		CancelAlgoOrder(req tradeRequests.CancelAlgoOrderRequest) (response tradeResponses.CancelAlgoOrderResponse, err error)
+		CancelAlgoOrders(req []tradeRequests.CancelAlgoOrderRequest) (response tradeResponses.CancelAlgoOrderResponse, err error)
+		CancelAdvanceAlgoOrder(req tradeRequests.CancelAlgoOrderRequest) (response tradeResponses.CancelAlgoOrderResponse, err error)
+		GetAlgoOrderDetail(req tradeRequests.AlgoOrderDetailsRequest) (response tradeResponses.AlgoOrderListResponse, err error)
+		GetAlgoOrderList(req tradeRequests.AlgoOrderListRequest, arch bool) (response tradeResponses.AlgoOrderListResponse, err error)
+	}
+
+	// TradeAPI is implemented by rest.Trade
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-trade
+	TradeAPI interface {
+		OrderAPI
+		AlgoOrderAPI
+		CancelAllAfter(req tradeRequests.CancelAllAfterRequest) (response tradeResponses.CancelAllAfterResponse, err error)
+	}
+
+	// AccountAPI is implemented by rest.Account
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-account
+	AccountAPI interface {
+		GetBalance(req accountRequests.GetBalanceRequest) (response accountResponses.GetBalanceResponse, err error)
+		GetPositions(req accountRequests.GetPositionsRequest) (response accountResponses.GetPositionsResponse, err error)
+		GetAccountAndPositionRisk(req accountRequests.GetAccountAndPositionRiskRequest) (response accountResponses.GetAccountAndPositionRiskResponse, err error)
+		GetBills(req accountRequests.GetBillsRequest, arc bool) (response accountResponses.GetBillsResponse, err error)
+		GetConfig() (response accountResponses.GetConfigResponse, err error)
+		SetPositionMode(req accountRequests.SetPositionModeRequest) (response accountResponses.SetPositionModeResponse, err error)
+		SetLeverage(req accountRequests.SetLeverageRequest) (response accountResponses.LeverageResponse, err error)
+		GetMaxBuySellAmount(req accountRequests.GetMaxBuySellAmountRequest) (response accountResponses.GetMaxBuySellAmountResponse, err error)
+		GetMaxAvailableTradeAmount(req accountRequests.GetMaxAvailableTradeAmountRequest) (response accountResponses.GetMaxAvailableTradeAmountResponse, err error)
+		IncreaseDecreaseMargin(req accountRequests.IncreaseDecreaseMarginRequest) (response accountResponses.IncreaseDecreaseMarginResponse, err error)
+		GetLeverage(req accountRequests.GetLeverageRequest) (response accountResponses.LeverageResponse, err error)
+		GetMaxLoan(req accountRequests.GetMaxLoanRequest) (response accountResponses.GetMaxLoanResponse, err error)
+		GetFeeRates(req accountRequests.GetFeeRatesRequest) (response accountResponses.GetFeeRatesResponse, err error)
+		GetInterestAccrued(req accountRequests.GetInterestAccruedRequest) (response accountResponses.GetInterestAccruedResponse, err error)
+		GetInterestRates(req accountRequests.GetInterestAccruedRequest) (response accountResponses.GetInterestAccruedResponse, err error)
+		SetGreeks(req accountRequests.SetGreeksRequest) (response accountResponses.SetGreeksResponse, err error)
+		GetMaxWithdrawals(req accountRequests.GetBalanceRequest) (response accountResponses.GetMaxWithdrawalsResponse, err error)
+	}
+
+	// SubAccountAPI is implemented by rest.SubAccount
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-subaccount
+	SubAccountAPI interface {
+		ViewList(req subAccountRequests.ViewList) (response subAccountResponses.ViewList, err error)
+		CreateAPIKey(req subAccountRequests.CreateAPIKey) (response subAccountResponses.APIKey, err error)
+		QueryAPIKey(req subAccountRequests.QueryAPIKey) (response subAccountResponses.APIKey, err error)
+		ResetAPIKey(req subAccountRequests.CreateAPIKey) (response subAccountResponses.APIKey, err error)
+		DeleteAPIKey(req subAccountRequests.DeleteAPIKey) (response subAccountResponses.APIKey, err error)
+		GetBalance(req subAccountRequests.GetBalance) (response subAccountResponses.GetBalance, err error)
+		HistoryTransfer(req subAccountRequests.HistoryTransfer) (response subAccountResponses.HistoryTransfer, err error)
+		ManageTransfers(req subAccountRequests.ManageTransfers) (response subAccountResponses.ManageTransfer, err error)
+	}
+
+	// FundingAPI is implemented by rest.Funding
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-funding
+	FundingAPI interface {
+		GetCurrencies() (response fundingResponses.GetCurrencies, err error)
+		GetBalance(req fundingRequests.GetBalance) (response fundingResponses.GetBalance, err error)
+		FundsTransfer(req fundingRequests.FundsTransfer) (response fundingResponses.FundsTransfer, err error)
+		AssetBillsDetails(req fundingRequests.AssetBillsDetails) (response fundingResponses.AssetBillsDetails, err error)
+		GetDepositAddress(req fundingRequests.GetDepositAddress) (response fundingResponses.GetDepositAddress, err error)
+		GetDepositHistory(req fundingRequests.GetDepositHistory) (response fundingResponses.GetDepositHistory, err error)
+		Withdrawal(req fundingRequests.Withdrawal) (response fundingResponses.Withdrawal, err error)
+		CancelWithdrawal(req fundingRequests.CancelWithdrawal) (response fundingResponses.CancelWithdrawal, err error)
+		GetWithdrawalHistory(req fundingRequests.GetWithdrawalHistory) (response fundingResponses.GetWithdrawalHistory, err error)
+		PiggyBankPurchaseRedemption(req fundingRequests.PiggyBankPurchaseRedemption) (response fundingResponses.PiggyBankPurchaseRedemption, err error)
+		GetPiggyBankBalance(req fundingRequests.GetPiggyBankBalance) (response fundingResponses.GetPiggyBankBalance, err error)
+	}
+
+	// FinanceAPI is implemented by rest.Finance
+	//
+	// https://www.okx.com/docs-v5/en/#financial-product
+	FinanceAPI interface {
+		GetSavingsBalance(req financeRequests.GetSavingsBalance) (response financeResponses.GetSavingsBalance, err error)
+		SavingsPurchaseRedemption(req financeRequests.SavingsPurchaseRedemption) (response financeResponses.SavingsPurchaseRedemption, err error)
+		SetLendingRate(req financeRequests.SetLendingRate) (response financeResponses.SetLendingRate, err error)
+		GetLendingHistory(req financeRequests.GetLendingHistory) (response financeResponses.GetLendingHistory, err error)
+		GetLendingRateSummary(req financeRequests.GetLendingRateSummary) (response financeResponses.GetLendingRateSummary, err error)
+		GetLendingRateHistory(req financeRequests.GetLendingRateHistory) (response financeResponses.GetLendingRateHistory, err error)
+		GetStakingOffers(req financeRequests.GetStakingOffers) (response financeResponses.GetStakingOffers, err error)
+		StakingPurchase(req financeRequests.StakingPurchase) (response financeResponses.StakingOrderResult, err error)
+		StakingRedeem(req financeRequests.StakingRedeem) (response financeResponses.StakingOrderResult, err error)
+		StakingCancel(req financeRequests.StakingCancel) (response financeResponses.StakingOrderResult, err error)
+		GetStakingActiveOrders(req financeRequests.GetStakingActiveOrders) (response financeResponses.GetStakingOrders, err error)
+		GetStakingOrderHistory(req financeRequests.GetStakingOrderHistory) (response financeResponses.GetStakingOrders, err error)
+	}
+
+	// ConvertAPI is implemented by rest.Convert
+	//
+	// https://www.okx.com/docs-v5/en/#funding-account-rest-api-get-convert-currencies
+	ConvertAPI interface {
+		GetCurrencies() (response convertResponses.GetCurrencies, err error)
+		GetCurrencyPair(req convertRequests.GetCurrencyPair) (response convertResponses.GetCurrencyPair, err error)
+		EstimateQuote(req convertRequests.EstimateQuote) (response convertResponses.EstimateQuote, err error)
+		Trade(req convertRequests.Trade) (response convertResponses.Trade, err error)
+		GetHistory(req convertRequests.GetHistory) (response convertResponses.Trade, err error)
+	}
+
+	// TradingBotAPI is implemented by rest.TradingBot
+	//
+	// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading
+	TradingBotAPI interface {
+		PlaceGridOrder(req tradingBotRequests.PlaceGridOrder) (response tradingBotResponses.GridOrderResult, err error)
+		AmendGridOrder(req tradingBotRequests.AmendGridOrder) (response tradingBotResponses.GridOrderResult, err error)
+		StopGridOrder(req []tradingBotRequests.StopGridOrder) (response tradingBotResponses.GridOrderResult, err error)
+		GetGridOrders(req tradingBotRequests.GetGridOrders) (response tradingBotResponses.GetGridOrders, err error)
+		GetGridOrderHistory(req tradingBotRequests.GetGridOrders) (response tradingBotResponses.GetGridOrders, err error)
+		GetGridOrderDetails(req tradingBotRequests.GetGridOrderDetails) (response tradingBotResponses.GetGridOrders, err error)
+		GetGridSubOrders(req tradingBotRequests.GetGridSubOrders) (response tradingBotResponses.GetGridSubOrders, err error)
+		GetGridPositions(req tradingBotRequests.GetGridPositions) (response tradingBotResponses.GetGridPositions, err error)
+		GetGridAIParam(req tradingBotRequests.GetGridAIParam) (response tradingBotResponses.GetGridAIParam, err error)
+		ComputeMinInvestment(req tradingBotRequests.ComputeMinInvestment) (response tradingBotResponses.ComputeMinInvestment, err error)
+	}
+
+	// MarketAPI is implemented by rest.Market
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-market-data
+	MarketAPI interface {
+		GetTickers(req marketRequests.GetTickersRequest) (response marketResponses.TickerResponse, err error)
+		GetTicker(req marketRequests.GetTickerRequest) (response marketResponses.TickerResponse, err error)
+		GetIndexTickers(req marketRequests.GetIndexTickersRequest) (response marketResponses.IndexTickerResponse, err error)
+		GetOrderBook(req marketRequests.GetOrderBookRequest) (response marketResponses.OrderBookResponse, err error)
+		GetCandlesticks(req marketRequests.GetCandlesticksRequest) (response marketResponses.CandleResponse, err error)
+		GetCandlesticksHistory(req marketRequests.GetCandlesticksRequest) (response marketResponses.CandleResponse, err error)
+		GetIndexCandlesticks(req marketRequests.GetCandlesticksRequest) (response marketResponses.IndexCandleResponse, err error)
+		GetMarkPriceCandlesticks(req marketRequests.GetCandlesticksRequest) (response marketResponses.CandleMarketResponse, err error)
+		GetTrades(req marketRequests.GetTradesRequest) (response marketResponses.TradeResponse, err error)
+		Get24HTotalVolume() (response marketResponses.TotalVolume24HResponse, err error)
+		GetIndexComponents(req marketRequests.GetIndexComponentsRequest) (response marketResponses.IndexComponentResponse, err error)
+	}
+
+	// PublicDataAPI is implemented by rest.PublicData
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-public-data
+	PublicDataAPI interface {
+		GetInstruments(req publicRequests.GetInstruments) (response publicDataResponses.GetInstruments, err error)
+		GetDeliveryExerciseHistory(req publicRequests.GetDeliveryExerciseHistory) (response publicDataResponses.GetDeliveryExerciseHistory, err error)
+		GetOpenInterest(req publicRequests.GetOpenInterest) (response publicDataResponses.GetOpenInterest, err error)
+		GetFundingRate(req publicRequests.GetFundingRate) (response publicDataResponses.GetFundingRate, err error)
+		GetFundingRateHistory(req publicRequests.GetFundingRateHistory) (response publicDataResponses.GetFundingRateHistory, err error)
+		GetLimitPrice(req publicRequests.GetLimitPrice) (response publicDataResponses.GetLimitPrice, err error)
+		GetOptionMarketData(req publicRequests.GetOptionMarketData) (response publicDataResponses.GetOptionMarketData, err error)
+		GetEstimatedDeliveryExercisePrice(req publicRequests.GetEstimatedDeliveryExercisePrice) (response publicDataResponses.GetEstimatedDeliveryExercisePrice, err error)
+		GetDiscountRateAndInterestFreeQuota(req publicRequests.GetDiscountRateAndInterestFreeQuota) (response publicDataResponses.GetDiscountRateAndInterestFreeQuota, err error)
+		GetSystemTime() (response publicDataResponses.GetSystemTime, err error)
+		GetLiquidationOrders(req publicRequests.GetLiquidationOrders) (response publicDataResponses.GetLiquidationOrders, err error)
+		GetMarkPrice(req publicRequests.GetMarkPrice) (response publicDataResponses.GetMarkPrice, err error)
+		GetPositionTiers(req publicRequests.GetPositionTiers) (response publicDataResponses.GetPositionTiers, err error)
+		GetInterestRateAndLoanQuota() (response publicDataResponses.GetInterestRateAndLoanQuota, err error)
+		GetUnderlying(req publicRequests.GetUnderlying) (response publicDataResponses.GetUnderlying, err error)
+	}
+
+	// TradeDataAPI is implemented by rest.TradeData
+	//
+	// https://www.okx.com/docs-v5/en/#rest-api-trading-data
+	TradeDataAPI interface {
+		GetSupportCoin() (response tradeDataResponses.GetSupportCoin, err error)
+		GetTakerVolume(req tradeDataRequests.GetTakerVolume) (response tradeDataResponses.GetTakerVolume, err error)
+		GetMarginLendingRatio(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetRatio, err error)
+		GetLongShortRatio(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetRatio, err error)
+		GetContractsOpenInterestAndVolume(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetOpenInterestAndVolume, err error)
+		GetOptionsOpenInterestAndVolume(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetOpenInterestAndVolume, err error)
+		GetPutCallRatio(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetPutCallRatio, err error)
+		GetOpenInterestAndVolumeExpiry(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetOpenInterestAndVolumeExpiry, err error)
+		GetOpenInterestAndVolumeStrike(req tradeDataRequests.GetOpenInterestAndVolumeStrike) (response tradeDataResponses.GetOpenInterestAndVolumeStrike, err error)
+		GetTakerFlow(req tradeDataRequests.GetRatio) (response tradeDataResponses.GetTakerFlow, err error)
+	}
+
+	// PublicStream is implemented by ws.Public
+	//
+	// https://www.okx.com/docs-v5/en/#websocket-api-public-channels
+	PublicStream interface {
+		Instruments(req publicWsRequests.Instruments, ch ...chan *public.Instruments) error
+		UInstruments(req publicWsRequests.Instruments, rCh ...bool) error
+		Tickers(req publicWsRequests.Tickers, ch ...chan *public.Tickers) error
+		UTickers(req publicWsRequests.Tickers, rCh ...bool) error
+		OpenInterest(req publicWsRequests.OpenInterest, ch ...chan *public.OpenInterest) error
+		UOpenInterest(req publicWsRequests.OpenInterest, rCh ...bool) error
+		Candlesticks(req publicWsRequests.Candlesticks, ch ...chan *public.Candlesticks) error
+		UCandlesticks(req publicWsRequests.Candlesticks, rCh ...bool) error
+		Trades(req publicWsRequests.Trades, ch ...chan *public.Trades) error
+		UTrades(req publicWsRequests.Trades, rCh ...bool) error
+		EstimatedDeliveryExercisePrice(req publicWsRequests.EstimatedDeliveryExercisePrice, ch ...chan *public.EstimatedDeliveryExercisePrice) error
+		UEstimatedDeliveryExercisePrice(req publicWsRequests.EstimatedDeliveryExercisePrice, rCh ...bool) error
+		MarkPrice(req publicWsRequests.MarkPrice, ch ...chan *public.MarkPrice) error
+		UMarkPrice(req publicWsRequests.MarkPrice, rCh ...bool) error
+		MarkPriceCandlesticks(req publicWsRequests.MarkPriceCandlesticks, ch ...chan *public.MarkPriceCandlesticks) error
+		UMarkPriceCandlesticks(req publicWsRequests.MarkPriceCandlesticks, rCh ...bool) error
+		PriceLimit(req publicWsRequests.PriceLimit, ch ...chan *public.PriceLimit) error
+		UPriceLimit(req publicWsRequests.PriceLimit, rCh ...bool) error
+		OrderBook(reqs []publicWsRequests.OrderBook, ch ...chan *public.OrderBook) error
+		UOrderBook(req publicWsRequests.OrderBook, rCh ...bool) error
+		OPTIONSummary(req publicWsRequests.OPTIONSummary, ch ...chan *public.OPTIONSummary) error
+		UOPTIONSummary(req publicWsRequests.OPTIONSummary, rCh ...bool) error
+		FundingRate(req publicWsRequests.FundingRate, ch ...chan *public.FundingRate) error
+		UFundingRate(req publicWsRequests.FundingRate, rCh ...bool) error
+		IndexCandlesticks(req publicWsRequests.IndexCandlesticks, ch ...chan *public.IndexCandlesticks) error
+		UIndexCandlesticks(req publicWsRequests.IndexCandlesticks, rCh ...bool) error
+		IndexTickers(req publicWsRequests.IndexTickers, ch ...chan *public.IndexTickers) error
+		UIndexTickers(req publicWsRequests.IndexTickers, rCh ...bool) error
+	}
+
+	// PrivateStream is implemented by ws.Private
+	//
+	// https://www.okx.com/docs-v5/en/#websocket-api-private-channel
+	PrivateStream interface {
+		Account(req privateWsRequests.Account, ch ...chan *private.Account) error
+		UAccount(req privateWsRequests.Account, rCh ...bool) error
+		Position(req privateWsRequests.Position, ch ...chan *private.Position) error
+		UPosition(req privateWsRequests.Position, rCh ...bool) error
+		BalanceAndPosition(ch ...chan *private.BalanceAndPosition) error
+		UBalanceAndPosition(rCh ...bool) error
+		Order(req privateWsRequests.Order, ch ...chan *private.Order) error
+		UOrder(req privateWsRequests.Order, rCh ...bool) error
+		AlgoOrder(req privateWsRequests.AlgoOrder, ch ...chan *private.AlgoOrder) error
+		UAlgoOrder(req privateWsRequests.AlgoOrder, rCh ...bool) error
+		AdvanceAlgoOrder(req privateWsRequests.AdvanceAlgoOrder, ch ...chan *private.AdvanceAlgoOrder) error
+		UAdvanceAlgoOrder(req privateWsRequests.AdvanceAlgoOrder, rCh ...bool) error
+		DepositInfo(req privateWsRequests.DepositInfo, ch ...chan *private.DepositInfo) error
+		UDepositInfo(req privateWsRequests.DepositInfo, rCh ...bool) error
+		GridSpotOrders(req privateWsRequests.GridOrders, ch ...chan *private.GridOrders) error
+		UGridSpotOrders(req privateWsRequests.GridOrders, rCh ...bool) error
+		GridContractOrders(req privateWsRequests.GridOrders, ch ...chan *private.GridOrders) error
+		UGridContractOrders(req privateWsRequests.GridOrders, rCh ...bool) error
+		GridPositions(req privateWsRequests.GridPositions, ch ...chan *private.GridPositions) error
+		UGridPositions(req privateWsRequests.GridPositions, rCh ...bool) error
+		GridSubOrders(req privateWsRequests.GridSubOrders, ch ...chan *private.GridSubOrders) error
+		UGridSubOrders(req privateWsRequests.GridSubOrders, rCh ...bool) error
+	}
+
+	// TradeStream is implemented by ws.Trade
+	//
+	// https://www.okx.com/docs-v5/en/#websocket-api-trade
+	TradeStream interface {
+		PlaceOrder(req ...tradeWsRequests.PlaceOrder) error
+		CancelOrder(req ...tradeWsRequests.CancelOrder) error
+		AmendOrder(req ...tradeWsRequests.AmendOrder) error
+	}
+)
+
+var (
+	_ TradeAPI      = (*rest.Trade)(nil)
+	_ AccountAPI    = (*rest.Account)(nil)
+	_ SubAccountAPI = (*rest.SubAccount)(nil)
+	_ FundingAPI    = (*rest.Funding)(nil)
+	_ FinanceAPI    = (*rest.Finance)(nil)
+	_ ConvertAPI    = (*rest.Convert)(nil)
+	_ TradingBotAPI = (*rest.TradingBot)(nil)
+	_ MarketAPI     = (*rest.Market)(nil)
+	_ PublicDataAPI = (*rest.PublicData)(nil)
+	_ TradeDataAPI  = (*rest.TradeData)(nil)
+	_ PublicStream  = (*ws.Public)(nil)
+	_ PrivateStream = (*ws.Private)(nil)
+	_ TradeStream   = (*ws.Trade)(nil)
+)
@@ -96,7 +96,7 @@ func (c *Account) GetAccountAndPositionRisk(req requests.GetAccountAndPositionRi
 func (c *Account) GetBills(req requests.GetBillsRequest, arc bool) (response responses.GetBillsResponse, err error) {
 	p := "/api/v5/account/bills"
 	if arc {
-		p = "/api/account/bills-archive"
+		p = "/api/v5/account/bills-archive"
 	}
 	m := okex.S2M(req)
 	res, err := c.client.Do(http.MethodGet, p, true, m)
@@ -22,6 +22,9 @@ type ClientRest struct {
 	SubAccount  *SubAccount
 	Trade       *Trade
 	Funding     *Funding
+	Finance     *Finance
+	Convert     *Convert
+	TradingBot  *TradingBot
 	Market      *Market
 	PublicData  *PublicData
 	TradeData   *TradeData
@@ -31,6 +34,8 @@ type ClientRest struct {
 	destination okex.Destination
 	baseURL     okex.BaseURL
 	client      *http.Client
+	limiter     Limiter
+	account     string
 }
 
 // NewClient returns a pointer to a fresh ClientRest
@@ -47,6 +52,9 @@ func NewClient(apiKey, secretKey, passphrase string, baseURL okex.BaseURL, desti
 	c.SubAccount = NewSubAccount(c)
 	c.Trade = NewTrade(c)
 	c.Funding = NewFunding(c)
+	c.Finance = NewFinance(c)
+	c.Convert = NewConvert(c)
+	c.TradingBot = NewTradingBot(c)
 	c.Market = NewMarket(c)
 	c.PublicData = NewPublicData(c)
 	c.TradeData = NewTradeData(c)
@@ -55,6 +63,7 @@ func NewClient(apiKey, secretKey, passphrase string, baseURL okex.BaseURL, desti
 
 // Do the http request to the server
 func (c *ClientRest) Do(method, path string, private bool, params ...map[string]string) (*http.Response, error) {
+	c.wait(private, path)
 	u := fmt.Sprintf("%s%s", c.baseURL, path)
 	var (
 		r    *http.Request
@@ -128,6 +137,7 @@ func (c *ClientRest) Status(req requests.Status) (response responses.Status, err
 
 // DoRawBody allows sending a raw JSON body (for batch endpoints)
 func (c *ClientRest) DoRawBody(method, path string, private bool, body []byte) (*http.Response, error) {
+	c.wait(private, path)
 	u := fmt.Sprintf("%s%s", c.baseURL, path)
 	r, err := http.NewRequest(method, u, bytes.NewBuffer(body))
 	if err != nil {
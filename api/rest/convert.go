@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yitech/okex"
+	requests "github.com/yitech/okex/requests/rest/convert"
+	responses "github.com/yitech/okex/responses/convert"
+)
+
+// Convert
+// Quote-and-trade conversion between currencies of the funding account, outside of the order books
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-get-convert-currencies
+type Convert struct {
+	client *ClientRest
+}
+
+// NewConvert returns a pointer to a fresh Convert
+func NewConvert(c *ClientRest) *Convert {
+	return &Convert{c}
+}
+
+// GetCurrencies
+// Get the currencies that can be converted, with their limits.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-get-convert-currencies
+func (c *Convert) GetCurrencies() (response responses.GetCurrencies, err error) {
+	p := "/api/v5/asset/convert/currencies"
+	res, err := c.client.Do(http.MethodGet, p, true)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetCurrencyPair
+// Get the pair converting between two currencies, with the limits on both sides.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-get-convert-currency-pair
+func (c *Convert) GetCurrencyPair(req requests.GetCurrencyPair) (response responses.GetCurrencyPair, err error) {
+	p := "/api/v5/asset/convert/currency-pair"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// EstimateQuote
+// Request a quote, valid for its ttlMs from its quoteTime.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-estimate-quote
+func (c *Convert) EstimateQuote(req requests.EstimateQuote) (response responses.EstimateQuote, err error) {
+	p := "/api/v5/asset/convert/estimate-quote"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// Trade
+// Execute a quote. The trade is filled in full at the quoted price or rejected.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-convert-trade
+func (c *Convert) Trade(req requests.Trade) (response responses.Trade, err error) {
+	p := "/api/v5/asset/convert/trade"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetHistory
+// Get the conversions of the last 3 months, in reverse chronological order.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-rest-api-get-convert-history
+func (c *Convert) GetHistory(req requests.GetHistory) (response responses.Trade, err error) {
+	p := "/api/v5/asset/convert/history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
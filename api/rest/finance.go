@@ -0,0 +1,233 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yitech/okex"
+	requests "github.com/yitech/okex/requests/rest/finance"
+	responses "github.com/yitech/okex/responses/finance"
+)
+
+// Finance
+// Simple Earn savings and on-chain earn (staking and DeFi) products
+//
+// https://www.okx.com/docs-v5/en/#financial-product
+type Finance struct {
+	client *ClientRest
+}
+
+// NewFinance returns a pointer to a fresh Finance
+func NewFinance(c *ClientRest) *Finance {
+	return &Finance{c}
+}
+
+// GetSavingsBalance
+// Get the balance of the savings account, its earnings and lending rate.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-get-saving-balance
+func (c *Finance) GetSavingsBalance(req requests.GetSavingsBalance) (response responses.GetSavingsBalance, err error) {
+	p := "/api/v5/finance/savings/balance"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// SavingsPurchaseRedemption
+// Purchase savings from, or redeem them to, the funding account. Only the assets in the funding account can be used for purchase.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-post-savings-purchase-redemption
+func (c *Finance) SavingsPurchaseRedemption(req requests.SavingsPurchaseRedemption) (response responses.SavingsPurchaseRedemption, err error) {
+	p := "/api/v5/finance/savings/purchase-redempt"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// SetLendingRate
+// Set the minimum annual lending rate of the savings of a currency.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-post-set-lending-rate
+func (c *Finance) SetLendingRate(req requests.SetLendingRate) (response responses.SetLendingRate, err error) {
+	p := "/api/v5/finance/savings/set-lending-rate"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetLendingHistory
+// Return the lending history of the savings over the last month, in reverse chronological order.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-get-lending-history
+func (c *Finance) GetLendingHistory(req requests.GetLendingHistory) (response responses.GetLendingHistory, err error) {
+	p := "/api/v5/finance/savings/lending-history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetLendingRateSummary
+// Public lending rate summary of the savings market.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-get-public-borrow-info-public
+func (c *Finance) GetLendingRateSummary(req requests.GetLendingRateSummary) (response responses.GetLendingRateSummary, err error) {
+	p := "/api/v5/finance/savings/lending-rate-summary"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, false, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetLendingRateHistory
+// Public lending rate history of the savings market.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-savings-get-public-borrow-history-public
+func (c *Finance) GetLendingRateHistory(req requests.GetLendingRateHistory) (response responses.GetLendingRateHistory, err error) {
+	p := "/api/v5/finance/savings/lending-rate-history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, false, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetStakingOffers
+// Get the on-chain earn offers, staking and DeFi products.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-get-offers
+func (c *Finance) GetStakingOffers(req requests.GetStakingOffers) (response responses.GetStakingOffers, err error) {
+	p := "/api/v5/finance/staking-defi/offers"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// StakingPurchase
+// Invest in an on-chain earn offer. Only the assets in the funding account can be used.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-post-purchase
+func (c *Finance) StakingPurchase(req requests.StakingPurchase) (response responses.StakingOrderResult, err error) {
+	p := "/api/v5/finance/staking-defi/purchase"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// StakingRedeem
+// Redeem an on-chain earn order.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-post-redeem
+func (c *Finance) StakingRedeem(req requests.StakingRedeem) (response responses.StakingOrderResult, err error) {
+	p := "/api/v5/finance/staking-defi/redeem"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// StakingCancel
+// Cancel the purchase or redemption of an on-chain earn order still pending.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-post-cancel-purchases-redemptions
+func (c *Finance) StakingCancel(req requests.StakingCancel) (response responses.StakingOrderResult, err error) {
+	p := "/api/v5/finance/staking-defi/cancel"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetStakingActiveOrders
+// Get the active on-chain earn orders.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-get-active-orders
+func (c *Finance) GetStakingActiveOrders(req requests.GetStakingActiveOrders) (response responses.GetStakingOrders, err error) {
+	p := "/api/v5/finance/staking-defi/orders-active"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetStakingOrderHistory
+// Get the on-chain earn orders redeemed or canceled over the last 3 months, in reverse chronological order.
+//
+// https://www.okx.com/docs-v5/en/#financial-product-on-chain-earn-get-order-history
+func (c *Finance) GetStakingOrderHistory(req requests.GetStakingOrderHistory) (response responses.GetStakingOrders, err error) {
+	p := "/api/v5/finance/staking-defi/orders-history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
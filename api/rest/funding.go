@@ -67,8 +67,11 @@ func (c *Funding) GetBalance(req requests.GetBalance) (response responses.GetBal
 // https://www.okx.com/docs-v5/en/#rest-api-funding-funds-transfer
 func (c *Funding) FundsTransfer(req requests.FundsTransfer) (response responses.FundsTransfer, err error) {
 	p := "/api/v5/asset/transfer"
-	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
 	if err != nil {
 		return
 	}
@@ -146,6 +149,23 @@ func (c *Funding) Withdrawal(req requests.Withdrawal) (response responses.Withdr
 	return
 }
 
+// CancelWithdrawal
+// Cancel a normal withdrawal, withdrawals on the Lightning network or already sent on-chain cannot be canceled.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-funding-cancel-withdrawal
+func (c *Funding) CancelWithdrawal(req requests.CancelWithdrawal) (response responses.CancelWithdrawal, err error) {
+	p := "/api/v5/asset/cancel-withdrawal"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
 // GetWithdrawalHistory
 // Retrieve the withdrawal records according to the currency, withdrawal status, and time range in reverse chronological order. The 100 most recent records are returned by default.
 //
@@ -164,6 +184,7 @@ func (c *Funding) GetWithdrawalHistory(req requests.GetWithdrawalHistory) (respo
 }
 
 // PiggyBankPurchaseRedemption
+// Superseded by Finance.SavingsPurchaseRedemption
 //
 // https://www.okx.com/docs-v5/en/#rest-api-funding-piggybank-purchase-redemption
 func (c *Funding) PiggyBankPurchaseRedemption(req requests.PiggyBankPurchaseRedemption) (response responses.PiggyBankPurchaseRedemption, err error) {
@@ -180,6 +201,7 @@ func (c *Funding) PiggyBankPurchaseRedemption(req requests.PiggyBankPurchaseRede
 }
 
 // GetPiggyBankBalance
+// Superseded by Finance.GetSavingsBalance
 //
 // https://www.okx.com/docs-v5/en/#rest-api-funding-get-piggybank-balance
 func (c *Funding) GetPiggyBankBalance(req requests.GetPiggyBankBalance) (response responses.GetPiggyBankBalance, err error) {
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// Limiter throttles the requests of one or more clients. Public requests are accounted per IP, so under an empty
+	// account, and private requests per account.
+	Limiter interface {
+		// Wait blocks until a request to path may be sent on behalf of account
+		Wait(account, path string)
+	}
+
+	// Rate is a number of requests allowed per window
+	Rate struct {
+		N      int
+		Window time.Duration
+	}
+
+	limiterKey struct {
+		account string
+		path    string
+	}
+
+	// WindowLimiter is a Limiter allowing a rate of requests per endpoint within a sliding window
+	WindowLimiter struct {
+		mu    sync.Mutex
+		def   Rate
+		rates map[string]Rate
+		sent  map[limiterKey][]time.Time
+	}
+)
+
+// NewWindowLimiter returns a pointer to a fresh WindowLimiter. Endpoints without a rate of their own use def, a zero
+// def leaves them unlimited.
+func NewWindowLimiter(def Rate, rates map[string]Rate) *WindowLimiter {
+	return &WindowLimiter{def: def, rates: rates, sent: make(map[limiterKey][]time.Time)}
+}
+
+// Wait implements Limiter
+func (l *WindowLimiter) Wait(account, path string) {
+	r, ok := l.rates[path]
+	if !ok {
+		r = l.def
+	}
+	if r.N <= 0 || r.Window <= 0 {
+		return
+	}
+	k := limiterKey{account: account, path: path}
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		ts := l.sent[k]
+		i := 0
+		for i < len(ts) && now.Sub(ts[i]) >= r.Window {
+			i++
+		}
+		ts = ts[i:]
+		if len(ts) < r.N {
+			l.sent[k] = append(ts, now)
+			l.mu.Unlock()
+			return
+		}
+		l.sent[k] = ts
+		wait := r.Window - now.Sub(ts[0])
+		l.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// SetHTTPClient replaces the http client, clients sharing one share its connection pool
+func (c *ClientRest) SetHTTPClient(h *http.Client) {
+	c.client = h
+}
+
+// HTTPClient returns the http client
+func (c *ClientRest) HTTPClient() *http.Client {
+	return c.client
+}
+
+// SetLimiter throttles the requests through l. Private requests are accounted under account, or the API key when it
+// is empty; clients of the same OKX user should share it.
+func (c *ClientRest) SetLimiter(l Limiter, account string) {
+	c.limiter = l
+	c.account = account
+}
+
+// Limiter returns the limiter, nil when requests are not throttled
+func (c *ClientRest) Limiter() Limiter {
+	return c.limiter
+}
+
+func (c *ClientRest) wait(private bool, path string) {
+	if c.limiter == nil {
+		return
+	}
+	if !private {
+		c.limiter.Wait("", path)
+		return
+	}
+	a := c.account
+	if a == "" {
+		a = c.apiKey
+	}
+	c.limiter.Wait(a, path)
+}
@@ -72,6 +72,40 @@ func (c *PublicData) GetOpenInterest(req requests.GetOpenInterest) (response res
 	return
 }
 
+// GetFundingRate
+// Retrieve the current and the estimated next funding rate of a perpetual swap.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-public-data-get-funding-rate
+func (c *PublicData) GetFundingRate(req requests.GetFundingRate) (response responses.GetFundingRate, err error) {
+	p := "/api/v5/public/funding-rate"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, false, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetFundingRateHistory
+// Retrieve the funding rate history of a perpetual swap, up to 3 months back.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-public-data-get-funding-rate-history
+func (c *PublicData) GetFundingRateHistory(req requests.GetFundingRateHistory) (response responses.GetFundingRateHistory, err error) {
+	p := "/api/v5/public/funding-rate-history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, false, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
 // GetLimitPrice
 // Retrieve the highest buy limit and lowest sell limit of the instrument.
 //
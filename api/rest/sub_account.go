@@ -154,8 +154,11 @@ func (c *SubAccount) HistoryTransfer(req requests.HistoryTransfer) (response res
 // https://www.okx.com/docs-v5/en/#rest-api-subaccount-master-accounts-manage-the-transfers-between-sub-accounts
 func (c *SubAccount) ManageTransfers(req requests.ManageTransfers) (response responses.ManageTransfer, err error) {
 	p := "/api/v5/account/subaccount/transfer"
-	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
 	if err != nil {
 		return
 	}
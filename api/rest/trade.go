@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/yitech/okex"
@@ -24,17 +25,23 @@ func NewTrade(c *ClientRest) *Trade {
 // PlaceOrder
 // You can place an order only if you have sufficient funds.
 //
-// https://www.okx.com/docs-v5/en/#rest-api-trade-get-positions
+// https://www.okx.com/docs-v5/en/#rest-api-trade-place-order
+//
+// Place orders in batches. Maximum 20 orders can be placed at a time.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-trade-place-multiple-orders
 func (c *Trade) PlaceOrder(req []requestsTrade.PlaceOrderRequest) (response responsesTrade.PlaceOrderResponse, err error) {
-	p := "/api/v5/trade/order"
-	var tmp interface{}
-	tmp = req[0]
 	if len(req) > 1 {
-		tmp = req
-		p = "/api/trade/batch-orders"
+		return c.PlaceMultipleOrders(req)
 	}
-	m := okex.S2M(tmp)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err = validateOrders(req); err != nil {
+		return
+	}
+	j, err := json.Marshal(req[0])
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, "/api/v5/trade/order", true, j)
 	if err != nil {
 		return
 	}
@@ -46,13 +53,18 @@ func (c *Trade) PlaceOrder(req []requestsTrade.PlaceOrderRequest) (response resp
 }
 
 // PlaceMultipleOrders
-// Cancel an incomplete order.
+// Place orders in batches. Maximum 20 orders can be placed at a time.
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-place-multiple-orders
 func (c *Trade) PlaceMultipleOrders(req []requestsTrade.PlaceOrderRequest) (response responsesTrade.PlaceOrderResponse, err error) {
-	p := "/api/v5/trade/batch-order"
-	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err = validateOrders(req); err != nil {
+		return
+	}
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, "/api/v5/trade/batch-orders", true, j)
 	if err != nil {
 		return
 	}
@@ -112,16 +124,28 @@ func (c *Trade) CancelBatchOrders(req []requestsTrade.CancelOrderRequest) (respo
 // Amend incomplete orders in batches. Maximum 20 orders can be amended at a time. Request parameters should be passed in the form of an array.
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-amend-multiple-orders
-func (c *Trade) AmendOrder(req []requestsTrade.OrderListRequest) (response responsesTrade.AmendOrderResponse, err error) {
+func (c *Trade) AmendOrder(req []requestsTrade.AmendOrderRequest) (response responsesTrade.AmendOrderResponse, err error) {
+	if len(req) == 0 || len(req) > requestsTrade.MaxBatchSize {
+		err = fmt.Errorf("trade: between 1 and %d orders can be amended at a time", requestsTrade.MaxBatchSize)
+		return
+	}
+	for _, r := range req {
+		if err = r.Validate(); err != nil {
+			return
+		}
+	}
 	p := "/api/v5/trade/amend-order"
-	var tmp interface{}
-	tmp = req[0]
+	var j []byte
 	if len(req) > 1 {
-		tmp = req
-		p = "/api/trade/amend-batch-orders"
+		p = "/api/v5/trade/amend-batch-orders"
+		j, err = json.Marshal(req)
+	} else {
+		j, err = json.Marshal(req[0])
 	}
-	m := okex.S2M(tmp)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
 	if err != nil {
 		return
 	}
@@ -192,7 +216,7 @@ func (c *Trade) GetOrderList(req requestsTrade.OrderListRequest) (response respo
 func (c *Trade) GetOrderHistory(req requestsTrade.OrderListRequest, arch bool) (response responsesTrade.OrderListResponse, err error) {
 	p := "/api/v5/trade/orders-history"
 	if arch {
-		p = "/api/trade/orders-history-archive"
+		p = "/api/v5/trade/orders-history-archive"
 	}
 	m := okex.S2M(req)
 	res, err := c.client.Do(http.MethodGet, p, true, m)
@@ -216,7 +240,7 @@ func (c *Trade) GetOrderHistory(req requestsTrade.OrderListRequest, arch bool) (
 func (c *Trade) GetTransactionDetails(req requestsTrade.TransactionDetailsRequest, arch bool) (response responsesTrade.TransactionDetailResponse, err error) {
 	p := "/api/v5/trade/fills"
 	if arch {
-		p = "/api/trade/fills-history"
+		p = "/api/v5/trade/fills-history"
 	}
 	m := okex.S2M(req)
 	res, err := c.client.Do(http.MethodGet, p, true, m)
@@ -230,15 +254,61 @@ func (c *Trade) GetTransactionDetails(req requestsTrade.TransactionDetailsReques
 }
 
 // PlaceAlgoOrder
-// The algo order includes trigger order, oco order, conditional order,iceberg order and twap order.
-//
-// `iceberg` order and `twap` order just supported on demo trading
+// The algo order includes trigger order, oco order, conditional order, trailing stop order, iceberg order and twap order.
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-place-algo-order
 func (c *Trade) PlaceAlgoOrder(req requestsTrade.PlaceAlgoOrderRequest) (response responsesTrade.PlaceAlgoOrderResponse, err error) {
+	p := "/api/v5/trade/order-algo"
+	if err = req.Validate(); err != nil {
+		return
+	}
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+
+	return
+}
+
+// AmendAlgoOrder
+// Amend an incomplete algo order, only the size, take profit, stop loss and trigger parameters can be amended.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-algo-trading-post-amend-algo-order
+func (c *Trade) AmendAlgoOrder(req requestsTrade.AmendAlgoOrderRequest) (response responsesTrade.AmendAlgoOrderResponse, err error) {
+	p := "/api/v5/trade/amend-algos"
+	if err = req.Validate(); err != nil {
+		return
+	}
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+
+	return
+}
+
+// GetAlgoOrderDetail
+// Retrieve a single algo order in any state.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-algo-trading-get-algo-order-details
+func (c *Trade) GetAlgoOrderDetail(req requestsTrade.AlgoOrderDetailsRequest) (response responsesTrade.AlgoOrderListResponse, err error) {
 	p := "/api/v5/trade/order-algo"
 	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
 	if err != nil {
 		return
 	}
@@ -250,33 +320,47 @@ func (c *Trade) PlaceAlgoOrder(req requestsTrade.PlaceAlgoOrderRequest) (respons
 }
 
 // CancelAlgoOrder
-// Cancel unfilled algo orders(trigger order, oco order, conditional order). A maximum of 10 orders can be canceled at a time. Request parameters should be passed in the form of an array.
+// Cancel an unfilled algo order(trigger order, oco order, conditional order, trailing stop order).
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-cancel-algo-order
 func (c *Trade) CancelAlgoOrder(req requestsTrade.CancelAlgoOrderRequest) (response responsesTrade.CancelAlgoOrderResponse, err error) {
+	return c.CancelAlgoOrders([]requestsTrade.CancelAlgoOrderRequest{req})
+}
+
+// CancelAlgoOrders
+// Cancel unfilled algo orders(trigger order, oco order, conditional order) in batches. A maximum of 10 orders can be canceled at a time.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-trade-cancel-algo-order
+func (c *Trade) CancelAlgoOrders(req []requestsTrade.CancelAlgoOrderRequest) (response responsesTrade.CancelAlgoOrderResponse, err error) {
 	p := "/api/v5/trade/cancel-algos"
-	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+
+	// Marshal the slice directly to JSON for batch endpoints
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
 	if err != nil {
 		return
 	}
 	defer res.Body.Close()
 	d := json.NewDecoder(res.Body)
 	err = d.Decode(&response)
-
 	return
 }
 
 // CancelAdvanceAlgoOrder
-// Cancel unfilled algo orders(iceberg order and twap order). A maximum of 10 orders can be canceled at a time. Request parameters should be passed in the form of an array.
-//
-// # Only released on demo trading
+// Cancel an unfilled iceberg or twap order.
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-cancel-advance-algo-order
 func (c *Trade) CancelAdvanceAlgoOrder(req requestsTrade.CancelAlgoOrderRequest) (response responsesTrade.CancelAlgoOrderResponse, err error) {
 	p := "/api/v5/trade/cancel-advance-algos"
-	m := okex.S2M(req)
-	res, err := c.client.Do(http.MethodPost, p, true, m)
+	j, err := json.Marshal([]requestsTrade.CancelAlgoOrderRequest{req})
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
 	if err != nil {
 		return
 	}
@@ -292,13 +376,13 @@ func (c *Trade) CancelAdvanceAlgoOrder(req requestsTrade.CancelAlgoOrderRequest)
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-get-algo-order-list
 //
-// Retrieve a list of untriggered Algo orders under the current account in the last 3 months.
+// Retrieve the algo orders of the last 3 months, either a state or an algo id is required.
 //
 // https://www.okx.com/docs-v5/en/#rest-api-trade-get-algo-order-history
 func (c *Trade) GetAlgoOrderList(req requestsTrade.AlgoOrderListRequest, arch bool) (response responsesTrade.AlgoOrderListResponse, err error) {
 	p := "/api/v5/trade/orders-algo-pending"
 	if arch {
-		p = "/api/trade/orders-algo-history"
+		p = "/api/v5/trade/orders-algo-history"
 	}
 	m := okex.S2M(req)
 	res, err := c.client.Do(http.MethodGet, p, true, m)
@@ -311,3 +395,35 @@ func (c *Trade) GetAlgoOrderList(req requestsTrade.AlgoOrderListRequest, arch bo
 
 	return
 }
+
+// CancelAllAfter
+// Cancel all pending orders after the countdown timeout. Only applicable to the unified account.
+//
+// The countdown is refreshed on every call, and a TimeOut of 0 disables it. Otherwise it must be between 10 and 120 seconds.
+//
+// https://www.okx.com/docs-v5/en/#rest-api-trade-cancel-all-after
+func (c *Trade) CancelAllAfter(req requestsTrade.CancelAllAfterRequest) (response responsesTrade.CancelAllAfterResponse, err error) {
+	p := "/api/v5/trade/cancel-all-after"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodPost, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+
+	return
+}
+
+func validateOrders(req []requestsTrade.PlaceOrderRequest) error {
+	if len(req) == 0 || len(req) > requestsTrade.MaxBatchSize {
+		return fmt.Errorf("trade: between 1 and %d orders can be placed at a time", requestsTrade.MaxBatchSize)
+	}
+	for _, r := range req {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/yitech/okex"
+	requests "github.com/yitech/okex/requests/rest/tradingbot"
+	responses "github.com/yitech/okex/responses/tradingbot"
+)
+
+// TradingBot
+// Spot and contract grid trading bots
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading
+type TradingBot struct {
+	client *ClientRest
+}
+
+// NewTradingBot returns a pointer to a fresh TradingBot
+func NewTradingBot(c *ClientRest) *TradingBot {
+	return &TradingBot{c}
+}
+
+// PlaceGridOrder
+// Place a spot or contract grid bot. Spot bots set QuoteSz or BaseSz, contract bots set Sz, Direction and Lever.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-post-place-grid-algo-order
+func (c *TradingBot) PlaceGridOrder(req requests.PlaceGridOrder) (response responses.GridOrderResult, err error) {
+	p := "/api/v5/tradingBot/grid/order-algo"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// AmendGridOrder
+// Amend the take-profit, stop-loss and trigger parameters of a running grid bot.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-post-amend-grid-algo-order
+func (c *TradingBot) AmendGridOrder(req requests.AmendGridOrder) (response responses.GridOrderResult, err error) {
+	p := "/api/v5/tradingBot/grid/amend-order-algo"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// StopGridOrder
+// Stop up to 10 grid bots, selling or closing their holdings or keeping them.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-post-stop-grid-algo-order
+func (c *TradingBot) StopGridOrder(req []requests.StopGridOrder) (response responses.GridOrderResult, err error) {
+	p := "/api/v5/tradingBot/grid/stop-order-algo"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, true, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridOrders
+// Retrieve the grid bots not stopped yet.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-algo-order-list
+func (c *TradingBot) GetGridOrders(req requests.GetGridOrders) (response responses.GetGridOrders, err error) {
+	p := "/api/v5/tradingBot/grid/orders-algo-pending"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridOrderHistory
+// Retrieve the grid bots stopped in the last 7 days.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-algo-order-history
+func (c *TradingBot) GetGridOrderHistory(req requests.GetGridOrders) (response responses.GetGridOrders, err error) {
+	p := "/api/v5/tradingBot/grid/orders-algo-history"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridOrderDetails
+// Retrieve a grid bot with its profit and holdings.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-algo-order-details
+func (c *TradingBot) GetGridOrderDetails(req requests.GetGridOrderDetails) (response responses.GetGridOrders, err error) {
+	p := "/api/v5/tradingBot/grid/orders-algo-details"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridSubOrders
+// Retrieve the live or filled orders placed by a grid bot.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-algo-sub-orders
+func (c *TradingBot) GetGridSubOrders(req requests.GetGridSubOrders) (response responses.GetGridSubOrders, err error) {
+	p := "/api/v5/tradingBot/grid/sub-orders"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridPositions
+// Retrieve the position of a contract grid bot.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-algo-order-positions
+func (c *TradingBot) GetGridPositions(req requests.GetGridPositions) (response responses.GetGridPositions, err error) {
+	p := "/api/v5/tradingBot/grid/positions"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, true, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// GetGridAIParam
+// Retrieve the parameters suggested for a grid bot from the backtest of an instrument.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-get-grid-ai-parameter-public
+func (c *TradingBot) GetGridAIParam(req requests.GetGridAIParam) (response responses.GetGridAIParam, err error) {
+	p := "/api/v5/tradingBot/grid/ai-param"
+	m := okex.S2M(req)
+	res, err := c.client.Do(http.MethodGet, p, false, m)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
+
+// ComputeMinInvestment
+// Compute the minimum investment of a grid bot.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-post-compute-min-investment-public
+func (c *TradingBot) ComputeMinInvestment(req requests.ComputeMinInvestment) (response responses.ComputeMinInvestment, err error) {
+	p := "/api/v5/tradingBot/grid/min-investment"
+	j, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	res, err := c.client.DoRawBody(http.MethodPost, p, false, j)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	d := json.NewDecoder(res.Body)
+	err = d.Decode(&response)
+	return
+}
@@ -25,6 +25,7 @@ type ClientWs struct {
 	DoneChan            chan interface{}
 	StructuredEventChan chan interface{}
 	RawEventChan        chan *events.Basic
+	RawMessageChan      chan []byte
 	ErrChan             chan *events.Error
 	SubscribeChan       chan *events.Subscribe
 	UnsubscribeCh       chan *events.Unsubscribe
@@ -38,6 +39,7 @@ type ClientWs struct {
 	secretKey           []byte
 	passphrase          string
 	lastTransmit        map[bool]*time.Time
+	lastReceive         map[bool]*time.Time
 	mu                  map[bool]*sync.RWMutex
 	AuthRequested       *time.Time
 	Authorized          bool
@@ -69,6 +71,7 @@ func NewClient(ctx context.Context, apiKey, secretKey, passphrase string, url ma
 		conn:         make(map[bool]*websocket.Conn),
 		dialer:       websocket.DefaultDialer,
 		lastTransmit: make(map[bool]*time.Time),
+		lastReceive:  make(map[bool]*time.Time),
 		mu:           map[bool]*sync.RWMutex{true: {}, false: {}},
 	}
 	c.Private = NewPrivate(c)
@@ -169,8 +172,8 @@ func (c *ClientWs) Unsubscribe(p bool, ch []okex.ChannelName, args map[string]st
 	return c.Send(p, okex.UnsubscribeOperation, tmpArgs)
 }
 
-// Send message through either connections
-func (c *ClientWs) Send(p bool, op okex.Operation, args []map[string]string, extras ...map[string]string) error {
+// Send message through either connections, args is marshalled as is
+func (c *ClientWs) Send(p bool, op okex.Operation, args interface{}, extras ...map[string]string) error {
 	if op != okex.LoginOperation {
 		err := c.Connect(p)
 		if err == nil {
@@ -221,6 +224,22 @@ func (c *ClientWs) SetEventChannels(structuredEventCh chan interface{}, rawEvent
 	c.RawEventChan = rawEventCh
 }
 
+// SetRawMessageChannel receives every channel push as it came off the wire, in order, e.g. to record market data.
+// The channel must be drained, the receiver blocks on it.
+func (c *ClientWs) SetRawMessageChannel(ch chan []byte) {
+	c.RawMessageChan = ch
+}
+
+// Alive reports whether the connection is established and a message, including a pong, was received within the pong wait period
+func (c *ClientWs) Alive(p bool) bool {
+	c.mu[p].RLock()
+	defer c.mu[p].RUnlock()
+	if c.conn[p] == nil || c.lastReceive[p] == nil {
+		return false
+	}
+	return time.Since(*c.lastReceive[p]) < pongWait
+}
+
 // WaitForAuthorization waits for the auth response and try to log in if it was needed
 func (c *ClientWs) WaitForAuthorization() error {
 	if c.Authorized {
@@ -243,6 +262,7 @@ func (c *ClientWs) dial(p bool) error {
 	c.mu[p].Lock()
 	conn, res, err := c.dialer.Dial(string(c.url[p]), nil)
 	if err != nil {
+		c.mu[p].Unlock()
 		var statusCode int
 		if res != nil {
 			statusCode = res.StatusCode
@@ -341,12 +361,16 @@ func (c *ClientWs) receiver(p bool) error {
 			now := time.Now()
 			c.mu[p].Lock()
 			c.lastTransmit[p] = &now
+			c.lastReceive[p] = &now
 			c.mu[p].Unlock()
 			if mt == websocket.TextMessage && string(data) != "pong" {
 				e := &events.Basic{}
 				if err := json.Unmarshal(data, &e); err != nil {
 					return err
 				}
+				if c.RawMessageChan != nil && e.Event == "" && e.Arg != nil && len(e.Data) > 0 {
+					c.RawMessageChan <- data
+				}
 				go func() {
 					c.process(data, e)
 				}()
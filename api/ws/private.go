@@ -18,6 +18,13 @@ type Private struct {
 	pCh   chan *private.Position
 	bnpCh chan *private.BalanceAndPosition
 	oCh   chan *private.Order
+	aoCh  chan *private.AlgoOrder
+	aaoCh chan *private.AdvanceAlgoOrder
+	dCh   chan *private.DepositInfo
+	gsCh  chan *private.GridOrders
+	gcCh  chan *private.GridOrders
+	gpCh  chan *private.GridPositions
+	gsoCh chan *private.GridSubOrders
 }
 
 // NewPrivate returns a pointer to a fresh Private
@@ -117,6 +124,176 @@ func (c *Private) UOrder(req requests.Order, rCh ...bool) error {
 	return c.Unsubscribe(true, []okex.ChannelName{"orders"}, m)
 }
 
+// AlgoOrder
+// Retrieve algo orders (includes trigger order, oco order, conditional order and trailing stop order). Data will be pushed when first subscribed. Data will be pushed when triggered by events such as placing/canceling order.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL, such as
+// api.Client.Business.
+//
+// https://www.okx.com/docs-v5/en/#websocket-api-private-channel-algo-orders-channel
+func (c *Private) AlgoOrder(req requests.AlgoOrder, ch ...chan *private.AlgoOrder) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.aoCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"orders-algo"}, m)
+}
+
+// UAlgoOrder
+//
+// https://www.okx.com/docs-v5/en/#websocket-api-private-channel-algo-orders-channel
+func (c *Private) UAlgoOrder(req requests.AlgoOrder, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.aoCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"orders-algo"}, m)
+}
+
+// AdvanceAlgoOrder
+// Retrieve advance algo orders (iceberg and twap orders). Data will be pushed when first subscribed. Data will be pushed when triggered by events such as placing/canceling order.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL, such as
+// api.Client.Business.
+//
+// https://www.okx.com/docs-v5/en/#websocket-api-private-channel-advance-algo-orders-channel
+func (c *Private) AdvanceAlgoOrder(req requests.AdvanceAlgoOrder, ch ...chan *private.AdvanceAlgoOrder) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.aaoCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"algo-advance"}, m)
+}
+
+// UAdvanceAlgoOrder
+//
+// https://www.okx.com/docs-v5/en/#websocket-api-private-channel-advance-algo-orders-channel
+func (c *Private) UAdvanceAlgoOrder(req requests.AdvanceAlgoOrder, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.aaoCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"algo-advance"}, m)
+}
+
+// DepositInfo
+// Retrieve deposit information. Data will be pushed when triggered by events such as deposit being detected, confirmed or credited.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL.
+//
+// https://www.okx.com/docs-v5/en/#funding-account-websocket-deposit-info-channel
+func (c *Private) DepositInfo(req requests.DepositInfo, ch ...chan *private.DepositInfo) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.dCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"deposit-info"}, m)
+}
+
+// UDepositInfo
+//
+// https://www.okx.com/docs-v5/en/#funding-account-websocket-deposit-info-channel
+func (c *Private) UDepositInfo(req requests.DepositInfo, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.dCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"deposit-info"}, m)
+}
+
+// GridSpotOrders
+// Retrieve spot grid bots. Data will be pushed when triggered by events such as placing, amending or stopping a bot, and every 10 seconds while the bot runs.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-spot-grid-algo-orders-channel
+func (c *Private) GridSpotOrders(req requests.GridOrders, ch ...chan *private.GridOrders) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.gsCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"grid-orders-spot"}, m)
+}
+
+// UGridSpotOrders
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-spot-grid-algo-orders-channel
+func (c *Private) UGridSpotOrders(req requests.GridOrders, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.gsCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"grid-orders-spot"}, m)
+}
+
+// GridContractOrders
+// Retrieve contract grid bots. Data will be pushed when triggered by events such as placing, amending or stopping a bot, and every 10 seconds while the bot runs.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-contract-grid-algo-orders-channel
+func (c *Private) GridContractOrders(req requests.GridOrders, ch ...chan *private.GridOrders) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.gcCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"grid-orders-contract"}, m)
+}
+
+// UGridContractOrders
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-contract-grid-algo-orders-channel
+func (c *Private) UGridContractOrders(req requests.GridOrders, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.gcCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"grid-orders-contract"}, m)
+}
+
+// GridPositions
+// Retrieve the position of a contract grid bot. Data will be pushed when first subscribed, and when triggered by events such as placing or stopping the bot.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-grid-positions-channel
+func (c *Private) GridPositions(req requests.GridPositions, ch ...chan *private.GridPositions) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.gpCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"grid-positions"}, m)
+}
+
+// UGridPositions
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-grid-positions-channel
+func (c *Private) UGridPositions(req requests.GridPositions, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.gpCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"grid-positions"}, m)
+}
+
+// GridSubOrders
+// Retrieve the orders placed by a grid bot. Data will be pushed when first subscribed, and when triggered by events such as placing, filling or canceling an order.
+// The channel is served on the business endpoint, subscribe through a client connected to okex.BusinessWsURL.
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-grid-sub-orders-channel
+func (c *Private) GridSubOrders(req requests.GridSubOrders, ch ...chan *private.GridSubOrders) error {
+	m := okex.S2M(req)
+	if len(ch) > 0 {
+		c.gsoCh = ch[0]
+	}
+	return c.Subscribe(true, []okex.ChannelName{"grid-sub-orders"}, m)
+}
+
+// UGridSubOrders
+//
+// https://www.okx.com/docs-v5/en/#order-book-trading-grid-trading-websocket-grid-sub-orders-channel
+func (c *Private) UGridSubOrders(req requests.GridSubOrders, rCh ...bool) error {
+	m := okex.S2M(req)
+	if len(rCh) > 0 && rCh[0] {
+		c.gsoCh = nil
+	}
+	return c.Unsubscribe(true, []okex.ChannelName{"grid-sub-orders"}, m)
+}
+
 func (c *Private) Process(data []byte, e *events.Basic) bool {
 	if e.Event == "" && e.Arg != nil && e.Data != nil && len(e.Data) > 0 {
 		ch, ok := e.Arg.Get("channel")
@@ -176,6 +353,97 @@ func (c *Private) Process(data []byte, e *events.Basic) bool {
 				c.StructuredEventChan <- e
 			}()
 			return true
+		case "orders-algo":
+			e := private.AlgoOrder{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.aoCh != nil {
+					c.aoCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "algo-advance":
+			e := private.AdvanceAlgoOrder{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.aaoCh != nil {
+					c.aaoCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "deposit-info":
+			e := private.DepositInfo{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.dCh != nil {
+					c.dCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "grid-orders-spot":
+			e := private.GridOrders{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.gsCh != nil {
+					c.gsCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "grid-orders-contract":
+			e := private.GridOrders{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.gcCh != nil {
+					c.gcCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "grid-positions":
+			e := private.GridPositions{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.gpCh != nil {
+					c.gpCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
+		case "grid-sub-orders":
+			e := private.GridSubOrders{}
+			err := json.Unmarshal(data, &e)
+			if err != nil {
+				return false
+			}
+			go func() {
+				if c.gsoCh != nil {
+					c.gsoCh <- &e
+				}
+				c.StructuredEventChan <- e
+			}()
+			return true
 		}
 	}
 	return false
@@ -1,7 +1,10 @@
 package ws
 
 import (
+	"fmt"
+
 	"github.com/yitech/okex"
+	restRequests "github.com/yitech/okex/requests/rest/trade"
 	requests "github.com/yitech/okex/requests/ws/trade"
 )
 
@@ -26,15 +29,19 @@ func NewTrade(c *ClientWs) *Trade {
 //
 // https://www.okx.com/docs-v5/en/#websocket-api-trade-place-multiple-orders
 func (c *Trade) PlaceOrder(req ...requests.PlaceOrder) error {
-	tmpArgs := make([]map[string]string, len(req))
+	if len(req) == 0 || len(req) > restRequests.MaxBatchSize {
+		return fmt.Errorf("ws: between 1 and %d orders can be placed at a time", restRequests.MaxBatchSize)
+	}
 	op := okex.OrderOperation
 	if len(req) > 1 {
 		op = okex.BatchOrderOperation
 	}
-	for i, order := range req {
-		tmpArgs[i] = okex.S2M(order)
+	for _, order := range req {
+		if err := order.Validate(); err != nil {
+			return err
+		}
 	}
-	return c.Send(true, op, tmpArgs, map[string]string{"id": req[0].ID})
+	return c.Send(true, op, req, map[string]string{"id": req[0].ID})
 }
 
 // CancelOrder
@@ -46,15 +53,14 @@ func (c *Trade) PlaceOrder(req ...requests.PlaceOrder) error {
 //
 // https://www.okx.com/docs-v5/en/#websocket-api-trade-cancel-multiple-orders
 func (c *Trade) CancelOrder(req ...requests.CancelOrder) error {
-	tmpArgs := make([]map[string]string, len(req))
+	if len(req) == 0 || len(req) > restRequests.MaxBatchSize {
+		return fmt.Errorf("ws: between 1 and %d orders can be canceled at a time", restRequests.MaxBatchSize)
+	}
 	op := okex.CancelOrderOperation
 	if len(req) > 1 {
 		op = okex.BatchCancelOrderOperation
 	}
-	for i, order := range req {
-		tmpArgs[i] = okex.S2M(order)
-	}
-	return c.Send(true, op, tmpArgs, map[string]string{"id": req[0].ID})
+	return c.Send(true, op, req, map[string]string{"id": req[0].ID})
 }
 
 // AmendOrder
@@ -66,13 +72,17 @@ func (c *Trade) CancelOrder(req ...requests.CancelOrder) error {
 //
 // https://www.okx.com/docs-v5/en/#websocket-api-trade-amend-multiple-orders
 func (c *Trade) AmendOrder(req ...requests.AmendOrder) error {
-	tmpArgs := make([]map[string]string, len(req))
+	if len(req) == 0 || len(req) > restRequests.MaxBatchSize {
+		return fmt.Errorf("ws: between 1 and %d orders can be amended at a time", restRequests.MaxBatchSize)
+	}
 	op := okex.AmendOrderOperation
 	if len(req) > 1 {
 		op = okex.BatchAmendOrderOperation
 	}
-	for i, order := range req {
-		tmpArgs[i] = okex.S2M(order)
+	for _, order := range req {
+		if err := order.Validate(); err != nil {
+			return err
+		}
 	}
-	return c.Send(true, op, tmpArgs, map[string]string{"id": req[0].ID})
+	return c.Send(true, op, req, map[string]string{"id": req[0].ID})
 }
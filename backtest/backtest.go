@@ -0,0 +1,495 @@
+// Package backtest replays recorded market data through the paper exchange, deterministically and in timestamp order.
+//
+// Strategies register the same handlers they would use on live pushes and trade through Backtest.Exchange, so the
+// same code runs against the exchange. PnL assumes every position settles in the same currency as InitialEquity.
+package backtest
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/fee"
+	"github.com/yitech/okex/margin"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/models/trade"
+	"github.com/yitech/okex/paper"
+	"github.com/yitech/okex/position"
+	requests "github.com/yitech/okex/requests/rest/trade"
+)
+
+type (
+	// Config of a backtest
+	Config struct {
+		InitialEquity float64
+		// Latency before orders, cancellations and amendments reach the simulated book
+		Latency time.Duration
+		// QueuePosition makes resting orders wait behind the size shown at their price
+		QueuePosition bool
+		// FeeModel charges the fills, e.g. one loaded from the account and shared with live reports. Fees are set on it.
+		FeeModel    *fee.Model
+		Fees        map[okex.InstrumentType]paper.Fee
+		Instruments []*publicdata.Instrument
+		// Tiers are the position tiers used for the maintenance margin, positions without tiers are never liquidated
+		Tiers []*publicdata.PositionTier
+		// SampleInterval of the equity curve, a minute by default
+		SampleInterval time.Duration
+	}
+
+	// EquityPoint is a sample of the equity curve
+	EquityPoint struct {
+		Time   time.Time
+		Equity float64
+	}
+
+	// Liquidation is a forced close of the positions because the equity fell below the maintenance margin
+	Liquidation struct {
+		Time        time.Time
+		InstID      string
+		Pos         float64
+		MarkPx      float64
+		Equity      float64
+		Maintenance float64
+	}
+
+	// Stats summarizes a backtest
+	Stats struct {
+		Start         time.Time
+		End           time.Time
+		InitialEquity float64
+		FinalEquity   float64
+		Return        float64
+		MaxDrawdown   float64
+		// Sharpe is annualized from the returns between equity samples
+		Sharpe       float64
+		RealizedPnl  float64
+		Fees         float64
+		Funding      float64
+		Volume       float64
+		Fills        int
+		Liquidations int
+	}
+
+	// Result of a backtest
+	Result struct {
+		Equity       []*EquityPoint
+		Trades       []*trade.TransactionDetail
+		Liquidations []*Liquidation
+		Stats        Stats
+	}
+
+	// Backtest drives a strategy with replayed events
+	Backtest struct {
+		cfg         Config
+		ex          *paper.Exchange
+		tracker     *position.Tracker
+		instruments map[string]*publicdata.Instrument
+		margin      *margin.Calculator
+		now         time.Time
+		marks       map[string]float64
+		markFeed    map[string]bool
+		rates       map[string]*publicdata.FundingRate
+		settled     map[string]time.Time
+		liquidating map[position.Key]bool
+		seen        map[string]bool
+		billSeq     int64
+		nextSample  time.Time
+		result      *Result
+		onTickers   []func(e *public.Tickers)
+		onTrades    []func(e *public.Trades)
+		onOrderBook []func(e *public.OrderBook)
+		onCandles   []func(e *public.Candlesticks)
+		onMarkPrice []func(e *public.MarkPrice)
+		onFunding   []func(e *public.FundingRate)
+		onTick      []func(now time.Time)
+	}
+)
+
+// New returns a pointer to a fresh Backtest
+func New(cfg Config) *Backtest {
+	if cfg.SampleInterval <= 0 {
+		cfg.SampleInterval = time.Minute
+	}
+	b := &Backtest{
+		cfg:         cfg,
+		ex:          paper.NewExchange(),
+		tracker:     position.NewTracker(nil),
+		instruments: make(map[string]*publicdata.Instrument),
+		margin:      margin.NewCalculator(),
+		marks:       make(map[string]float64),
+		markFeed:    make(map[string]bool),
+		rates:       make(map[string]*publicdata.FundingRate),
+		settled:     make(map[string]time.Time),
+		liquidating: make(map[position.Key]bool),
+		seen:        make(map[string]bool),
+		result:      &Result{},
+	}
+	b.ex.SetLatency(cfg.Latency)
+	b.ex.SetQueuePosition(cfg.QueuePosition)
+	if cfg.FeeModel != nil {
+		b.ex.SetFeeModel(cfg.FeeModel)
+	}
+	for t, f := range cfg.Fees {
+		b.ex.SetFee(t, f)
+	}
+	for _, i := range cfg.Instruments {
+		b.instruments[i.InstID] = i
+		b.ex.SetInstrument(i)
+		b.tracker.SetInstrument(i)
+		b.margin.SetInstrument(i)
+	}
+	b.margin.SetTiers(cfg.Tiers)
+	b.ex.OnOrder(b.handleOrder)
+	return b
+}
+
+// Exchange returns the simulated exchange the strategy trades on
+func (b *Backtest) Exchange() *paper.Exchange {
+	return b.ex
+}
+
+// Tracker returns the positions and PnL of the strategy
+func (b *Backtest) Tracker() *position.Tracker {
+	return b.tracker
+}
+
+// Now returns the replay time
+func (b *Backtest) Now() time.Time {
+	return b.now
+}
+
+// OnTickers registers a handler for `tickers` pushes
+func (b *Backtest) OnTickers(h func(e *public.Tickers)) {
+	b.onTickers = append(b.onTickers, h)
+}
+
+// OnTrades registers a handler for `trades` pushes
+func (b *Backtest) OnTrades(h func(e *public.Trades)) {
+	b.onTrades = append(b.onTrades, h)
+}
+
+// OnOrderBook registers a handler for `books` pushes
+func (b *Backtest) OnOrderBook(h func(e *public.OrderBook)) {
+	b.onOrderBook = append(b.onOrderBook, h)
+}
+
+// OnCandlesticks registers a handler for `candle*` pushes
+func (b *Backtest) OnCandlesticks(h func(e *public.Candlesticks)) {
+	b.onCandles = append(b.onCandles, h)
+}
+
+// OnMarkPrice registers a handler for `mark-price` pushes
+func (b *Backtest) OnMarkPrice(h func(e *public.MarkPrice)) {
+	b.onMarkPrice = append(b.onMarkPrice, h)
+}
+
+// OnFundingRate registers a handler for `funding-rate` pushes
+func (b *Backtest) OnFundingRate(h func(e *public.FundingRate)) {
+	b.onFunding = append(b.onFunding, h)
+}
+
+// OnTick registers a handler called after every event, e.g. for timers of the strategy
+func (b *Backtest) OnTick(h func(now time.Time)) {
+	b.onTick = append(b.onTick, h)
+}
+
+// Run replays the source until it is exhausted or the context is done
+func (b *Backtest) Run(ctx context.Context, src Source) (*Result, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return b.finish(), err
+		}
+		ev, err := src.Next()
+		if err == io.EOF {
+			return b.finish(), nil
+		}
+		if err != nil {
+			return b.finish(), err
+		}
+		b.step(ev)
+	}
+}
+
+func (b *Backtest) step(ev *Event) {
+	if b.now.IsZero() {
+		b.now = ev.TS
+		b.ex.SetClock(b.Now)
+		b.result.Stats.Start = ev.TS
+		b.nextSample = ev.TS
+	}
+	if ev.TS.After(b.now) {
+		b.now = ev.TS
+	}
+	b.settleFunding()
+
+	switch e := ev.Value.(type) {
+	case *public.OrderBook:
+		b.ex.HandleOrderBook(e)
+		for _, h := range b.onOrderBook {
+			h(e)
+		}
+	case *public.Trades:
+		b.ex.HandleTrades(e)
+		for _, t := range e.Trades {
+			if !b.markFeed[t.InstID] {
+				b.mark(t.InstID, "", float64(t.Px))
+			}
+		}
+		for _, h := range b.onTrades {
+			h(e)
+		}
+	case *public.Tickers:
+		b.ex.Advance()
+		for _, t := range e.Tickers {
+			if !b.markFeed[t.InstID] && t.Last > 0 {
+				b.mark(t.InstID, t.InstType, float64(t.Last))
+			}
+		}
+		for _, h := range b.onTickers {
+			h(e)
+		}
+	case *public.MarkPrice:
+		b.ex.Advance()
+		for _, p := range e.Prices {
+			b.markFeed[p.InstID] = true
+			b.marks[p.InstID] = float64(p.MarkPx)
+		}
+		b.tracker.HandleMarkPrice(e)
+		for _, h := range b.onMarkPrice {
+			h(e)
+		}
+	case *public.FundingRate:
+		b.ex.Advance()
+		for _, r := range e.Rates {
+			b.rates[r.InstID] = r
+		}
+		for _, h := range b.onFunding {
+			h(e)
+		}
+	case *public.Candlesticks:
+		b.ex.Advance()
+		for _, h := range b.onCandles {
+			h(e)
+		}
+	}
+	for _, h := range b.onTick {
+		h(b.now)
+	}
+	b.checkLiquidation()
+	if !b.now.Before(b.nextSample) {
+		b.sample()
+		b.nextSample = b.now.Truncate(b.cfg.SampleInterval).Add(b.cfg.SampleInterval)
+	}
+}
+
+// mark revalues the positions of an instrument when no mark price feed is replayed
+func (b *Backtest) mark(instID string, t okex.InstrumentType, px float64) {
+	b.marks[instID] = px
+	b.tracker.HandleMarkPrice(&public.MarkPrice{Prices: []*publicdata.MarkPrice{{
+		InstID:   instID,
+		InstType: t,
+		MarkPx:   okex.JSONFloat64(px),
+		TS:       okex.JSONTime(b.now),
+	}}})
+}
+
+// settleFunding books the funding fee of every position whose funding time has passed
+func (b *Backtest) settleFunding() {
+	ids := make([]string, 0, len(b.rates))
+	for instID := range b.rates {
+		ids = append(ids, instID)
+	}
+	sort.Strings(ids)
+	for _, instID := range ids {
+		r := b.rates[instID]
+		ft := time.Time(r.FundingTime)
+		if ft.IsZero() || b.now.Before(ft) || !b.settled[instID].Before(ft) {
+			continue
+		}
+		b.settled[instID] = ft
+		px := b.marks[instID]
+		if px == 0 {
+			continue
+		}
+		ctVal, inverse := b.contract(instID)
+		for _, p := range b.tracker.Positions() {
+			if p.InstID != instID || p.Pos == 0 {
+				continue
+			}
+			// Longs pay shorts when the rate is positive
+			chg := -float64(r.FundingRate) * p.Pos * ctVal * px
+			if inverse {
+				chg = -float64(r.FundingRate) * p.Pos * ctVal / px
+			}
+			b.billSeq++
+			b.tracker.HandleBill(&account.Bill{
+				InstID:   instID,
+				BillID:   "funding-" + strconv.FormatInt(b.billSeq, 10),
+				BalChg:   okex.JSONFloat64(chg),
+				InstType: p.InstType,
+				MgnMode:  p.MgnMode,
+				Type:     okex.BillFundingFeeType,
+				TS:       okex.JSONTime(ft),
+			})
+			b.result.Stats.Funding += chg
+		}
+	}
+}
+
+// checkLiquidation closes every position at market once the equity no longer covers the maintenance margin
+func (b *Backtest) checkLiquidation() {
+	var mm float64
+	var ps []*position.Position
+	for _, p := range b.tracker.Positions() {
+		if p.Pos == 0 {
+			delete(b.liquidating, p.Key)
+			continue
+		}
+		if p.MgnMode == okex.MarginMode(okex.TradeCashMode) {
+			continue
+		}
+		mm += b.maintenance(p)
+		ps = append(ps, p)
+	}
+	eq := b.equity()
+	if mm == 0 || eq > mm {
+		return
+	}
+	for _, p := range ps {
+		if b.liquidating[p.Key] {
+			continue
+		}
+		b.liquidating[p.Key] = true
+		b.result.Liquidations = append(b.result.Liquidations, &Liquidation{
+			Time:        b.now,
+			InstID:      p.InstID,
+			Pos:         p.Pos,
+			MarkPx:      b.marks[p.InstID],
+			Equity:      eq,
+			Maintenance: mm,
+		})
+		_, _ = b.ex.ClosePosition(requests.ClosePositionRequest{InstID: p.InstID, MgnMode: okex.TradeMode(p.MgnMode)})
+	}
+}
+
+// maintenance returns the maintenance margin of a position from the tier its size falls in
+func (b *Backtest) maintenance(p *position.Position) float64 {
+	r, err := b.margin.Calculate(margin.Position{
+		InstID:  p.InstID,
+		MgnMode: p.MgnMode,
+		Pos:     p.Pos,
+		AvgPx:   p.AvgPx,
+		MarkPx:  b.marks[p.InstID],
+	})
+	if err != nil {
+		return 0
+	}
+	return r.Mmr
+}
+
+func (b *Backtest) contract(instID string) (float64, bool) {
+	i, ok := b.instruments[instID]
+	if !ok || i.CtVal == 0 {
+		return 1, ok && i.CtType == okex.ContractInverseType
+	}
+	return float64(i.CtVal), i.CtType == okex.ContractInverseType
+}
+
+func (b *Backtest) equity() float64 {
+	eq := b.cfg.InitialEquity
+	for _, p := range b.tracker.Positions() {
+		eq += p.TotalPnl()
+	}
+	return eq
+}
+
+func (b *Backtest) sample() {
+	b.result.Equity = append(b.result.Equity, &EquityPoint{Time: b.now, Equity: b.equity()})
+}
+
+// handleOrder books the fills found in order pushes
+func (b *Backtest) handleOrder(e *private.Order) {
+	for _, o := range e.Orders {
+		if o.TradeID == "" || o.FillSz == 0 || b.seen[o.TradeID] {
+			continue
+		}
+		b.seen[o.TradeID] = true
+		f := &trade.TransactionDetail{
+			InstID:   o.InstID,
+			OrdID:    o.OrdID,
+			TradeID:  o.TradeID,
+			ClOrdID:  o.ClOrdID,
+			FillPx:   o.FillPx,
+			FillSz:   o.FillSz,
+			FeeCcy:   o.FillFeeCcy,
+			Fee:      o.FillFee,
+			InstType: o.InstType,
+			Side:     o.Side,
+			PosSide:  o.PosSide,
+			ExecType: o.ExecType,
+			TS:       o.UTime,
+		}
+		b.tracker.ApplyFill(okex.MarginMode(o.TdMode), f)
+		b.result.Trades = append(b.result.Trades, f)
+		ctVal, inverse := b.contract(o.InstID)
+		if inverse {
+			b.result.Stats.Volume += float64(o.FillSz) * ctVal
+		} else {
+			b.result.Stats.Volume += float64(o.FillSz) * ctVal * float64(o.FillPx)
+		}
+	}
+}
+
+func (b *Backtest) finish() *Result {
+	r := b.result
+	if b.now.IsZero() {
+		return r
+	}
+	if n := len(r.Equity); n == 0 || r.Equity[n-1].Time.Before(b.now) {
+		b.sample()
+	}
+	s := &r.Stats
+	s.End = b.now
+	s.InitialEquity = b.cfg.InitialEquity
+	s.FinalEquity = b.equity()
+	if s.InitialEquity != 0 {
+		s.Return = s.FinalEquity/s.InitialEquity - 1
+	}
+	s.RealizedPnl, s.Fees = 0, 0
+	for _, p := range b.tracker.Positions() {
+		s.RealizedPnl += p.RealizedPnl
+		s.Fees += p.Fee
+	}
+	s.Fills = len(r.Trades)
+	s.Liquidations = len(r.Liquidations)
+
+	var peak, sum, sumSq float64
+	var n int
+	for i, p := range r.Equity {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			s.MaxDrawdown = math.Max(s.MaxDrawdown, (peak-p.Equity)/peak)
+		}
+		if i > 0 && r.Equity[i-1].Equity != 0 {
+			ret := p.Equity/r.Equity[i-1].Equity - 1
+			sum += ret
+			sumSq += ret * ret
+			n++
+		}
+	}
+	if n > 1 {
+		mean := sum / float64(n)
+		sd := math.Sqrt((sumSq - float64(n)*mean*mean) / float64(n-1))
+		if sd > 0 {
+			s.Sharpe = mean / sd * math.Sqrt(float64(365*24*time.Hour)/float64(b.cfg.SampleInterval))
+		}
+	}
+	return r
+}
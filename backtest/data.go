@@ -0,0 +1,261 @@
+package backtest
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/events"
+	"github.com/yitech/okex/events/public"
+)
+
+type (
+	// Record is a single recorded push, stamped with the time it was received
+	Record struct {
+		TS  okex.JSONTime   `json:"ts"`
+		Msg json.RawMessage `json:"msg"`
+	}
+
+	// Event is a replayed push. Value is one of *public.Tickers, *public.Trades, *public.OrderBook,
+	// *public.Candlesticks, *public.MarkPrice or *public.FundingRate.
+	Event struct {
+		TS    time.Time
+		Value interface{}
+	}
+
+	// Source yields events in time order and io.EOF once exhausted
+	Source interface {
+		Next() (*Event, error)
+	}
+
+	// Recorder writes channel pushes as JSON lines of Record
+	Recorder struct {
+		w   *bufio.Writer
+		now func() time.Time
+	}
+
+	// Reader replays the JSON lines written by a Recorder. Lines holding the bare push are accepted too, they are
+	// replayed at the latest timestamp found in their data.
+	Reader struct {
+		s    *bufio.Scanner
+		last time.Time
+	}
+
+	merged struct {
+		heads []*Event
+		srcs  []Source
+		order []int
+	}
+)
+
+// NewRecorder returns a pointer to a fresh Recorder
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w), now: time.Now}
+}
+
+// Record writes a push received now
+func (r *Recorder) Record(msg []byte) error {
+	_, err := fmt.Fprintf(r.w, "{\"ts\":\"%d\",\"msg\":%s}\n", r.now().UnixMilli(), strings.TrimSpace(string(msg)))
+	return err
+}
+
+// Run records the pushes of a ws.ClientWs raw message channel until the context is done
+func (r *Recorder) Run(ctx context.Context, ch <-chan []byte) error {
+	defer r.w.Flush()
+	for {
+		select {
+		case msg := <-ch:
+			if err := r.Record(msg); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Flush writes the buffered records
+func (r *Recorder) Flush() error {
+	return r.w.Flush()
+}
+
+// NewReader returns a pointer to a fresh Reader
+func NewReader(r io.Reader) *Reader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &Reader{s: s}
+}
+
+// Next returns the next supported push, pushes of other channels are skipped
+func (r *Reader) Next() (*Event, error) {
+	for r.s.Scan() {
+		line := r.s.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, err
+		}
+		if rec.Msg == nil {
+			rec.Msg = line
+		}
+		v, ts, err := decode(rec.Msg)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		if !time.Time(rec.TS).IsZero() {
+			ts = time.Time(rec.TS)
+		}
+		if ts.IsZero() {
+			// Funding rate pushes carry no timestamp of their own
+			ts = r.last
+		}
+		if ts.After(r.last) {
+			r.last = ts
+		}
+		return &Event{TS: ts, Value: v}, nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Merge interleaves sources in time order. Events with the same timestamp keep the order of the sources.
+func Merge(srcs ...Source) Source {
+	return &merged{srcs: srcs, heads: make([]*Event, len(srcs))}
+}
+
+func (m *merged) Next() (*Event, error) {
+	if m.order == nil {
+		m.order = []int{}
+		for i := range m.srcs {
+			if err := m.pull(i); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(m.order) == 0 {
+		return nil, io.EOF
+	}
+	i := heap.Pop(m).(int)
+	ev := m.heads[i]
+	if err := m.pull(i); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+func (m *merged) pull(i int) error {
+	ev, err := m.srcs[i].Next()
+	if err == io.EOF {
+		m.heads[i] = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.heads[i] = ev
+	heap.Push(m, i)
+	return nil
+}
+
+func (m *merged) Len() int { return len(m.order) }
+func (m *merged) Less(i, j int) bool {
+	a, b := m.heads[m.order[i]], m.heads[m.order[j]]
+	if a.TS.Equal(b.TS) {
+		return m.order[i] < m.order[j]
+	}
+	return a.TS.Before(b.TS)
+}
+func (m *merged) Swap(i, j int)      { m.order[i], m.order[j] = m.order[j], m.order[i] }
+func (m *merged) Push(x interface{}) { m.order = append(m.order, x.(int)) }
+func (m *merged) Pop() interface{} {
+	n := len(m.order) - 1
+	x := m.order[n]
+	m.order = m.order[:n]
+	return x
+}
+
+// decode turns a push into its event and the latest timestamp of its data, unsupported channels give a nil event
+func decode(msg []byte) (interface{}, time.Time, error) {
+	var b events.Basic
+	if err := json.Unmarshal(msg, &b); err != nil {
+		return nil, time.Time{}, err
+	}
+	if b.Arg == nil {
+		return nil, time.Time{}, nil
+	}
+	v, _ := b.Arg.Get("channel")
+	ch, _ := v.(string)
+	var ts time.Time
+	latest := func(t okex.JSONTime) {
+		if time.Time(t).After(ts) {
+			ts = time.Time(t)
+		}
+	}
+	switch {
+	case ch == "tickers":
+		e := &public.Tickers{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		for _, t := range e.Tickers {
+			latest(t.TS)
+		}
+		return e, ts, nil
+	case ch == "trades" || ch == "trades-all":
+		e := &public.Trades{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		for _, t := range e.Trades {
+			latest(t.TS)
+		}
+		return e, ts, nil
+	case strings.HasPrefix(ch, "books") || ch == "bbo-tbt":
+		e := &public.OrderBook{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		for _, b := range e.Books {
+			latest(b.TS)
+		}
+		return e, ts, nil
+	case strings.HasPrefix(ch, "candle"):
+		e := &public.Candlesticks{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		for _, c := range e.Candles {
+			latest(c.TS)
+		}
+		return e, ts, nil
+	case ch == "mark-price":
+		e := &public.MarkPrice{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		for _, p := range e.Prices {
+			latest(p.TS)
+		}
+		return e, ts, nil
+	case ch == "funding-rate":
+		e := &public.FundingRate{}
+		if err := json.Unmarshal(msg, e); err != nil {
+			return nil, ts, err
+		}
+		return e, ts, nil
+	}
+	return nil, ts, nil
+}
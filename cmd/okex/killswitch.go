@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yitech/okex/killswitch"
+)
+
+func init() {
+	commands["killswitch"] = command{
+		usage: "cancel every open order and algo order, optionally close every position",
+		run:   runKillSwitch,
+	}
+}
+
+func runKillSwitch(args []string) error {
+	fs := flag.NewFlagSet("killswitch", flag.ExitOnError)
+	closePos := fs.Bool("close", false, "close every position after cancelling the orders")
+	dryRun := fs.Bool("dry-run", false, "only list what would be cancelled and closed")
+	demo := fs.Bool("demo", false, "use the demo trading environment")
+	accounts := fs.String("accounts", "", "JSON file listing the sub-account credentials to include")
+	skipMaster := fs.Bool("skip-master", false, "do not include the master account")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var creds []credentials
+	if !*skipMaster {
+		creds = append(creds, masterCredentials())
+	}
+	if *accounts != "" {
+		b, err := os.ReadFile(*accounts)
+		if err != nil {
+			return err
+		}
+		var subs []credentials
+		if err := json.Unmarshal(b, &subs); err != nil {
+			return fmt.Errorf("%s: %w", *accounts, err)
+		}
+		creds = append(creds, subs...)
+	}
+
+	targets := make([]killswitch.Target, len(creds))
+	for i, c := range creds {
+		r := newRestClient(c, *demo)
+		targets[i] = killswitch.Target{Name: c.Name, Trade: r.Trade, Account: r.Account}
+	}
+	r := killswitch.Run(killswitch.Options{ClosePositions: *closePos, DryRun: *dryRun}, targets...)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	if !r.OK() && !r.DryRun {
+		return fmt.Errorf("killswitch: some orders or positions were left behind")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api/rest"
+	"github.com/yitech/okex/ledger"
+	public "github.com/yitech/okex/requests/rest/public"
+)
+
+func init() {
+	commands["ledger"] = command{
+		usage: "export the account and funding bills as a double-entry ledger, or the realized PnL",
+		run:   runLedger,
+	}
+}
+
+func runLedger(args []string) error {
+	fs := flag.NewFlagSet("ledger", flag.ExitOnError)
+	since := fs.Duration("since", 7*24*time.Hour, "how far back to export, at most 3 months")
+	format := fs.String("format", "csv", "output format, csv or json")
+	pnl := fs.String("pnl", "", "print the realized PnL under a cost basis method (fifo, lifo or average) instead")
+	out := fs.String("out", "", "output file, standard output when empty")
+	demo := fs.Bool("demo", false, "use the demo trading environment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m := ledger.Method(*pnl)
+	switch {
+	case *pnl == "":
+		if *format != "csv" && *format != "json" {
+			return fmt.Errorf("ledger: unknown format %q", *format)
+		}
+	case m == ledger.MethodFIFO, m == ledger.MethodLIFO, m == ledger.MethodAverage:
+	default:
+		return fmt.Errorf("ledger: unknown cost basis method %q", *pnl)
+	}
+
+	r := newRestClient(masterCredentials(), *demo)
+	end := time.Now()
+	entries, err := ledger.NewLedger(r.Account, r.Funding).Load(end.Add(-*since), end)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *pnl != "" {
+		b := ledger.NewBook(m)
+		if err := loadInstruments(r, b, entries); err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b.Realize(entries))
+	}
+	if *format == "json" {
+		return ledger.WriteJSON(w, entries)
+	}
+	return ledger.WriteCSV(w, entries)
+}
+
+// loadInstruments registers the instruments of every type the entries trade, options per underlying
+func loadInstruments(r *rest.ClientRest, b *ledger.Book, entries []*ledger.Entry) error {
+	reqs := make(map[public.GetInstruments]bool)
+	for _, e := range entries {
+		if e.InstType == "" || e.InstID == "" {
+			continue
+		}
+		req := public.GetInstruments{InstType: okex.InstrumentType(e.InstType)}
+		if req.InstType == okex.OptionsInstrument {
+			if parts := strings.Split(e.InstID, "-"); len(parts) >= 2 {
+				req.Uly = parts[0] + "-" + parts[1]
+			}
+		}
+		reqs[req] = true
+	}
+	for req := range reqs {
+		res, err := r.PublicData.GetInstruments(req)
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("ledger: get %s instruments failed: %d %s", req.InstType, res.Code, res.Msg)
+		}
+		for _, i := range res.Instruments {
+			b.SetInstrument(i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Command okex groups operational tools built on top of the okex package.
+//
+//	okex <command> [flags]
+//
+// The master account credentials are read from the OKX_API_KEY, OKX_SECRET_KEY and OKX_PASSPHRASE environment variables.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api/rest"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: okex <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", n, commands[n].usage)
+	}
+}
+
+// credentials of an account, as found in the accounts file
+type credentials struct {
+	Name       string `json:"name"`
+	APIKey     string `json:"apiKey"`
+	SecretKey  string `json:"secretKey"`
+	Passphrase string `json:"passphrase"`
+}
+
+func masterCredentials() credentials {
+	return credentials{
+		Name:       "master",
+		APIKey:     os.Getenv("OKX_API_KEY"),
+		SecretKey:  os.Getenv("OKX_SECRET_KEY"),
+		Passphrase: os.Getenv("OKX_PASSPHRASE"),
+	}
+}
+
+func newRestClient(c credentials, demo bool) *rest.ClientRest {
+	if demo {
+		return rest.NewClient(c.APIKey, c.SecretKey, c.Passphrase, okex.DemoRestURL, okex.DemoServer)
+	}
+	return rest.NewClient(c.APIKey, c.SecretKey, c.Passphrase, okex.RestURL, okex.NormalServer)
+}
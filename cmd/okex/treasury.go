@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yitech/okex/treasury"
+)
+
+func init() {
+	commands["treasury"] = command{
+		usage: "plan the sweeps, top-ups and allocations of a treasury config, and execute them with -execute",
+		run:   runTreasury,
+	}
+}
+
+func runTreasury(args []string) error {
+	fs := flag.NewFlagSet("treasury", flag.ExitOnError)
+	config := fs.String("config", "treasury.json", "JSON file with the sweeps, top-ups, allocations and caps")
+	execute := fs.Bool("execute", false, "execute the plan instead of only printing it")
+	audit := fs.String("audit", "treasury-audit.log", "file the executed transfers are appended to")
+	demo := fs.Bool("demo", false, "use the demo trading environment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(*config)
+	if err != nil {
+		return err
+	}
+	var cfg treasury.Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("treasury: read %s: %w", *config, err)
+	}
+
+	r := newRestClient(masterCredentials(), *demo)
+	t := treasury.NewTreasury(cfg, r.SubAccount, r.Funding)
+	if err := t.LoadUsage(); err != nil {
+		return err
+	}
+	p, err := t.Plan()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return err
+	}
+	if !*execute || len(p.Transfers) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(*audit, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	t.OnRecord(treasury.NewAuditLog(f))
+	t.OnRecord(func(rec *treasury.Record) {
+		if rec.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s %g %s %q -> %q failed: %v\n", rec.Transfer.Rule, rec.Transfer.Amt, rec.Transfer.Ccy, rec.Transfer.From, rec.Transfer.To, rec.Err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%s %g %s %q -> %q done, transfer %s\n", rec.Transfer.Rule, rec.Transfer.Amt, rec.Transfer.Ccy, rec.Transfer.From, rec.Transfer.To, rec.TransID)
+	})
+	_, err = t.Execute(p)
+	return err
+}
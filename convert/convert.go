@@ -0,0 +1,272 @@
+// Package convert swaps small balances through the quote-and-trade conversion of the funding account, without
+// touching the order books.
+//
+// A conversion requests a quote, checks that it is still valid long enough and that its price is within a tolerance
+// of a reference price, then executes it. A quote is filled in full at its price or rejected, never partially.
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	models "github.com/yitech/okex/models/convert"
+	requests "github.com/yitech/okex/requests/rest/convert"
+	marketRequests "github.com/yitech/okex/requests/rest/market"
+)
+
+var (
+	ErrAmount      = errors.New("convert: amount out of the pair bounds")
+	ErrNoReference = errors.New("convert: no reference price")
+	ErrExpired     = errors.New("convert: quote expired")
+	ErrSlippage    = errors.New("convert: quote price out of tolerance")
+	ErrRejected    = errors.New("convert: trade rejected")
+)
+
+type (
+	// Config tunes the checks made before executing a quote
+	Config struct {
+		// MaxSlippage is the tolerated gap between the quoted and the reference price, 0.005 for 0.5%. The price is
+		// not checked when zero.
+		MaxSlippage float64
+		// MinTTL is the validity a quote must have left to be executed, 500ms when zero
+		MinTTL time.Duration
+		Tag    string
+	}
+
+	// Request converts Amt of From into To
+	Request struct {
+		From string
+		To   string
+		Amt  float64
+		// RefPx is the reference price of the base currency of the pair in its quote currency. The last price of the
+		// spot instrument of the pair is used when zero.
+		RefPx float64
+		// ClientID identifies the trade, one is generated when empty
+		ClientID string
+	}
+
+	// Result is an executed conversion
+	Result struct {
+		Quote    *models.Quote
+		Trade    *models.Trade
+		RefPx    float64
+		Slippage float64
+	}
+
+	// Converter executes conversions
+	Converter struct {
+		convert api.ConvertAPI
+		market  api.MarketAPI
+		cfg     Config
+	}
+)
+
+// NewConverter returns a pointer to a fresh Converter. The market client, used for reference prices, may be nil when
+// every request sets RefPx or the price is not checked.
+func NewConverter(c api.ConvertAPI, m api.MarketAPI, cfg Config) *Converter {
+	if cfg.MinTTL == 0 {
+		cfg.MinTTL = 500 * time.Millisecond
+	}
+	return &Converter{convert: c, market: m, cfg: cfg}
+}
+
+// Currencies returns the currencies that can be converted
+func (c *Converter) Currencies() ([]*models.Currency, error) {
+	res, err := c.convert.GetCurrencies()
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("convert: get currencies failed: %d %s", res.Code, res.Msg)
+	}
+	return res.Currencies, nil
+}
+
+// Pair returns the pair converting From into To
+func (c *Converter) Pair(from, to string) (*models.CurrencyPair, error) {
+	res, err := c.convert.GetCurrencyPair(requests.GetCurrencyPair{FromCcy: from, ToCcy: to})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("convert: get currency pair failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.CurrencyPairs) == 0 {
+		return nil, fmt.Errorf("convert: no pair converting %s into %s", from, to)
+	}
+	return res.CurrencyPairs[0], nil
+}
+
+// Quote requests a quote for a conversion without executing it
+func (c *Converter) Quote(req Request) (*models.Quote, error) {
+	pair, err := c.Pair(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	return c.quote(pair, req)
+}
+
+// Convert requests a quote, checks its validity and price, then executes it. When the trade request fails in
+// transit, the outcome is looked up in the history by client ID before the error is returned.
+func (c *Converter) Convert(req Request) (*Result, error) {
+	if req.ClientID == "" {
+		req.ClientID = "cv" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	pair, err := c.Pair(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	ref := req.RefPx
+	if ref == 0 && c.cfg.MaxSlippage > 0 {
+		if ref, err = c.reference(pair.InstID); err != nil {
+			return nil, err
+		}
+	}
+	q, err := c.quote(pair, req)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{Quote: q, RefPx: ref}
+	if ref > 0 {
+		r.Slippage = slippage(q.Side, float64(q.CnvtPx), ref)
+		if c.cfg.MaxSlippage > 0 && r.Slippage > c.cfg.MaxSlippage {
+			return r, fmt.Errorf("%w: %s %s at %g, reference %g", ErrSlippage, q.Side, pair.InstID, float64(q.CnvtPx), ref)
+		}
+	}
+	if left := c.ttl(q); left < c.cfg.MinTTL {
+		return r, fmt.Errorf("%w: %s left", ErrExpired, left)
+	}
+
+	t, err := c.trade(q, req.ClientID)
+	if err != nil {
+		var lerr error
+		if t, lerr = c.lookup(req.ClientID); lerr != nil || t == nil {
+			return r, err
+		}
+	}
+	r.Trade = t
+	if t.State != okex.ConvertFullyFilled {
+		return r, fmt.Errorf("%w: %s %s", ErrRejected, t.TradeID, t.State)
+	}
+	return r, nil
+}
+
+// History returns the conversions made after before and before after, the latest first
+func (c *Converter) History(req requests.GetHistory) ([]*models.Trade, error) {
+	if req.Tag == "" {
+		req.Tag = c.cfg.Tag
+	}
+	res, err := c.convert.GetHistory(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("convert: get history failed: %d %s", res.Code, res.Msg)
+	}
+	return res.Trades, nil
+}
+
+func (c *Converter) quote(pair *models.CurrencyPair, req Request) (*models.Quote, error) {
+	side, lo, hi := okex.OrderSell, float64(pair.BaseCcyMin), float64(pair.BaseCcyMax)
+	if req.From != pair.BaseCcy {
+		side, lo, hi = okex.OrderBuy, float64(pair.QuoteCcyMin), float64(pair.QuoteCcyMax)
+	}
+	if req.Amt < lo || (hi > 0 && req.Amt > hi) {
+		return nil, fmt.Errorf("%w: %g %s not in [%g, %g]", ErrAmount, req.Amt, req.From, lo, hi)
+	}
+	res, err := c.convert.EstimateQuote(requests.EstimateQuote{
+		BaseCcy:  pair.BaseCcy,
+		QuoteCcy: pair.QuoteCcy,
+		Side:     side,
+		RfqSz:    req.Amt,
+		RfqSzCcy: req.From,
+		Tag:      c.cfg.Tag,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("convert: estimate quote failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Quotes) == 0 {
+		return nil, fmt.Errorf("convert: estimate quote returned no quote")
+	}
+	return res.Quotes[0], nil
+}
+
+func (c *Converter) trade(q *models.Quote, clientID string) (*models.Trade, error) {
+	res, err := c.convert.Trade(requests.Trade{
+		QuoteID:  q.QuoteID,
+		BaseCcy:  q.BaseCcy,
+		QuoteCcy: q.QuoteCcy,
+		Side:     q.Side,
+		Sz:       float64(q.RfqSz),
+		SzCcy:    q.RfqSzCcy,
+		ClTReqID: clientID,
+		Tag:      c.cfg.Tag,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("convert: trade failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Trades) == 0 {
+		return nil, fmt.Errorf("convert: trade returned no trade")
+	}
+	return res.Trades[0], nil
+}
+
+// lookup returns the trade of a client ID, nil when there is none
+func (c *Converter) lookup(clientID string) (*models.Trade, error) {
+	ts, err := c.History(requests.GetHistory{ClTReqID: clientID})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		if t.ClTReqID == clientID {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *Converter) reference(instID string) (float64, error) {
+	if c.market == nil {
+		return 0, fmt.Errorf("%w: %s", ErrNoReference, instID)
+	}
+	res, err := c.market.GetTicker(marketRequests.GetTickerRequest{InstID: instID})
+	if err != nil {
+		return 0, err
+	}
+	if res.Code != 0 {
+		return 0, fmt.Errorf("convert: get ticker failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Tickers) == 0 || res.Tickers[0].Last <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoReference, instID)
+	}
+	return float64(res.Tickers[0].Last), nil
+}
+
+// ttl returns the validity a quote has left
+func (c *Converter) ttl(q *models.Quote) time.Duration {
+	exp := time.Time(q.QuoteTime).Add(time.Duration(q.TTLMs) * time.Millisecond)
+	return time.Until(exp)
+}
+
+// slippage returns how much worse than the reference a quoted price is, relative to the reference, negative when it
+// is better
+func slippage(side okex.OrderSide, px, ref float64) float64 {
+	if ref == 0 {
+		return math.Inf(1)
+	}
+	if side == okex.OrderBuy {
+		return (px - ref) / ref
+	}
+	return (ref - px) / ref
+}
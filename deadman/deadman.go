@@ -0,0 +1,154 @@
+// Package deadman keeps the exchange cancel-all-after countdown armed while the process is healthy.
+//
+// As soon as a health check fails, the countdown is no longer refreshed and the exchange cancels every pending order
+// once it expires.
+package deadman
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/api/ws"
+	requests "github.com/yitech/okex/requests/rest/trade"
+)
+
+const (
+	// MinTimeout is the shortest countdown the exchange accepts
+	MinTimeout = 10 * time.Second
+	// MaxTimeout is the longest countdown the exchange accepts
+	MaxTimeout = 120 * time.Second
+)
+
+type (
+	// Check reports whether the process is healthy enough to keep its orders on the book
+	Check func() bool
+
+	// ErrorHandler is called whenever refreshing the countdown fails
+	ErrorHandler func(err error)
+
+	// Keeper refreshes the countdown at a fixed interval while every check passes
+	Keeper struct {
+		trade       api.TradeAPI
+		timeout     time.Duration
+		interval    time.Duration
+		mu          sync.RWMutex
+		checks      []Check
+		onError     []ErrorHandler
+		triggerTime time.Time
+		armed       bool
+	}
+)
+
+// NewKeeper returns a pointer to a fresh Keeper. The countdown is refreshed every third of the timeout.
+func NewKeeper(t api.TradeAPI, timeout time.Duration) (*Keeper, error) {
+	if timeout < MinTimeout || timeout > MaxTimeout {
+		return nil, fmt.Errorf("deadman: timeout must be between %s and %s", MinTimeout, MaxTimeout)
+	}
+	return &Keeper{trade: t, timeout: timeout, interval: timeout / 3}, nil
+}
+
+// AddCheck registers a health check
+func (k *Keeper) AddCheck(c Check) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.checks = append(k.checks, c)
+}
+
+// WatchWs ties the countdown to the private websocket connection, losing it or its login stops the refresh
+func (k *Keeper) WatchWs(c *ws.ClientWs) {
+	k.AddCheck(func() bool {
+		return c.Alive(true) && c.Authorized
+	})
+}
+
+// OnError registers a handler for refresh failures
+func (k *Keeper) OnError(h ErrorHandler) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.onError = append(k.onError, h)
+}
+
+// TriggerTime returns the time at which the exchange will cancel every order if the countdown is not refreshed
+func (k *Keeper) TriggerTime() time.Time {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.triggerTime
+}
+
+// Armed reports whether the last refresh succeeded
+func (k *Keeper) Armed() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.armed && time.Now().Before(k.triggerTime)
+}
+
+// Run refreshes the countdown until the context is done. The countdown is left running on return,
+// call Disarm to stop it on a clean shutdown.
+func (k *Keeper) Run(ctx context.Context) error {
+	k.refresh()
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			k.refresh()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Disarm turns the countdown off
+func (k *Keeper) Disarm() error {
+	res, err := k.trade.CancelAllAfter(requests.CancelAllAfterRequest{TimeOut: 0})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("deadman: disarm failed: %d %s", res.Code, res.Msg)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.armed = false
+	k.triggerTime = time.Time{}
+	return nil
+}
+
+func (k *Keeper) healthy() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, c := range k.checks {
+		if !c() {
+			return false
+		}
+	}
+	return true
+}
+
+func (k *Keeper) refresh() {
+	if !k.healthy() {
+		return
+	}
+	res, err := k.trade.CancelAllAfter(requests.CancelAllAfterRequest{TimeOut: int64(k.timeout / time.Second)})
+	if err == nil && res.Code != 0 {
+		err = fmt.Errorf("deadman: refresh failed: %d %s", res.Code, res.Msg)
+	}
+	k.mu.Lock()
+	if err == nil {
+		k.armed = true
+		k.triggerTime = time.Now().Add(k.timeout)
+		if len(res.Results) > 0 && !time.Time(res.Results[0].TriggerTime).IsZero() {
+			k.triggerTime = time.Time(res.Results[0].TriggerTime)
+		}
+	}
+	hs := k.onError
+	k.mu.Unlock()
+	if err != nil {
+		for _, h := range hs {
+			h(err)
+		}
+	}
+}
@@ -30,9 +30,22 @@ type (
 	OrderType            string
 	AlgoOrderType        string
 	QuantityType         string
+	STPMode              string
+	QuickMarginType      string
+	TriggerPxType        string
 	OrderFlowType        string
 	OrderState           string
 	ActionType           string
+	ProtocolType         string
+	StakingOfferState    string
+	StakingOrderState    string
+	ConvertTradeState    string
+	GridAlgoOrderType    string
+	GridRunType          string
+	GridDirection        string
+	GridState            string
+	GridStopType         string
+	GridSubOrderType     string
 	APIKeyAccess         string
 	OptionType           string
 	AliasType            string
@@ -43,8 +56,10 @@ type (
 	Destination           int
 	BillType              uint8
 	BillSubType           uint8
+	AssetBillType         uint16
 	FeeCategory           uint8
 	TransferType          uint8
+	SubAccountBillType    uint8
 	AccountType           uint8
 	DepositState          uint8
 	WithdrawalDestination uint8
@@ -62,23 +77,27 @@ const (
 	AwsServer
 	DemoServer
 
-	RestURL      = BaseURL("https://www.okx.com")
-	PublicWsURL  = BaseURL("wss://ws.okx.com:8443/ws/v5/public")
-	PrivateWsURL = BaseURL("wss://ws.okx.com:8443/ws/v5/private")
+	RestURL       = BaseURL("https://www.okx.com")
+	PublicWsURL   = BaseURL("wss://ws.okx.com:8443/ws/v5/public")
+	PrivateWsURL  = BaseURL("wss://ws.okx.com:8443/ws/v5/private")
+	BusinessWsURL = BaseURL("wss://ws.okx.com:8443/ws/v5/business")
 
-	AwsRestURL      = BaseURL("https://aws.okx.com")
-	AwsPublicWsURL  = BaseURL("wss://wsaws.okx.com:8443/ws/v5/public")
-	AwsPrivateWsURL = BaseURL("wss://wsaws.okx.com:8443/ws/v5/private")
+	AwsRestURL       = BaseURL("https://aws.okx.com")
+	AwsPublicWsURL   = BaseURL("wss://wsaws.okx.com:8443/ws/v5/public")
+	AwsPrivateWsURL  = BaseURL("wss://wsaws.okx.com:8443/ws/v5/private")
+	AwsBusinessWsURL = BaseURL("wss://wsaws.okx.com:8443/ws/v5/business")
 
-	DemoRestURL      = BaseURL("https://www.okx.com")
-	DemoPublicWsURL  = BaseURL("wss://wspap.okx.com:8443/ws/v5/public?brokerId=9999")
-	DemoPrivateWsURL = BaseURL("wss://wspap.okx.com:8443/ws/v5/private?brokerId=9999")
+	DemoRestURL       = BaseURL("https://www.okx.com")
+	DemoPublicWsURL   = BaseURL("wss://wspap.okx.com:8443/ws/v5/public?brokerId=9999")
+	DemoPrivateWsURL  = BaseURL("wss://wspap.okx.com:8443/ws/v5/private?brokerId=9999")
+	DemoBusinessWsURL = BaseURL("wss://wspap.okx.com:8443/ws/v5/business?brokerId=9999")
 
 	SpotInstrument    = InstrumentType("SPOT")
 	MarginInstrument  = InstrumentType("MARGIN")
 	SwapInstrument    = InstrumentType("SWAP")
 	FuturesInstrument = InstrumentType("FUTURES")
 	OptionsInstrument = InstrumentType("OPTION")
+	AnyInstrument     = InstrumentType("ANY")
 
 	MarginCrossMode    = MarginMode("cross")
 	MarginIsolatedMode = MarginMode("isolated")
@@ -142,6 +161,29 @@ const (
 	BillSystemTransferOutSubType                = BillSubType(202)
 	BillManuallyTransferOutSubType              = BillSubType(203)
 
+	AssetBillDepositType              = AssetBillType(1)
+	AssetBillWithdrawalType           = AssetBillType(2)
+	AssetBillCanceledWithdrawalType   = AssetBillType(13)
+	AssetBillTransferToSubType        = AssetBillType(20)
+	AssetBillTransferFromSubType      = AssetBillType(21)
+	AssetBillTransferOutToMasterType  = AssetBillType(22)
+	AssetBillTransferInFromMasterType = AssetBillType(23)
+	AssetBillAirdropType              = AssetBillType(28)
+	AssetBillSystemReversalType       = AssetBillType(47)
+	AssetBillEventRewardType          = AssetBillType(48)
+	AssetBillEventGiveawayType        = AssetBillType(49)
+	AssetBillFeeRebateType            = AssetBillType(68)
+	AssetBillSavingsSubscriptionType  = AssetBillType(75)
+	AssetBillSavingsRedemptionType    = AssetBillType(76)
+	AssetBillStakingPurchaseType      = AssetBillType(80)
+	AssetBillStakingRedemptionType    = AssetBillType(82)
+	AssetBillStakingYieldType         = AssetBillType(83)
+	AssetBillSavingsYieldType         = AssetBillType(89)
+	AssetBillTransferFromTradingType  = AssetBillType(130)
+	AssetBillTransferToTradingType    = AssetBillType(131)
+	AssetBillAffiliateCommissionType  = AssetBillType(150)
+	AssetBillReferralRewardType       = AssetBillType(151)
+
 	PositionLongShortMode = PositionType("long_short_mode")
 	PositionNetMode       = PositionType("net_mode")
 
@@ -202,16 +244,32 @@ const (
 	OrderFOK             = OrderType("fok")
 	OrderIOC             = OrderType("ioc")
 	OrderOptimalLimitIoc = OrderType("optimal_limit_ioc")
+	OrderMMP             = OrderType("mmp")
+	OrderMMPAndPostOnly  = OrderType("mmp_and_post_only")
+	OrderOpFOK           = OrderType("op_fok")
 
 	AlgoOrderConditional = AlgoOrderType("conditional")
 	AlgoOrderOCO         = AlgoOrderType("oco")
 	AlgoOrderTrigger     = AlgoOrderType("trigger")
 	AlgoOrderIceberg     = AlgoOrderType("iceberg")
 	AlgoOrderTwap        = AlgoOrderType("twap")
+	AlgoOrderMoveStop    = AlgoOrderType("move_order_stop")
 
 	QuantityBaseCcy  = QuantityType("base_ccy")
 	QuantityQuoteCcy = QuantityType("quote_ccy")
 
+	STPCancelMaker = STPMode("cancel_maker")
+	STPCancelTaker = STPMode("cancel_taker")
+	STPCancelBoth  = STPMode("cancel_both")
+
+	QuickMarginManual     = QuickMarginType("manual")
+	QuickMarginAutoBorrow = QuickMarginType("auto_borrow")
+	QuickMarginAutoRepay  = QuickMarginType("auto_repay")
+
+	TriggerPxLast  = TriggerPxType("last")
+	TriggerPxIndex = TriggerPxType("index")
+	TriggerPxMark  = TriggerPxType("mark")
+
 	OrderTakerFlow = OrderFlowType("T")
 	OrderMakerFlow = OrderFlowType("M")
 
@@ -227,9 +285,19 @@ const (
 	OrderFilled          = OrderState("filled")
 	OrderUnfilled        = OrderState("unfilled")
 
+	AlgoOrderEffective          = OrderState("effective")
+	AlgoOrderPartiallyEffective = OrderState("partially_effective")
+	AlgoOrderFailed             = OrderState("order_failed")
+	AlgoOrderPartiallyFailed    = OrderState("partially_failed")
+
 	TransferWithinAccount     = TransferType(0)
 	MasterAccountToSubAccount = TransferType(1)
 	MasterSubAccountToAccount = TransferType(2)
+	SubAccountToMasterAccount = TransferType(3)
+	SubAccountToSubAccount    = TransferType(4)
+
+	SubAccountBillMasterToSubType = SubAccountBillType(0)
+	SubAccountBillSubToMasterType = SubAccountBillType(1)
 
 	SpotAccount    = AccountType(1)
 	FuturesAccount = AccountType(3)
@@ -239,10 +307,14 @@ const (
 	OptionsAccount = AccountType(12)
 	UnifiedAccount = AccountType(18)
 
-	WaitingForConfirmation     = DepositState(0)
-	DepositCredited            = DepositState(1)
-	DepositSuccessful          = DepositState(2)
-	DepositTemporarySuspension = DepositState(8)
+	WaitingForConfirmation       = DepositState(0)
+	DepositCredited              = DepositState(1)
+	DepositSuccessful            = DepositState(2)
+	DepositTemporarySuspension   = DepositState(8)
+	DepositAddressBlacklisted    = DepositState(11)
+	DepositAccountFrozen         = DepositState(12)
+	DepositSubAccountIntercepted = DepositState(13)
+	DepositKYCLimit              = DepositState(14)
 
 	WithdrawalOkexDestination           = WithdrawalDestination(3)
 	WithdrawalDigitalAddressDestination = WithdrawalDestination(4)
@@ -256,10 +328,51 @@ const (
 	WithdrawalAwaitingEmailVerification  = WithdrawalState(3)
 	WithdrawalAwaitingManualVerification = WithdrawalState(4)
 	WithdrawalIdentityManualVerification = WithdrawalState(5)
+	WithdrawalApproved                   = WithdrawalState(7)
+	WithdrawalWaitingTransfer            = WithdrawalState(10)
 
 	ActionPurchase = ActionType("purchase")
 	ActionRedempt  = ActionType("redempt")
 
+	ProtocolStaking = ProtocolType("staking")
+	ProtocolDefi    = ProtocolType("defi")
+
+	StakingOfferPurchasable = StakingOfferState("purchasable")
+	StakingOfferSoldOut     = StakingOfferState("sold_out")
+	StakingOfferStopped     = StakingOfferState("stop")
+
+	StakingOrderEarning   = StakingOrderState("1")
+	StakingOrderRedeeming = StakingOrderState("2")
+	StakingOrderPending   = StakingOrderState("8")
+	StakingOrderOnChain   = StakingOrderState("9")
+	StakingOrderCanceling = StakingOrderState("13")
+
+	ConvertFullyFilled = ConvertTradeState("fullyFilled")
+	ConvertRejected    = ConvertTradeState("rejected")
+
+	GridSpot     = GridAlgoOrderType("grid")
+	GridContract = GridAlgoOrderType("contract_grid")
+
+	GridArithmetic = GridRunType("1")
+	GridGeometric  = GridRunType("2")
+
+	GridLong    = GridDirection("long")
+	GridShort   = GridDirection("short")
+	GridNeutral = GridDirection("neutral")
+
+	GridStarting        = GridState("starting")
+	GridRunning         = GridState("running")
+	GridStopping        = GridState("stopping")
+	GridPendingSignal   = GridState("pending_signal")
+	GridNoClosePosition = GridState("no_close_position")
+	GridStopped         = GridState("stopped")
+
+	GridStopSellOrClose = GridStopType("1")
+	GridStopKeep        = GridStopType("2")
+
+	GridSubOrderLive   = GridSubOrderType("live")
+	GridSubOrderFilled = GridSubOrderType("filled")
+
 	APIKeyReadOnly = APIKeyAccess("read_only")
 	APIKeyTrade    = APIKeyAccess("trade")
 
@@ -458,6 +571,8 @@ func (t BarSize) Duration() time.Duration {
 	return time.Minute
 }
 
+// S2M converts a request to its parameters. Values not encoded as JSON strings are dropped, so numbers and bools need
+// a `,string` tag; requests holding arrays or objects are sent as a raw body instead.
 func S2M(i interface{}) map[string]string {
 	m := make(map[string]string)
 	j, _ := json.Marshal(i)
@@ -0,0 +1,190 @@
+package deposit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/funding"
+	requests "github.com/yitech/okex/requests/rest/funding"
+)
+
+// ErrNoAddress is returned when every loaded deposit address of a chain is assigned. The API lists addresses but
+// cannot create them, more are created on the web, or per sub-account.
+var ErrNoAddress = errors.New("deposit: no free deposit address")
+
+type (
+	// Assignment ties a deposit address to a client
+	Assignment struct {
+		Client string `json:"client"`
+		Ccy    string `json:"ccy"`
+		Chain  string `json:"chain"`
+		Addr   string `json:"addr"`
+		// Tag is the tag, memo or payment ID of chains needing one
+		Tag string `json:"tag,omitempty"`
+	}
+
+	addressKey struct {
+		ccy   string
+		chain string
+		addr  string
+	}
+
+	clientKey struct {
+		ccy    string
+		chain  string
+		client string
+	}
+
+	// AddressBook hands out the deposit addresses of the account to clients, one per client and chain
+	AddressBook struct {
+		funding   api.FundingAPI
+		mu        sync.RWMutex
+		addresses map[string][]*funding.DepositAddress
+		byAddr    map[addressKey]*Assignment
+		byClient  map[clientKey]*Assignment
+	}
+)
+
+// NewAddressBook returns a pointer to a fresh AddressBook
+func NewAddressBook(f api.FundingAPI) *AddressBook {
+	return &AddressBook{
+		funding:   f,
+		addresses: make(map[string][]*funding.DepositAddress),
+		byAddr:    make(map[addressKey]*Assignment),
+		byClient:  make(map[clientKey]*Assignment),
+	}
+}
+
+// Load fetches the deposit addresses of a currency on every chain
+func (b *AddressBook) Load(ccy string) error {
+	res, err := b.funding.GetDepositAddress(requests.GetDepositAddress{Ccy: ccy})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("deposit: get deposit address failed: %d %s", res.Code, res.Msg)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addresses[ccy] = res.DepositAddresses
+	return nil
+}
+
+// Addresses returns copies of the loaded addresses of a currency, on a chain unless it is empty
+func (b *AddressBook) Addresses(ccy, chain string) []*funding.DepositAddress {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var res []*funding.DepositAddress
+	for _, a := range b.addresses[ccy] {
+		if chain == "" || a.Chain == chain {
+			c := *a
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// Assign returns the address of a client on a chain, assigning a free one on first use. The default address of the
+// account, selected on the web, is never handed out.
+func (b *AddressBook) Assign(client, ccy, chain string) (*Assignment, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if a, ok := b.byClient[clientKey{ccy: ccy, chain: chain, client: client}]; ok {
+		c := *a
+		return &c, nil
+	}
+	for _, d := range b.addresses[ccy] {
+		if d.Chain != chain || d.Selected {
+			continue
+		}
+		a := &Assignment{Client: client, Ccy: ccy, Chain: chain, Addr: d.Addr, Tag: tag(d)}
+		if _, ok := b.byAddr[a.key()]; ok {
+			continue
+		}
+		b.set(a)
+		c := *a
+		return &c, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoAddress, ccy, chain)
+}
+
+// SetAssignment restores an assignment, e.g. from the client database, replacing the previous one of the client
+func (b *AddressBook) SetAssignment(a Assignment) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set(&a)
+}
+
+// Release frees the address of a client on a chain
+func (b *AddressBook) Release(client, ccy, chain string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	k := clientKey{ccy: ccy, chain: chain, client: client}
+	if a, ok := b.byClient[k]; ok {
+		delete(b.byAddr, a.key())
+		delete(b.byClient, k)
+	}
+}
+
+// Assignments returns copies of every assignment, by client
+func (b *AddressBook) Assignments() []*Assignment {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	res := make([]*Assignment, 0, len(b.byClient))
+	for _, a := range b.byClient {
+		c := *a
+		res = append(res, &c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Client != res[j].Client {
+			return res[i].Client < res[j].Client
+		}
+		if res[i].Ccy != res[j].Ccy {
+			return res[i].Ccy < res[j].Ccy
+		}
+		return res[i].Chain < res[j].Chain
+	})
+	return res
+}
+
+// Client returns the client an address is assigned to. Addresses of chains with tags are written as address:tag, as
+// in the deposit history.
+func (b *AddressBook) Client(ccy, chain, addr string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	a, ok := b.byAddr[addressKey{ccy: ccy, chain: chain, addr: addr}]
+	if !ok {
+		return "", false
+	}
+	return a.Client, true
+}
+
+func (b *AddressBook) set(a *Assignment) {
+	k := clientKey{ccy: a.Ccy, chain: a.Chain, client: a.Client}
+	if old, ok := b.byClient[k]; ok {
+		delete(b.byAddr, old.key())
+	}
+	b.byClient[k] = a
+	b.byAddr[a.key()] = a
+}
+
+func (a *Assignment) key() addressKey {
+	addr := a.Addr
+	if a.Tag != "" {
+		addr += ":" + a.Tag
+	}
+	return addressKey{ccy: a.Ccy, chain: a.Chain, addr: addr}
+}
+
+func tag(d *funding.DepositAddress) string {
+	switch {
+	case d.Tag != "":
+		return d.Tag
+	case d.Memo != "":
+		return d.Memo
+	}
+	return d.PmtID
+}
@@ -0,0 +1,304 @@
+// Package deposit follows incoming deposits from detection to credit, from the deposit history and the deposit-info
+// channel, and attributes them to clients through an address book of deposit addresses.
+package deposit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/models/funding"
+	requests "github.com/yitech/okex/requests/rest/funding"
+)
+
+// EventKind tells what changed in a deposit
+type EventKind string
+
+const (
+	// EventDetected is emitted the first time a deposit is seen
+	EventDetected = EventKind("detected")
+	// EventConfirmation is emitted when the number of block confirmations grows
+	EventConfirmation = EventKind("confirmation")
+	// EventCredited is emitted once the deposit is credited, it may not be withdrawn yet
+	EventCredited = EventKind("credited")
+	// EventSuccessful is emitted once the deposit is final
+	EventSuccessful = EventKind("successful")
+	// EventHeld is emitted when the deposit is suspended, intercepted or frozen
+	EventHeld = EventKind("held")
+)
+
+const historyLimit = 100
+
+type (
+	// Deposit is the last known state of a deposit
+	Deposit struct {
+		DepID         string
+		Ccy           string
+		Chain         string
+		TxID          string
+		From          string
+		To            string
+		Amt           float64
+		State         okex.DepositState
+		Confirmations int64
+		// Client owns the address the deposit was made to, empty when the address is not in the address book
+		Client  string
+		SubAcct string
+		TS      time.Time
+		UTime   time.Time
+	}
+
+	// Event is a change of a deposit
+	Event struct {
+		Kind      EventKind
+		Deposit   Deposit
+		PrevState okex.DepositState
+	}
+
+	// EventHandler is called for every change of a watched deposit
+	EventHandler func(e *Event)
+
+	watchKey struct {
+		ccy   string
+		chain string
+	}
+
+	// Watcher tracks the deposits of the watched currencies and chains
+	Watcher struct {
+		funding  api.FundingAPI
+		book     *AddressBook
+		mu       sync.RWMutex
+		watched  map[watchKey]bool
+		deposits map[string]*Deposit
+		lastPoll time.Time
+		lookback time.Duration
+		onEvent  []EventHandler
+	}
+)
+
+// NewWatcher returns a pointer to a fresh Watcher. The first poll looks back over lookback, a day when zero.
+func NewWatcher(f api.FundingAPI, lookback time.Duration) *Watcher {
+	if lookback == 0 {
+		lookback = 24 * time.Hour
+	}
+	return &Watcher{
+		funding:  f,
+		watched:  make(map[watchKey]bool),
+		deposits: make(map[string]*Deposit),
+		lookback: lookback,
+	}
+}
+
+// SetAddressBook attributes the deposits to the clients of the book
+func (w *Watcher) SetAddressBook(b *AddressBook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.book = b
+}
+
+// Watch restricts the watcher to a currency, and a chain unless it is empty. Every deposit is watched until the
+// first call.
+func (w *Watcher) Watch(ccy, chain string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.watched[watchKey{ccy: ccy, chain: chain}] = true
+}
+
+// OnEvent registers an event handler
+func (w *Watcher) OnEvent(h EventHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onEvent = append(w.onEvent, h)
+}
+
+// Poll fetches the deposit history back to the last poll, or to the oldest deposit not final yet
+func (w *Watcher) Poll() error {
+	now := time.Now()
+	w.mu.RLock()
+	cutoff := now.Add(-w.lookback)
+	if !w.lastPoll.IsZero() {
+		cutoff = w.lastPoll.Add(-time.Minute)
+		for _, d := range w.deposits {
+			if !final(d.State) && d.TS.Before(cutoff) {
+				cutoff = d.TS
+			}
+		}
+	}
+	w.mu.RUnlock()
+
+	var (
+		hs    []*funding.DepositHistory
+		after int64
+	)
+	for {
+		res, err := w.funding.GetDepositHistory(requests.GetDepositHistory{After: after, Limit: historyLimit})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("deposit: get deposit history failed: %d %s", res.Code, res.Msg)
+		}
+		done := len(res.DepositHistories) < historyLimit
+		for _, h := range res.DepositHistories {
+			ts := time.Time(h.TS)
+			if ts.Before(cutoff) {
+				done = true
+				continue
+			}
+			hs = append(hs, h)
+			after = ts.UnixMilli()
+		}
+		if done || after == 0 {
+			break
+		}
+	}
+
+	// oldest first, so that events come in the order of the deposits
+	var events []*Event
+	w.mu.Lock()
+	for i := len(hs) - 1; i >= 0; i-- {
+		events = append(events, w.apply(hs[i], "")...)
+	}
+	w.lastPoll = now
+	handlers := w.onEvent
+	w.mu.Unlock()
+	emit(handlers, events)
+	return nil
+}
+
+// HandleDepositInfo applies a `deposit-info` channel push
+func (w *Watcher) HandleDepositInfo(e *private.DepositInfo) {
+	var events []*Event
+	w.mu.Lock()
+	for _, d := range e.Deposits {
+		h := d.DepositHistory
+		events = append(events, w.apply(&h, d.SubAcct)...)
+	}
+	hs := w.onEvent
+	w.mu.Unlock()
+	emit(hs, events)
+}
+
+// Run polls on every tick until the context is done. With the deposit-info channel subscribed, polling only catches
+// what the channel missed and can be infrequent.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Poll()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Deposit returns a copy of a deposit
+func (w *Watcher) Deposit(depID string) (*Deposit, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	d, ok := w.deposits[depID]
+	if !ok {
+		return nil, false
+	}
+	c := *d
+	return &c, true
+}
+
+// Pending returns copies of the deposits not final yet, the oldest first
+func (w *Watcher) Pending() []*Deposit {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var res []*Deposit
+	for _, d := range w.deposits {
+		if !final(d.State) {
+			c := *d
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].TS.Before(res[j].TS)
+	})
+	return res
+}
+
+// Prune forgets the final deposits made before t
+func (w *Watcher) Prune(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id, d := range w.deposits {
+		if final(d.State) && d.TS.Before(t) {
+			delete(w.deposits, id)
+		}
+	}
+}
+
+// apply merges a deposit record and returns the events it caused, it is called with the lock held
+func (w *Watcher) apply(h *funding.DepositHistory, subAcct string) []*Event {
+	if h.DepId == "" || !w.watches(h.Ccy, h.Chain) {
+		return nil
+	}
+	d, seen := w.deposits[h.DepId]
+	if !seen {
+		d = &Deposit{DepID: h.DepId, Ccy: h.Ccy, Chain: h.Chain, State: h.State}
+		w.deposits[h.DepId] = d
+	}
+	prev, prevConf := d.State, d.Confirmations
+	d.TxID, d.From, d.To, d.Amt = h.TxID, h.From, h.To, float64(h.Amt)
+	d.State, d.Confirmations = h.State, max(d.Confirmations, int64(h.ActualDepBlkConfirm))
+	d.TS, d.UTime = time.Time(h.TS), time.Now()
+	if subAcct != "" {
+		d.SubAcct = subAcct
+	}
+	if d.Client == "" && w.book != nil {
+		d.Client, _ = w.book.Client(d.Ccy, d.Chain, d.To)
+	}
+
+	var res []*Event
+	add := func(k EventKind) {
+		res = append(res, &Event{Kind: k, Deposit: *d, PrevState: prev})
+	}
+	if !seen {
+		add(EventDetected)
+	} else if d.Confirmations > prevConf {
+		add(EventConfirmation)
+	}
+	if seen && d.State == prev {
+		return res
+	}
+	switch d.State {
+	case okex.DepositCredited:
+		add(EventCredited)
+	case okex.DepositSuccessful:
+		add(EventSuccessful)
+	case okex.DepositTemporarySuspension, okex.DepositAddressBlacklisted, okex.DepositAccountFrozen,
+		okex.DepositSubAccountIntercepted, okex.DepositKYCLimit:
+		add(EventHeld)
+	}
+	return res
+}
+
+func (w *Watcher) watches(ccy, chain string) bool {
+	if len(w.watched) == 0 {
+		return true
+	}
+	return w.watched[watchKey{ccy: ccy}] || w.watched[watchKey{ccy: ccy, chain: chain}]
+}
+
+func final(s okex.DepositState) bool {
+	return s == okex.DepositSuccessful
+}
+
+func emit(hs []EventHandler, es []*Event) {
+	for _, e := range es {
+		for _, h := range hs {
+			h(e)
+		}
+	}
+}
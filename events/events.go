@@ -52,6 +52,11 @@ type (
 	}
 )
 
+// NewArgument returns a pointer to an Argument holding the given fields, e.g. for events built locally
+func NewArgument(m map[string]interface{}) *Argument {
+	return &Argument{arg: m}
+}
+
 func (a *Argument) Get(k string) (interface{}, bool) {
 	v, ok := a.arg[k]
 	return v, ok
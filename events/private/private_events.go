@@ -3,7 +3,9 @@ package private
 import (
 	"github.com/yitech/okex/events"
 	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/funding"
 	"github.com/yitech/okex/models/trade"
+	"github.com/yitech/okex/models/tradingbot"
 )
 
 type (
@@ -23,4 +25,28 @@ type (
 		Arg    *events.Argument `json:"arg"`
 		Orders []*trade.Order   `json:"data"`
 	}
+	AlgoOrder struct {
+		Arg    *events.Argument   `json:"arg"`
+		Orders []*trade.AlgoOrder `json:"data"`
+	}
+	AdvanceAlgoOrder struct {
+		Arg    *events.Argument   `json:"arg"`
+		Orders []*trade.AlgoOrder `json:"data"`
+	}
+	DepositInfo struct {
+		Arg      *events.Argument       `json:"arg"`
+		Deposits []*funding.DepositInfo `json:"data"`
+	}
+	GridOrders struct {
+		Arg    *events.Argument        `json:"arg"`
+		Orders []*tradingbot.GridOrder `json:"data"`
+	}
+	GridPositions struct {
+		Arg       *events.Argument           `json:"arg"`
+		Positions []*tradingbot.GridPosition `json:"data"`
+	}
+	GridSubOrders struct {
+		Arg       *events.Argument           `json:"arg"`
+		SubOrders []*tradingbot.GridSubOrder `json:"data"`
+	}
 )
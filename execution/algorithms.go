@@ -0,0 +1,189 @@
+package execution
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/models/market"
+)
+
+type (
+	// TWAP spreads the parent evenly over Duration in Slices children. At every slice boundary the unfilled part of the
+	// previous child is cancelled and, once the cancellation is confirmed, rolled into the next one.
+	TWAP struct {
+		Duration time.Duration
+		Slices   int
+		// OrdType of the children, limit children are priced at the opposite touch. Defaults to limit.
+		OrdType okex.OrderType
+		sent    int
+	}
+
+	// VWAP follows a volume profile over Duration. Profile holds the weight of each equally sized bucket, see VolumeProfile.
+	VWAP struct {
+		Duration time.Duration
+		Profile  []float64
+		// OrdType of the children, limit children are priced at the opposite touch. Defaults to limit.
+		OrdType okex.OrderType
+		sent    int
+	}
+
+	// Iceberg shows DisplaySz at a time, randomized by up to Variance (a fraction of DisplaySz) on each child
+	Iceberg struct {
+		DisplaySz float64
+		Variance  float64
+		// Px of the children, zero joins the best bid or ask
+		Px float64
+	}
+
+	// Peg keeps a single child at the best bid (buy) or ask (sell), Offset away from it on the passive side.
+	// The child is amended whenever the touch moves.
+	Peg struct {
+		Offset float64
+		// OrdType of the child. Defaults to post_only.
+		OrdType okex.OrderType
+		px      float64
+	}
+)
+
+// Step places the child of the current slice
+func (a *TWAP) Step(x *Execution, now time.Time) error {
+	n := max(a.Slices, 1)
+	return schedule(x, now, a.Duration, n, &a.sent, a.OrdType, func(i int) float64 {
+		return float64(i) / float64(n)
+	})
+}
+
+// Step places the child of the current bucket
+func (a *VWAP) Step(x *Execution, now time.Time) error {
+	w := normalize(a.Profile)
+	return schedule(x, now, a.Duration, len(w), &a.sent, a.OrdType, func(i int) float64 {
+		var c float64
+		for _, v := range w[:i] {
+			c += v
+		}
+		return c
+	})
+}
+
+// Step places the next child once the previous one is done
+func (a *Iceberg) Step(x *Execution, _ time.Time) error {
+	if _, ok := x.Live(); ok {
+		return nil
+	}
+	px := a.Px
+	if px == 0 {
+		if px = touch(x, false); px == 0 {
+			return nil
+		}
+	}
+	sz := a.DisplaySz * (1 + a.Variance*(2*rand.Float64()-1))
+	if x.parent.LotSz > 0 {
+		sz = math.Max(sz, x.parent.LotSz)
+	}
+	return x.Place(sz, px, okex.OrderLimit)
+}
+
+// Step places the child or moves it to the touch
+func (a *Peg) Step(x *Execution, _ time.Time) error {
+	px := touch(x, false)
+	if px == 0 {
+		return nil
+	}
+	if x.parent.Side == okex.OrderBuy {
+		px -= a.Offset
+	} else {
+		px += a.Offset
+	}
+	px = x.roundPx(px)
+	if _, ok := x.Live(); ok {
+		if px == a.px {
+			return nil
+		}
+		a.px = px
+		return x.AmendLive(px)
+	}
+	a.px = px
+	ordType := a.OrdType
+	if ordType == "" {
+		ordType = okex.OrderPostOnly
+	}
+	return x.Place(x.Remaining(), px, ordType)
+}
+
+// VolumeProfile builds the weights of a VWAP over [start, start+d) from historical candles, e.g. the same hours of the
+// previous days. Candles are matched by time of day. Without volume the profile is flat.
+func VolumeProfile(candles []*market.Candle, start time.Time, d time.Duration, buckets int) []float64 {
+	w := make([]float64, max(buckets, 1))
+	day := 24 * time.Hour
+	from := start.UTC().Sub(start.UTC().Truncate(day))
+	for _, c := range candles {
+		t := time.Time(c.TS).UTC()
+		off := (t.Sub(t.Truncate(day)) - from + day) % day
+		if off >= d {
+			continue
+		}
+		w[int(int64(off)*int64(len(w))/int64(d))] += c.Vol
+	}
+	return normalize(w)
+}
+
+// schedule sends the children of slice based algorithms. target returns the share of the parent due by the end of
+// slice i, counted from one.
+func schedule(x *Execution, now time.Time, d time.Duration, n int, sent *int, ordType okex.OrderType, target func(i int) float64) error {
+	elapsed := now.Sub(x.Progress().StartTime)
+	if elapsed >= d {
+		x.Finish()
+		return nil
+	}
+	i := int(int64(elapsed)*int64(n)/int64(max(d, 1))) + 1
+	if i <= *sent {
+		return nil
+	}
+	if ordType == "" {
+		ordType = okex.OrderLimit
+	}
+	var px float64
+	if ordType != okex.OrderMarket {
+		if px = touch(x, true); px == 0 {
+			return nil
+		}
+	}
+	if _, ok := x.Live(); ok {
+		// the next child waits for the previous one to be cancelled, a late fill would overfill the parent
+		return x.CancelLive()
+	}
+	*sent = i
+	return x.Place(x.parent.Sz*target(i)-x.Filled(), px, ordType)
+}
+
+// touch returns the best price on the passive side, or on the opposite side when aggressive, falling back to the last
+// price
+func touch(x *Execution, aggressive bool) float64 {
+	bid, ask, last := x.Quote()
+	px := bid
+	if (x.parent.Side == okex.OrderBuy) == aggressive {
+		px = ask
+	}
+	if px == 0 {
+		px = last
+	}
+	return px
+}
+
+func normalize(w []float64) []float64 {
+	var sum float64
+	for _, v := range w {
+		sum += v
+	}
+	res := make([]float64, len(w))
+	for i, v := range w {
+		if sum > 0 {
+			res[i] = v / sum
+		} else {
+			res[i] = 1 / float64(len(w))
+		}
+	}
+	return res
+}
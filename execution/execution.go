@@ -0,0 +1,431 @@
+// Package execution slices parent orders into child orders on the client side.
+//
+// Children are sent through a Router and tracked by an oms.Manager, so they show up with every other order of the process.
+package execution
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/oms"
+	requests "github.com/yitech/okex/requests/rest/trade"
+)
+
+// State of an execution
+type State string
+
+const (
+	StateRunning   = State("running")
+	StatePaused    = State("paused")
+	StateCancelled = State("cancelled")
+	StateDone      = State("done")
+	StateFailed    = State("failed")
+)
+
+type (
+	// Parent is the order to be executed
+	Parent struct {
+		InstID string
+		TdMode okex.TradeMode
+		Side   okex.OrderSide
+		Tag    string
+		Sz     float64
+		// LimitPx is the worst price children may be sent at, zero means no limit
+		LimitPx float64
+		// LotSz, MinSz and TickSz round the children, zero disables rounding
+		LotSz  float64
+		MinSz  float64
+		TickSz float64
+	}
+
+	// Algorithm decides the children of an execution. Step is called on every tick while the execution runs.
+	// An algorithm keeps its own schedule, so a value must not be shared between executions.
+	Algorithm interface {
+		Step(x *Execution, now time.Time) error
+	}
+
+	// Progress is a snapshot of an execution
+	Progress struct {
+		State     State
+		Sz        float64
+		FilledSz  float64
+		AvgPx     float64
+		FillRate  float64
+		Children  int
+		StartTime time.Time
+		Elapsed   time.Duration
+		Err       error
+	}
+
+	// Execution runs an algorithm for a single parent order
+	Execution struct {
+		parent   Parent
+		algo     Algorithm
+		router   Router
+		oms      *oms.Manager
+		tick     time.Duration
+		mu       sync.RWMutex
+		state    State
+		err      error
+		start    time.Time
+		filled   float64
+		notional float64
+		children map[string]bool
+		live     string
+		// cancelling is the live child a cancel was sent for
+		cancelling string
+		release    func()
+		bid        float64
+		ask        float64
+		last       float64
+	}
+)
+
+// New returns a pointer to a fresh Execution. Its fills are taken from the order manager until Run returns.
+func New(p Parent, a Algorithm, r Router, m *oms.Manager) *Execution {
+	x := &Execution{
+		parent:   p,
+		algo:     a,
+		router:   r,
+		oms:      m,
+		tick:     time.Second,
+		state:    StatePaused,
+		children: make(map[string]bool),
+	}
+	x.release = m.OnFill(x.handleFill)
+	return x
+}
+
+// SetTick sets how often the algorithm is stepped
+func (x *Execution) SetTick(d time.Duration) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.tick = d
+}
+
+// Parent returns the parent order
+func (x *Execution) Parent() Parent {
+	return x.parent
+}
+
+// Run steps the algorithm until the parent is filled, the execution is cancelled or fails, or the context is done.
+// Before returning, it waits for the live child to be done so that its last fills are counted, unless the context is
+// done, then stops taking fills from the order manager.
+func (x *Execution) Run(ctx context.Context) error {
+	x.mu.Lock()
+	if x.state != StatePaused || !x.start.IsZero() {
+		x.mu.Unlock()
+		return fmt.Errorf("execution: already started")
+	}
+	x.state = StateRunning
+	x.start = time.Now()
+	tick := x.tick
+	x.mu.Unlock()
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	defer x.release()
+	for {
+		if done, err := x.step(time.Now()); done {
+			x.drain(ctx, ticker)
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			_ = x.Cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// drain waits for the live child to be done
+func (x *Execution) drain(ctx context.Context, ticker *time.Ticker) {
+	for {
+		if _, ok := x.Live(); !ok {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Pause stops sending children and cancels the live one
+func (x *Execution) Pause() error {
+	x.mu.Lock()
+	if x.state != StateRunning {
+		x.mu.Unlock()
+		return nil
+	}
+	x.state = StatePaused
+	x.mu.Unlock()
+	return x.CancelLive()
+}
+
+// Resume continues a paused execution
+func (x *Execution) Resume() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.state == StatePaused && !x.start.IsZero() {
+		x.state = StateRunning
+	}
+}
+
+// Cancel stops the execution for good and cancels the live child
+func (x *Execution) Cancel() error {
+	x.mu.Lock()
+	if x.state == StateDone || x.state == StateCancelled || x.state == StateFailed {
+		x.mu.Unlock()
+		return nil
+	}
+	x.state = StateCancelled
+	x.mu.Unlock()
+	return x.CancelLive()
+}
+
+// Progress returns a snapshot of the execution
+func (x *Execution) Progress() Progress {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	p := Progress{
+		State:     x.state,
+		Sz:        x.parent.Sz,
+		FilledSz:  x.filled,
+		Children:  len(x.children),
+		StartTime: x.start,
+		Err:       x.err,
+	}
+	if x.filled > 0 {
+		p.AvgPx = x.notional / x.filled
+	}
+	if x.parent.Sz > 0 {
+		p.FillRate = x.filled / x.parent.Sz
+	}
+	if !x.start.IsZero() {
+		p.Elapsed = time.Since(x.start)
+	}
+	return p
+}
+
+// Filled returns the filled size of the parent
+func (x *Execution) Filled() float64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.filled
+}
+
+// Remaining returns the size left to fill, not counting what the live child may still fill
+func (x *Execution) Remaining() float64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return math.Max(0, x.parent.Sz-x.filled)
+}
+
+// Quote returns the best bid, best ask and last price seen so far
+func (x *Execution) Quote() (bid, ask, last float64) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.bid, x.ask, x.last
+}
+
+// SetQuote sets the best bid, best ask and last price
+func (x *Execution) SetQuote(bid, ask, last float64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.bid, x.ask, x.last = bid, ask, last
+}
+
+// HandleTickers updates the quote from a `tickers` channel push
+func (x *Execution) HandleTickers(e *public.Tickers) {
+	for _, t := range e.Tickers {
+		if t.InstID == x.parent.InstID {
+			x.SetQuote(float64(t.BidPx), float64(t.AskPx), float64(t.Last))
+		}
+	}
+}
+
+// Live returns the live child order, if any
+func (x *Execution) Live() (*oms.Order, bool) {
+	x.mu.RLock()
+	id := x.live
+	x.mu.RUnlock()
+	if id == "" {
+		return nil, false
+	}
+	o, ok := x.oms.Order(id)
+	if !ok || o.IsDone() {
+		return nil, false
+	}
+	return o, true
+}
+
+// Place sends a child order, capped to what neither the fills nor the live child cover. The size is rounded down to the lot size and the price to the tick size on the passive side.
+// Children below the minimum size are skipped.
+func (x *Execution) Place(sz, px float64, ordType okex.OrderType) error {
+	p := x.parent
+	left := x.Remaining()
+	if o, ok := x.Live(); ok {
+		left -= o.RemainingSz()
+	}
+	sz = math.Min(sz, left)
+	if p.LotSz > 0 {
+		sz = math.Floor(sz/p.LotSz+1e-9) * p.LotSz
+	}
+	if sz <= 0 || (p.MinSz > 0 && sz < p.MinSz) {
+		return nil
+	}
+	px = x.roundPx(px)
+	req := requests.PlaceOrderRequest{
+		InstID:  p.InstID,
+		TdMode:  p.TdMode,
+		Side:    p.Side,
+		OrdType: ordType,
+		Sz:      format(sz, p.LotSz),
+		Tag:     p.Tag,
+	}
+	if px > 0 && ordType != okex.OrderMarket {
+		req.Px = format(px, p.TickSz)
+	}
+	req.ClOrdId = x.oms.NewClOrdID()
+	x.mu.Lock()
+	x.children[req.ClOrdId] = true
+	x.live = req.ClOrdId
+	x.mu.Unlock()
+
+	_, err := x.router.Place(req)
+	return err
+}
+
+// CancelLive cancels the live child, if any. The child stays live until the cancellation is confirmed, algorithms
+// wait for it before sending the next child, as it may still fill.
+func (x *Execution) CancelLive() error {
+	o, ok := x.Live()
+	if !ok {
+		return nil
+	}
+	x.mu.Lock()
+	sent := x.cancelling == o.ClOrdID
+	x.cancelling = o.ClOrdID
+	x.mu.Unlock()
+	if sent {
+		return nil
+	}
+	if err := x.router.Cancel(o); err != nil {
+		x.mu.Lock()
+		x.cancelling = ""
+		x.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// AmendLive moves the price of the live child, unless it is being cancelled
+func (x *Execution) AmendLive(px float64) error {
+	o, ok := x.Live()
+	if !ok {
+		return nil
+	}
+	x.mu.RLock()
+	cancelling := x.cancelling == o.ClOrdID
+	x.mu.RUnlock()
+	if cancelling {
+		return nil
+	}
+	return x.router.Amend(o, px)
+}
+
+func (x *Execution) step(now time.Time) (bool, error) {
+	x.mu.RLock()
+	state := x.state
+	done := x.parent.Sz-x.filled <= 1e-12
+	x.mu.RUnlock()
+
+	switch state {
+	case StateCancelled:
+		return true, nil
+	case StateFailed, StateDone:
+		return true, x.err
+	case StatePaused:
+		return false, nil
+	}
+	if done {
+		x.finish(StateDone, nil)
+		return true, nil
+	}
+	if err := x.algo.Step(x, now); err != nil {
+		_ = x.CancelLive()
+		x.finish(StateFailed, err)
+		return true, err
+	}
+	return false, nil
+}
+
+// Finish ends the execution, e.g. when the schedule of the algorithm is over
+func (x *Execution) Finish() {
+	_ = x.CancelLive()
+	x.finish(StateDone, nil)
+}
+
+func (x *Execution) finish(s State, err error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.state == StateRunning || x.state == StatePaused {
+		x.state = s
+		x.err = err
+	}
+}
+
+func (x *Execution) handleFill(o *oms.Order, f *oms.Fill) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.children[o.ClOrdID] {
+		return
+	}
+	x.filled += f.FillSz
+	x.notional += f.FillSz * f.FillPx
+}
+
+// roundPx clamps px to the limit price and rounds it to the tick size on the passive side
+func (x *Execution) roundPx(px float64) float64 {
+	p := x.parent
+	if px <= 0 {
+		return px
+	}
+	if p.LimitPx > 0 {
+		if p.Side == okex.OrderBuy {
+			px = math.Min(px, p.LimitPx)
+		} else {
+			px = math.Max(px, p.LimitPx)
+		}
+	}
+	if p.TickSz > 0 {
+		if p.Side == okex.OrderBuy {
+			px = math.Floor(px/p.TickSz+1e-9) * p.TickSz
+		} else {
+			px = math.Ceil(px/p.TickSz-1e-9) * p.TickSz
+		}
+	}
+	return px
+}
+
+// format prints v with as many decimals as step has, or as few as needed when step is zero
+func format(v, step float64) string {
+	if step <= 0 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	d := 0
+	if s := strconv.FormatFloat(step, 'f', -1, 64); strings.Contains(s, ".") {
+		d = len(s) - strings.Index(s, ".") - 1
+	}
+	return strconv.FormatFloat(v, 'f', d, 64)
+}
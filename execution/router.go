@@ -0,0 +1,105 @@
+package execution
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/oms"
+	requests "github.com/yitech/okex/requests/rest/trade"
+	wsRequests "github.com/yitech/okex/requests/ws/trade"
+)
+
+// Router sends the child orders of an execution
+type Router interface {
+	Place(req requests.PlaceOrderRequest) (*oms.Order, error)
+	Cancel(o *oms.Order) error
+	Amend(o *oms.Order, newPx float64) error
+}
+
+// RestRouter sends the child orders through the rest Trade client
+type RestRouter struct {
+	oms   *oms.Manager
+	trade api.OrderAPI
+}
+
+// NewRestRouter returns a pointer to a fresh RestRouter. The manager must have been created with the same client.
+func NewRestRouter(m *oms.Manager, t api.OrderAPI) *RestRouter {
+	return &RestRouter{oms: m, trade: t}
+}
+
+// Place submits a child order
+func (r *RestRouter) Place(req requests.PlaceOrderRequest) (*oms.Order, error) {
+	os, err := r.oms.PlaceOrder(req)
+	if len(os) == 0 {
+		return nil, err
+	}
+	if err == nil && os[0].State == oms.OrderRejected {
+		err = fmt.Errorf("execution: child order rejected: %s", os[0].RejectMsg)
+	}
+	return os[0], err
+}
+
+// Cancel cancels a child order
+func (r *RestRouter) Cancel(o *oms.Order) error {
+	res, err := r.trade.CancelOrder(requests.CancelOrderRequest{InstID: o.InstID, ClOrdId: o.ClOrdID})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("execution: cancel failed: %d %s", res.Code, res.Msg)
+	}
+	return nil
+}
+
+// Amend moves the price of a child order
+func (r *RestRouter) Amend(o *oms.Order, newPx float64) error {
+	res, err := r.trade.AmendOrder([]requests.AmendOrderRequest{{
+		InstID:  o.InstID,
+		ClOrdId: o.ClOrdID,
+		NewPx:   strconv.FormatFloat(newPx, 'f', -1, 64),
+	}})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("execution: amend failed: %d %s", res.Code, res.Msg)
+	}
+	return nil
+}
+
+// WsRouter sends the child orders through the websocket Trade client. Acknowledgements are asynchronous.
+type WsRouter struct {
+	oms   *oms.Manager
+	trade api.TradeStream
+}
+
+// NewWsRouter returns a pointer to a fresh WsRouter. The manager must have been created with the same client.
+func NewWsRouter(m *oms.Manager, t api.TradeStream) *WsRouter {
+	return &WsRouter{oms: m, trade: t}
+}
+
+// Place submits a child order
+func (r *WsRouter) Place(req requests.PlaceOrderRequest) (*oms.Order, error) {
+	os, err := r.oms.PlaceOrderWs(wsRequests.PlaceOrder{PlaceOrderRequest: req})
+	if len(os) == 0 {
+		return nil, err
+	}
+	return os[0], err
+}
+
+// Cancel cancels a child order
+func (r *WsRouter) Cancel(o *oms.Order) error {
+	return r.trade.CancelOrder(wsRequests.CancelOrder{
+		ID:                 o.ClOrdID,
+		CancelOrderRequest: requests.CancelOrderRequest{InstID: o.InstID, ClOrdId: o.ClOrdID},
+	})
+}
+
+// Amend moves the price of a child order
+func (r *WsRouter) Amend(o *oms.Order, newPx float64) error {
+	return r.trade.AmendOrder(wsRequests.AmendOrder{
+		ID:                o.ClOrdID,
+		AmendOrderRequest: requests.AmendOrderRequest{InstID: o.InstID, ClOrdId: o.ClOrdID, NewPx: strconv.FormatFloat(newPx, 'f', -1, 64)},
+	})
+}
@@ -0,0 +1,352 @@
+// Package fee caches the fee tiers of the account and computes the fees of prospective orders and fills.
+//
+// Rates and fees follow the sign convention of the exchange, negative amounts are charged and positive ones are rebates.
+// The same Model can be shared by the paper exchange, backtests and reports so they all make the same assumptions.
+package fee
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/models/trade"
+	requests "github.com/yitech/okex/requests/rest/account"
+)
+
+// OptionFeeCap is the largest share of the premium charged as an option fee
+const OptionFeeCap = 0.125
+
+type (
+	// Rate is a fee tier. The U and USDC rates apply to USDT and USDC margined contracts, and USDC rates to USDC spot
+	// pairs, the plain rates are used when they are zero.
+	Rate struct {
+		Level     string
+		Maker     float64
+		Taker     float64
+		MakerU    float64
+		TakerU    float64
+		MakerUSDC float64
+		TakerUSDC float64
+	}
+
+	// Order is a prospective order or a fill. The instrument type is only needed for unregistered instruments.
+	Order struct {
+		InstID   string
+		InstType okex.InstrumentType
+		Side     okex.OrderSide
+		Px       float64
+		Sz       float64
+		Maker    bool
+	}
+
+	// Estimate is the expected fee of an order
+	Estimate struct {
+		Rate  float64
+		Fee   float64
+		Ccy   string
+		Maker bool
+	}
+
+	// Discrepancy is a fill whose fee differs from the expected one
+	Discrepancy struct {
+		InstID       string
+		TradeID      string
+		Expected     Estimate
+		ActualFee    float64
+		ActualFeeCcy string
+		TS           time.Time
+	}
+
+	// DiscrepancyHandler is called for every fill whose fee differs from the expected one
+	DiscrepancyHandler func(d *Discrepancy)
+
+	// Total sums the expected and actual fees of the reconciled fills in a currency
+	Total struct {
+		Expected float64
+		Actual   float64
+		Fills    int
+	}
+
+	key struct {
+		instType okex.InstrumentType
+		uly      string
+	}
+
+	// Model holds the fee tiers per instrument type and underlying
+	Model struct {
+		account       api.AccountAPI
+		mu            sync.RWMutex
+		rates         map[key]Rate
+		instruments   map[string]*publicdata.Instrument
+		totals        map[string]*Total
+		onDiscrepancy []DiscrepancyHandler
+		relTol        float64
+		absTol        float64
+	}
+)
+
+// Rebate reports whether the fee is paid to the account
+func (e Estimate) Rebate() bool {
+	return e.Fee > 0
+}
+
+// RateOf converts the fee rates returned by the account endpoint
+func RateOf(f *account.Fee) Rate {
+	return Rate{
+		Level:     f.Level,
+		Maker:     float64(f.Maker),
+		Taker:     float64(f.Taker),
+		MakerU:    float64(f.MakerU),
+		TakerU:    float64(f.TakerU),
+		MakerUSDC: float64(f.MakerUSDC),
+		TakerUSDC: float64(f.TakerUSDC),
+	}
+}
+
+// NewModel returns a pointer to a fresh Model. The account client is only needed by Load.
+func NewModel(a api.AccountAPI) *Model {
+	return &Model{
+		account:     a,
+		rates:       make(map[key]Rate),
+		instruments: make(map[string]*publicdata.Instrument),
+		totals:      make(map[string]*Total),
+		relTol:      1e-6,
+		absTol:      1e-12,
+	}
+}
+
+// SetTolerance sets the relative and absolute fee differences that are not reported as discrepancies
+func (m *Model) SetTolerance(rel, abs float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.relTol, m.absTol = rel, abs
+}
+
+// OnDiscrepancy registers a handler for discrepancies found by Reconcile
+func (m *Model) OnDiscrepancy(h DiscrepancyHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onDiscrepancy = append(m.onDiscrepancy, h)
+}
+
+// SetRate sets the fee tier of an instrument type, an empty underlying sets the default of the type.
+// Margin uses the rates of spot.
+func (m *Model) SetRate(t okex.InstrumentType, uly string, r Rate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates[key{instType: normalize(t), uly: uly}] = r
+}
+
+// Load takes the fee tier of the account for an instrument type and, for derivatives, an optional underlying
+func (m *Model) Load(t okex.InstrumentType, uly string) error {
+	if m.account == nil {
+		return fmt.Errorf("fee: account client is not set")
+	}
+	res, err := m.account.GetFeeRates(requests.GetFeeRatesRequest{InstType: normalize(t), Uly: uly})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("fee: get fee rates failed: %d %s", res.Code, res.Msg)
+	}
+	for _, f := range res.Fees {
+		m.SetRate(t, uly, RateOf(f))
+	}
+	return nil
+}
+
+// SetInstrument registers the contract specification and currencies of an instrument
+func (m *Model) SetInstrument(i *publicdata.Instrument) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instruments[i.InstID] = i
+}
+
+// Rate returns the fee tier of an instrument, falling back to the default of its type
+func (m *Model) Rate(instID string, t okex.InstrumentType) (Rate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	inst := m.instruments[instID]
+	return m.rate(inst, instID, t)
+}
+
+// Estimate returns the expected fee of an order. Spot fees are charged in the received currency, derivative fees in
+// the settlement currency and option fees are capped to a share of the premium.
+func (m *Model) Estimate(o Order) Estimate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.estimate(o)
+}
+
+// Reconcile compares the fee of a fill with the expected one and reports a discrepancy, if any.
+// Fills without an execution type are not reconciled.
+func (m *Model) Reconcile(f *trade.TransactionDetail) *Discrepancy {
+	if f.ExecType != okex.OrderMakerFlow && f.ExecType != okex.OrderTakerFlow {
+		return nil
+	}
+	m.mu.Lock()
+	est := m.estimate(Order{
+		InstID:   f.InstID,
+		InstType: f.InstType,
+		Side:     f.Side,
+		Px:       float64(f.FillPx),
+		Sz:       float64(f.FillSz),
+		Maker:    f.ExecType == okex.OrderMakerFlow,
+	})
+	actual := float64(f.Fee)
+	ccy := f.FeeCcy
+	if ccy == "" {
+		ccy = est.Ccy
+	}
+	t, ok := m.totals[ccy]
+	if !ok {
+		t = &Total{}
+		m.totals[ccy] = t
+	}
+	t.Actual += actual
+	t.Fills++
+	if est.Ccy == ccy || est.Ccy == "" {
+		t.Expected += est.Fee
+	}
+	var d *Discrepancy
+	if (est.Ccy != "" && f.FeeCcy != "" && est.Ccy != f.FeeCcy) ||
+		math.Abs(est.Fee-actual) > m.absTol+m.relTol*math.Abs(est.Fee) {
+		d = &Discrepancy{
+			InstID:       f.InstID,
+			TradeID:      f.TradeID,
+			Expected:     est,
+			ActualFee:    actual,
+			ActualFeeCcy: f.FeeCcy,
+			TS:           time.Time(f.TS),
+		}
+	}
+	hs := m.onDiscrepancy
+	m.mu.Unlock()
+	if d != nil {
+		for _, h := range hs {
+			h(d)
+		}
+	}
+	return d
+}
+
+// Totals returns the expected and actual fees of the reconciled fills per currency
+func (m *Model) Totals() map[string]Total {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := make(map[string]Total, len(m.totals))
+	for ccy, t := range m.totals {
+		res[ccy] = *t
+	}
+	return res
+}
+
+func (m *Model) estimate(o Order) Estimate {
+	inst := m.instruments[o.InstID]
+	r, _ := m.rate(inst, o.InstID, o.InstType)
+	t := instType(inst, o.InstID, o.InstType)
+	maker, taker := r.Maker, r.Taker
+	switch {
+	case t == okex.SpotInstrument && inst != nil && inst.QuoteCcy == "USDC":
+		maker, taker = pick(r.MakerUSDC, maker), pick(r.TakerUSDC, taker)
+	case t != okex.SpotInstrument && inst != nil && inst.CtType == okex.ContractLinearType && inst.SettleCcy == "USDC":
+		maker, taker = pick(r.MakerUSDC, maker), pick(r.TakerUSDC, taker)
+	case t != okex.SpotInstrument && inst != nil && inst.CtType == okex.ContractLinearType:
+		maker, taker = pick(r.MakerU, maker), pick(r.TakerU, taker)
+	}
+	e := Estimate{Rate: taker, Maker: o.Maker}
+	if o.Maker {
+		e.Rate = maker
+	}
+
+	switch t {
+	case okex.SpotInstrument:
+		if inst == nil {
+			e.Fee = e.Rate * o.Sz * o.Px
+		} else if o.Side == okex.OrderBuy {
+			e.Fee, e.Ccy = e.Rate*o.Sz, inst.BaseCcy
+		} else {
+			e.Fee, e.Ccy = e.Rate*o.Sz*o.Px, inst.QuoteCcy
+		}
+		return e
+	}
+	mult, inverse, ccy := 1.0, false, ""
+	if inst != nil {
+		mult = float64(inst.CtVal)
+		if mult == 0 {
+			mult = 1
+		}
+		if inst.CtMult > 0 {
+			mult *= float64(inst.CtMult)
+		}
+		inverse, ccy = inst.CtType == okex.ContractInverseType, inst.SettleCcy
+	}
+	e.Ccy = ccy
+	switch {
+	case t == okex.OptionsInstrument:
+		// Charged on the underlying, the premium is already quoted in the settlement currency
+		e.Fee = e.Rate * o.Sz * mult
+		if max := OptionFeeCap * o.Px * o.Sz * mult; math.Abs(e.Fee) > max {
+			e.Fee = math.Copysign(max, e.Fee)
+		}
+	case inverse:
+		if o.Px > 0 {
+			e.Fee = e.Rate * o.Sz * mult / o.Px
+		}
+	default:
+		e.Fee = e.Rate * o.Sz * mult * o.Px
+	}
+	return e
+}
+
+func (m *Model) rate(inst *publicdata.Instrument, instID string, t okex.InstrumentType) (Rate, bool) {
+	t = instType(inst, instID, t)
+	if inst != nil && inst.Uly != "" {
+		if r, ok := m.rates[key{instType: t, uly: inst.Uly}]; ok {
+			return r, true
+		}
+	}
+	r, ok := m.rates[key{instType: t}]
+	return r, ok
+}
+
+// instType is the type of a registered instrument, or the given one, or the type guessed from the id
+func instType(inst *publicdata.Instrument, instID string, t okex.InstrumentType) okex.InstrumentType {
+	if inst != nil && inst.InstType != "" {
+		t = inst.InstType
+	}
+	if t == "" {
+		p := strings.Split(instID, "-")
+		switch {
+		case len(p) == 2:
+			t = okex.SpotInstrument
+		case p[len(p)-1] == "SWAP":
+			t = okex.SwapInstrument
+		case len(p) == 5:
+			t = okex.OptionsInstrument
+		default:
+			t = okex.FuturesInstrument
+		}
+	}
+	return normalize(t)
+}
+
+func normalize(t okex.InstrumentType) okex.InstrumentType {
+	if t == okex.MarginInstrument {
+		return okex.SpotInstrument
+	}
+	return t
+}
+
+func pick(v, fallback float64) float64 {
+	if v == 0 {
+		return fallback
+	}
+	return v
+}
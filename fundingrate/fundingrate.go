@@ -0,0 +1,535 @@
+// Package fundingrate tracks the funding rates of perpetual swaps, historical and live, and matches the funding fees
+// paid or received by the account with the settled rates and the positions held, for the realized funding PnL.
+//
+// Payments follow the sign convention of the bills, positive amounts are received and negative ones paid.
+package fundingrate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/position"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	publicRequests "github.com/yitech/okex/requests/rest/public"
+)
+
+const (
+	// DefaultInterval is the funding interval assumed until the live rate tells otherwise
+	DefaultInterval = 8 * time.Hour
+	// Year is the period rates are annualized over
+	Year = 365 * 24 * time.Hour
+
+	// RecentBills is how far back the account bills endpoint reaches, older bills are taken from the archive
+	RecentBills = 7 * 24 * time.Hour
+
+	pageLimit = 100
+	// matchWindow is the largest distance between a funding fee bill and the settlement it is matched with
+	matchWindow = 5 * time.Minute
+)
+
+type (
+	// Rate is a settled funding rate
+	Rate struct {
+		InstID       string
+		Rate         float64
+		RealizedRate float64
+		Time         time.Time
+	}
+
+	// Payment is a funding fee of the account, matched with the settled rate and the position it was charged on
+	Payment struct {
+		InstID  string
+		BillID  string
+		Ccy     string
+		MgnMode okex.MarginMode
+		PosSide okex.PositionSide
+		Amount  float64
+		// Pos is the signed position size, zero when it is not known
+		Pos float64
+		// Px is the price the position is valued at, zero when it is not known
+		Px float64
+		// Rate is the settled rate, zero when it is not known
+		Rate float64
+		// Expected is the payment implied by the rate and the position value, zero when either is not known
+		Expected float64
+		TS       time.Time
+		// sz is the unsigned size of the bill, its direction is known once the rate is
+		sz float64
+		// live are the positions held when a bill without size was booked
+		live []*position.Position
+	}
+
+	// PaymentHandler is called for every new funding payment
+	PaymentHandler func(p *Payment)
+
+	// Summary sums the funding of an instrument over a period
+	Summary struct {
+		InstID   string
+		Ccy      string
+		Begin    time.Time
+		End      time.Time
+		Payments int
+		// Realized is the sum of the payments, Expected the sum of their expected amounts
+		Realized float64
+		Expected float64
+		// AvgRate is the mean settled rate of the period and Annualized its yearly equivalent
+		AvgRate    float64
+		Annualized float64
+	}
+
+	// PositionSource provides the current positions, e.g. a position.Tracker
+	PositionSource interface {
+		Positions() []*position.Position
+	}
+
+	// Tracker keeps the funding rates and payments per instrument
+	Tracker struct {
+		public      api.PublicDataAPI
+		account     api.AccountAPI
+		positions   PositionSource
+		mu          sync.RWMutex
+		instruments map[string]*publicdata.Instrument
+		history     map[string][]*Rate
+		current     map[string]*publicdata.FundingRate
+		payments    map[string][]*Payment
+		seen        map[string]bool
+		onPayment   []PaymentHandler
+	}
+)
+
+// Annualize converts a rate charged every interval to its yearly equivalent, without compounding
+func Annualize(rate float64, interval time.Duration) float64 {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return rate * float64(Year) / float64(interval)
+}
+
+// NewTracker returns a pointer to a fresh Tracker. The public data client is only needed by LoadHistory and the
+// account client by LoadPayments.
+func NewTracker(p api.PublicDataAPI, a api.AccountAPI) *Tracker {
+	return &Tracker{
+		public:      p,
+		account:     a,
+		instruments: make(map[string]*publicdata.Instrument),
+		history:     make(map[string][]*Rate),
+		current:     make(map[string]*publicdata.FundingRate),
+		payments:    make(map[string][]*Payment),
+		seen:        make(map[string]bool),
+	}
+}
+
+// SetPositionSource sets where the positions payments are matched with are taken from
+func (t *Tracker) SetPositionSource(ps PositionSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.positions = ps
+}
+
+// SetInstrument registers the contract specification of a swap
+func (t *Tracker) SetInstrument(i *publicdata.Instrument) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.instruments[i.InstID] = i
+}
+
+// OnPayment registers a handler for new funding payments
+func (t *Tracker) OnPayment(h PaymentHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPayment = append(t.onPayment, h)
+}
+
+// LoadHistory takes the settled rates of a swap between begin and end from the funding-rate-history endpoint
+func (t *Tracker) LoadHistory(instID string, begin, end time.Time) error {
+	if t.public == nil {
+		return fmt.Errorf("fundingrate: public data client is not set")
+	}
+	req := publicRequests.GetFundingRateHistory{InstID: instID, Limit: pageLimit}
+	if !end.IsZero() {
+		req.After = end.UnixMilli() + 1
+	}
+	for {
+		res, err := t.public.GetFundingRateHistory(req)
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("fundingrate: get funding rate history failed: %d %s", res.Code, res.Msg)
+		}
+		rs := make([]*Rate, 0, len(res.FundingRates))
+		for _, r := range res.FundingRates {
+			if ts := time.Time(r.FundingTime); !ts.Before(begin) {
+				rs = append(rs, &Rate{InstID: r.InstID, Rate: float64(r.FundingRate), RealizedRate: float64(r.RealizedRate), Time: ts})
+			}
+		}
+		t.addRates(rs...)
+		if len(res.FundingRates) < pageLimit {
+			return nil
+		}
+		last := time.Time(res.FundingRates[len(res.FundingRates)-1].FundingTime)
+		if last.Before(begin) {
+			return nil
+		}
+		req.After = last.UnixMilli()
+	}
+}
+
+// HandleFundingRate keeps the live rates of a `funding-rate` channel push. A push after the funding time settles
+// the previous rate into the history.
+func (t *Tracker) HandleFundingRate(e *public.FundingRate) {
+	var settled []*Rate
+	t.mu.Lock()
+	for _, r := range e.Rates {
+		if prev, ok := t.current[r.InstID]; ok && time.Time(r.FundingTime).After(time.Time(prev.FundingTime)) {
+			settled = append(settled, &Rate{InstID: r.InstID, Rate: float64(prev.FundingRate), Time: time.Time(prev.FundingTime)})
+		}
+		t.current[r.InstID] = r
+	}
+	t.mu.Unlock()
+	t.addRates(settled...)
+}
+
+// Current returns the live rate of a swap
+func (t *Tracker) Current(instID string) (*publicdata.FundingRate, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.current[instID]
+	if !ok {
+		return nil, false
+	}
+	c := *r
+	return &c, true
+}
+
+// Interval returns the funding interval of a swap, from its live rate
+func (t *Tracker) Interval(instID string) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.interval(instID)
+}
+
+// Annualized returns the current and next rates of a swap as yearly rates
+func (t *Tracker) Annualized(instID string) (current, next float64, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.current[instID]
+	if !ok {
+		return 0, 0, false
+	}
+	i := t.interval(instID)
+	return Annualize(float64(r.FundingRate), i), Annualize(float64(r.NextFundingRate), i), true
+}
+
+// History returns the settled rates of a swap between begin and end, oldest first. A zero end is open.
+func (t *Tracker) History(instID string, begin, end time.Time) []*Rate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var res []*Rate
+	for _, r := range t.history[instID] {
+		if within(r.Time, begin, end) {
+			c := *r
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// HandleBill matches a funding fee bill with the settled rate and the current position. Other bills are ignored.
+func (t *Tracker) HandleBill(b *account.Bill) {
+	if b.Type != okex.BillFundingFeeType {
+		return
+	}
+	t.mu.Lock()
+	p := t.match(b)
+	hs := t.onPayment
+	t.mu.Unlock()
+	if p == nil {
+		return
+	}
+	for _, h := range hs {
+		h(p)
+	}
+}
+
+// LoadPayments takes the funding fee bills between begin and end, from the archive when begin is older than the
+// recent bills
+func (t *Tracker) LoadPayments(begin, end time.Time) error {
+	if t.account == nil {
+		return fmt.Errorf("fundingrate: account client is not set")
+	}
+	arch := time.Since(begin) > RecentBills
+	req := accountRequests.GetBillsRequest{
+		InstType: okex.SwapInstrument,
+		Type:     okex.BillFundingFeeType,
+		Begin:    begin.UnixMilli(),
+		End:      end.UnixMilli(),
+		Limit:    pageLimit,
+	}
+	var bills []*account.Bill
+	for {
+		res, err := t.account.GetBills(req, arch)
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("fundingrate: get bills failed: %d %s", res.Code, res.Msg)
+		}
+		bills = append(bills, res.Bills...)
+		if len(res.Bills) < pageLimit {
+			break
+		}
+		last := res.Bills[len(res.Bills)-1]
+		after, err := strconv.ParseInt(last.BillID, 10, 64)
+		if err != nil || time.Time(last.TS).Before(begin) {
+			break
+		}
+		req.After = after
+	}
+	// Oldest first, so handlers see the payments in the order they happened
+	for i := len(bills) - 1; i >= 0; i-- {
+		if within(time.Time(bills[i].TS), begin, end) {
+			t.HandleBill(bills[i])
+		}
+	}
+	return nil
+}
+
+// Payments returns the funding payments of a swap between begin and end, oldest first. A zero end is open.
+func (t *Tracker) Payments(instID string, begin, end time.Time) []*Payment {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var res []*Payment
+	for _, p := range t.payments[instID] {
+		if within(p.TS, begin, end) {
+			c := *p
+			res = append(res, &c)
+		}
+	}
+	return res
+}
+
+// Summary sums the payments and averages the settled rates of a swap between begin and end
+func (t *Tracker) Summary(instID string, begin, end time.Time) *Summary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	s := &Summary{InstID: instID, Begin: begin, End: end}
+	for _, p := range t.payments[instID] {
+		if !within(p.TS, begin, end) {
+			continue
+		}
+		s.Payments++
+		s.Realized += p.Amount
+		s.Expected += p.Expected
+		s.Ccy = p.Ccy
+	}
+	var n int
+	for _, r := range t.history[instID] {
+		if within(r.Time, begin, end) {
+			s.AvgRate += r.Rate
+			n++
+		}
+	}
+	if n > 0 {
+		s.AvgRate /= float64(n)
+		s.Annualized = Annualize(s.AvgRate, t.interval(instID))
+	}
+	return s
+}
+
+// Summaries returns the summary of every swap with payments or rates between begin and end
+func (t *Tracker) Summaries(begin, end time.Time) []*Summary {
+	t.mu.RLock()
+	ids := make(map[string]bool)
+	for id := range t.payments {
+		ids[id] = true
+	}
+	for id := range t.history {
+		ids[id] = true
+	}
+	t.mu.RUnlock()
+	res := make([]*Summary, 0, len(ids))
+	for id := range ids {
+		if s := t.Summary(id, begin, end); s.Payments > 0 || s.AvgRate != 0 {
+			res = append(res, s)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].InstID < res[j].InstID
+	})
+	return res
+}
+
+func (t *Tracker) addRates(rs ...*Rate) {
+	if len(rs) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	touched := make(map[string]bool)
+	for _, r := range rs {
+		h := t.history[r.InstID]
+		i := sort.Search(len(h), func(i int) bool {
+			return !h[i].Time.Before(r.Time)
+		})
+		if i < len(h) && h[i].Time.Equal(r.Time) {
+			// The history endpoint knows the realized rate, the live channel does not
+			if r.RealizedRate != 0 || h[i].RealizedRate == 0 {
+				h[i] = r
+			}
+			continue
+		}
+		h = append(h, nil)
+		copy(h[i+1:], h[i:])
+		h[i] = r
+		t.history[r.InstID] = h
+		touched[r.InstID] = true
+	}
+	// Payments booked before their rate was known are matched again
+	for id := range touched {
+		for _, p := range t.payments[id] {
+			if p.Rate == 0 {
+				t.price(p)
+			}
+		}
+	}
+}
+
+// match books a funding fee bill, it is called with the lock held
+func (t *Tracker) match(b *account.Bill) *Payment {
+	if t.seen[b.BillID] {
+		return nil
+	}
+	t.seen[b.BillID] = true
+	p := &Payment{
+		InstID:  b.InstID,
+		BillID:  b.BillID,
+		Ccy:     b.Ccy,
+		MgnMode: b.MgnMode,
+		Amount:  float64(b.BalChg),
+		Px:      float64(b.Px),
+		TS:      time.Time(b.TS),
+		sz:      math.Abs(float64(b.Sz)),
+	}
+	// Historical bills are charged on the position held at the settlement, the live one is only a fallback
+	if p.sz == 0 && t.positions != nil {
+		for _, pp := range t.positions.Positions() {
+			if pp.InstID == b.InstID && pp.MgnMode == b.MgnMode && pp.Pos != 0 {
+				p.live = append(p.live, pp)
+			}
+		}
+	}
+	t.price(p)
+
+	ps := t.payments[p.InstID]
+	i := sort.Search(len(ps), func(i int) bool {
+		return ps[i].TS.After(p.TS)
+	})
+	ps = append(ps, nil)
+	copy(ps[i+1:], ps[i:])
+	ps[i] = p
+	t.payments[p.InstID] = ps
+	c := *p
+	return &c
+}
+
+// price sets the settled rate and the expected amount of a payment
+func (t *Tracker) price(p *Payment) {
+	var best *Rate
+	for _, r := range t.history[p.InstID] {
+		if d := r.Time.Sub(p.TS); d > -matchWindow && d < matchWindow {
+			if best == nil || math.Abs(float64(d)) < math.Abs(float64(best.Time.Sub(p.TS))) {
+				best = r
+			}
+		}
+	}
+	if best == nil {
+		return
+	}
+	p.Rate = best.Rate
+	if best.RealizedRate != 0 {
+		p.Rate = best.RealizedRate
+	}
+	if p.Pos == 0 && p.Rate != 0 && p.Amount != 0 {
+		// Bills carry the size without its direction, longs pay positive rates
+		dir := 1.0
+		if p.Amount*p.Rate > 0 {
+			dir = -1
+		}
+		if pp := held(p.live, p.InstID, p.MgnMode, dir); pp != nil {
+			p.Pos, p.PosSide = pp.Pos, pp.PosSide
+			if p.Px == 0 {
+				p.Px = pp.MarkPx
+			}
+		} else if p.sz != 0 {
+			p.Pos = dir * p.sz
+		}
+		if p.PosSide == "" && t.positions != nil {
+			if pp := held(t.positions.Positions(), p.InstID, p.MgnMode, dir); pp != nil {
+				p.PosSide = pp.PosSide
+			}
+		}
+	}
+	if p.Px == 0 && t.positions != nil {
+		for _, pp := range t.positions.Positions() {
+			if pp.InstID == p.InstID && pp.MarkPx > 0 {
+				p.Px = pp.MarkPx
+				break
+			}
+		}
+	}
+	if v := t.value(p.InstID, p.Pos, p.Px); v != 0 {
+		p.Expected = -v * p.Rate
+	}
+}
+
+// value is the signed value of a position in the settlement currency at px
+func (t *Tracker) value(instID string, pos, px float64) float64 {
+	i, ok := t.instruments[instID]
+	if !ok || pos == 0 || px == 0 {
+		return 0
+	}
+	m := float64(i.CtVal)
+	if m == 0 {
+		m = 1
+	}
+	if i.CtMult > 0 {
+		m *= float64(i.CtMult)
+	}
+	if i.CtType == okex.ContractInverseType {
+		return pos * m / px
+	}
+	return pos * m * px
+}
+
+// held returns the position of an instrument and margin mode held in the direction of dir, nil when there is none
+func held(ps []*position.Position, instID string, mgnMode okex.MarginMode, dir float64) *position.Position {
+	for _, p := range ps {
+		if p.InstID == instID && p.MgnMode == mgnMode && p.Pos*dir > 0 {
+			return p
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) interval(instID string) time.Duration {
+	if r, ok := t.current[instID]; ok {
+		if d := time.Time(r.NextFundingTime).Sub(time.Time(r.FundingTime)); d > 0 {
+			return d
+		}
+	}
+	return DefaultInterval
+}
+
+func within(ts, begin, end time.Time) bool {
+	return !ts.Before(begin) && (end.IsZero() || !ts.After(end))
+}
@@ -0,0 +1,317 @@
+// Package killswitch cancels every open order and algo order and optionally flattens every position,
+// across the master account and any configured sub-accounts.
+package killswitch
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/trade"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	requests "github.com/yitech/okex/requests/rest/trade"
+	responses "github.com/yitech/okex/responses/trade"
+)
+
+const (
+	// OrderBatchSize is the largest number of orders cancel-batch-orders accepts
+	OrderBatchSize = 20
+	// AlgoBatchSize is the largest number of algo orders cancel-algos accepts
+	AlgoBatchSize = 10
+)
+
+// AlgoOrderTypes are the algo order types enumerated for cancellation
+var AlgoOrderTypes = []okex.AlgoOrderType{
+	okex.AlgoOrderConditional,
+	okex.AlgoOrderOCO,
+	okex.AlgoOrderTrigger,
+	okex.AlgoOrderIceberg,
+	okex.AlgoOrderTwap,
+	okex.AlgoOrderMoveStop,
+}
+
+type (
+	// Target is an account the kill switch operates on
+	Target struct {
+		Name    string
+		Trade   api.TradeAPI
+		Account api.AccountAPI
+	}
+
+	// Options of a kill switch run
+	Options struct {
+		// ClosePositions closes every position with a market order after the cancellations
+		ClosePositions bool
+		// DryRun only enumerates what would be cancelled and closed
+		DryRun bool
+	}
+
+	// Item is a single order, algo order or position the kill switch acted on
+	Item struct {
+		ID      string
+		InstID  string
+		PosSide okex.PositionSide
+		MgnMode okex.MarginMode
+		Code    int64
+		Msg     string
+	}
+
+	// AccountReport is what happened on a single target
+	AccountReport struct {
+		Name             string
+		Cancelled        []*Item
+		NotCancelled     []*Item
+		AlgoCancelled    []*Item
+		AlgoNotCancelled []*Item
+		Closed           []*Item
+		NotClosed        []*Item
+		// Remaining are the orders still listed as open after the cancellations, AlgoRemaining the algo orders
+		Remaining     []*Item
+		AlgoRemaining []*Item
+		Errors        []string
+	}
+
+	// Report is the outcome of a kill switch run
+	Report struct {
+		DryRun   bool
+		Accounts []*AccountReport
+	}
+)
+
+// OK reports whether everything that was found was cancelled or closed
+func (r *AccountReport) OK() bool {
+	return len(r.NotCancelled) == 0 && len(r.AlgoNotCancelled) == 0 && len(r.NotClosed) == 0 && len(r.Remaining) == 0 &&
+		len(r.AlgoRemaining) == 0 && len(r.Errors) == 0
+}
+
+// OK reports whether every account was cleaned up
+func (r *Report) OK() bool {
+	for _, a := range r.Accounts {
+		if !a.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Run executes the kill switch on every target. A failing target does not prevent the next ones from running.
+func Run(opt Options, targets ...Target) *Report {
+	r := &Report{DryRun: opt.DryRun}
+	for _, t := range targets {
+		r.Accounts = append(r.Accounts, run(opt, t))
+	}
+	return r
+}
+
+func run(opt Options, t Target) *AccountReport {
+	r := &AccountReport{Name: t.Name}
+
+	orders, err := openOrders(t.Trade)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("list orders: %v", err))
+	}
+	algos, err := openAlgoOrders(t.Trade)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("list algo orders: %v", err))
+	}
+
+	if opt.DryRun {
+		for _, o := range orders {
+			r.NotCancelled = append(r.NotCancelled, &Item{ID: o.OrdID, InstID: o.InstID, Msg: "dry run"})
+		}
+		for _, o := range algos {
+			r.AlgoNotCancelled = append(r.AlgoNotCancelled, &Item{ID: o.AlgoID, InstID: o.InstID, Msg: "dry run"})
+		}
+	} else {
+		cancelOrders(t.Trade, orders, r)
+		var regular, advance []*trade.AlgoOrder
+		for _, o := range algos {
+			if advanced(o.OrdType) {
+				advance = append(advance, o)
+			} else {
+				regular = append(regular, o)
+			}
+		}
+		cancelAlgoOrders(t.Trade.CancelAlgoOrders, AlgoBatchSize, regular, r)
+		// iceberg and twap orders are only cancelled through cancel-advance-algos, one at a time
+		cancelAlgoOrders(func(req []requests.CancelAlgoOrderRequest) (responses.CancelAlgoOrderResponse, error) {
+			return t.Trade.CancelAdvanceAlgoOrder(req[0])
+		}, 1, advance, r)
+	}
+
+	if opt.ClosePositions {
+		closePositions(t.Trade, t.Account, opt.DryRun, r)
+	}
+
+	if !opt.DryRun {
+		remaining, err := openOrders(t.Trade)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("verify orders: %v", err))
+		}
+		for _, o := range remaining {
+			r.Remaining = append(r.Remaining, &Item{ID: o.OrdID, InstID: o.InstID})
+		}
+		algoRemaining, err := openAlgoOrders(t.Trade)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("verify algo orders: %v", err))
+		}
+		for _, o := range algoRemaining {
+			r.AlgoRemaining = append(r.AlgoRemaining, &Item{ID: o.AlgoID, InstID: o.InstID})
+		}
+	}
+	return r
+}
+
+func openOrders(c api.TradeAPI) ([]*trade.Order, error) {
+	var res []*trade.Order
+	req := requests.OrderListRequest{Limit: 100}
+	for {
+		out, err := c.GetOrderList(req)
+		if err != nil {
+			return res, err
+		}
+		if out.Code != 0 {
+			return res, fmt.Errorf("%d %s", out.Code, out.Msg)
+		}
+		res = append(res, out.Orders...)
+		if len(out.Orders) < int(req.Limit) {
+			return res, nil
+		}
+		after, err := strconv.ParseInt(out.Orders[len(out.Orders)-1].OrdID, 10, 64)
+		if err != nil {
+			return res, err
+		}
+		req.After = after
+	}
+}
+
+func openAlgoOrders(c api.TradeAPI) ([]*trade.AlgoOrder, error) {
+	var res []*trade.AlgoOrder
+	for _, t := range AlgoOrderTypes {
+		req := requests.AlgoOrderListRequest{OrdType: t, Limit: 100}
+		for {
+			out, err := c.GetAlgoOrderList(req, false)
+			if err != nil {
+				return res, err
+			}
+			if out.Code != 0 {
+				return res, fmt.Errorf("%s: %d %s", t, out.Code, out.Msg)
+			}
+			res = append(res, out.Orders...)
+			if len(out.Orders) < int(req.Limit) {
+				break
+			}
+			after, err := strconv.ParseInt(out.Orders[len(out.Orders)-1].AlgoID, 10, 64)
+			if err != nil {
+				return res, err
+			}
+			req.After = after
+		}
+	}
+	return res, nil
+}
+
+func cancelOrders(c api.TradeAPI, orders []*trade.Order, r *AccountReport) {
+	for i := 0; i < len(orders); i += OrderBatchSize {
+		batch := orders[i:min(i+OrderBatchSize, len(orders))]
+		req := make([]requests.CancelOrderRequest, len(batch))
+		for j, o := range batch {
+			req[j] = requests.CancelOrderRequest{InstID: o.InstID, OrdId: o.OrdID}
+		}
+		res, err := c.CancelBatchOrders(req)
+		acked := make(map[string]*trade.CancelOrder)
+		for _, o := range res.Orders {
+			acked[o.OrdID] = o
+		}
+		for _, o := range batch {
+			it := &Item{ID: o.OrdID, InstID: o.InstID}
+			a, ok := acked[o.OrdID]
+			switch {
+			case ok && a.SCode == 0:
+				r.Cancelled = append(r.Cancelled, it)
+				continue
+			case ok:
+				it.Code, it.Msg = int64(a.SCode), a.SMsg
+			case err != nil:
+				it.Msg = err.Error()
+			default:
+				it.Code, it.Msg = int64(res.Code), res.Msg
+			}
+			r.NotCancelled = append(r.NotCancelled, it)
+		}
+	}
+}
+
+func cancelAlgoOrders(cancel func([]requests.CancelAlgoOrderRequest) (responses.CancelAlgoOrderResponse, error), size int, orders []*trade.AlgoOrder, r *AccountReport) {
+	for i := 0; i < len(orders); i += size {
+		batch := orders[i:min(i+size, len(orders))]
+		req := make([]requests.CancelAlgoOrderRequest, len(batch))
+		for j, o := range batch {
+			req[j] = requests.CancelAlgoOrderRequest{InstID: o.InstID, AlgoId: o.AlgoID}
+		}
+		res, err := cancel(req)
+		acked := make(map[string]*trade.CancelAlgoOrder)
+		for _, o := range res.Orders {
+			acked[o.AlgoID] = o
+		}
+		for _, o := range batch {
+			it := &Item{ID: o.AlgoID, InstID: o.InstID}
+			a, ok := acked[o.AlgoID]
+			switch {
+			case ok && a.SCode == 0:
+				r.AlgoCancelled = append(r.AlgoCancelled, it)
+				continue
+			case ok:
+				it.Code, it.Msg = int64(a.SCode), a.SMsg
+			case err != nil:
+				it.Msg = err.Error()
+			default:
+				it.Code, it.Msg = int64(res.Code), res.Msg
+			}
+			r.AlgoNotCancelled = append(r.AlgoNotCancelled, it)
+		}
+	}
+}
+
+func advanced(t okex.AlgoOrderType) bool {
+	return t == okex.AlgoOrderIceberg || t == okex.AlgoOrderTwap
+}
+
+func closePositions(c api.TradeAPI, a api.AccountAPI, dryRun bool, r *AccountReport) {
+	res, err := a.GetPositions(accountRequests.GetPositionsRequest{})
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("list positions: %v", err))
+		return
+	}
+	if res.Code != 0 {
+		r.Errors = append(r.Errors, fmt.Sprintf("list positions: %d %s", res.Code, res.Msg))
+		return
+	}
+	for _, p := range res.Positions {
+		if p.Pos == 0 {
+			continue
+		}
+		it := &Item{ID: p.PosID, InstID: p.InstID, PosSide: p.PosSide, MgnMode: p.MgnMode}
+		if dryRun {
+			it.Msg = "dry run"
+			r.NotClosed = append(r.NotClosed, it)
+			continue
+		}
+		out, err := c.ClosePosition(requests.ClosePositionRequest{
+			InstID:  p.InstID,
+			MgnMode: okex.TradeMode(p.MgnMode),
+			PosSide: p.PosSide,
+		})
+		switch {
+		case err != nil:
+			it.Msg = err.Error()
+		case out.Code != 0:
+			it.Code, it.Msg = int64(out.Code), out.Msg
+		default:
+			r.Closed = append(r.Closed, it)
+			continue
+		}
+		r.NotClosed = append(r.NotClosed, it)
+	}
+}
@@ -0,0 +1,241 @@
+package ledger
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/models/publicdata"
+)
+
+// Method is a cost basis method
+type Method string
+
+const (
+	MethodFIFO    = Method("fifo")
+	MethodLIFO    = Method("lifo")
+	MethodAverage = Method("average")
+)
+
+type (
+	// Realized sums the realized PnL, fees, funding and interest of an instrument in a currency, per position side in
+	// long/short mode. Interest is booked without an instrument, funding without a position side.
+	Realized struct {
+		InstID   string            `json:"instId"`
+		PosSide  okex.PositionSide `json:"posSide,omitempty"`
+		Ccy      string            `json:"ccy"`
+		Method   Method            `json:"method"`
+		Pnl      float64           `json:"pnl"`
+		Fee      float64           `json:"fee"`
+		Funding  float64           `json:"funding"`
+		Interest float64           `json:"interest"`
+		// ExchangePnl is the PnL reported by the bills, for comparison
+		ExchangePnl float64 `json:"exchangePnl"`
+		// OpenPos and OpenCost are the signed size and average price of the lots still open
+		OpenPos  float64 `json:"openPos"`
+		OpenCost float64 `json:"openCost"`
+	}
+
+	lot struct {
+		qty float64
+		px  float64
+	}
+
+	realizedKey struct {
+		instID  string
+		posSide okex.PositionSide
+		ccy     string
+	}
+
+	// lotKey keeps the lots of the long and short positions of an instrument apart in long/short mode
+	lotKey struct {
+		instID  string
+		posSide okex.PositionSide
+	}
+
+	// Book computes the realized PnL of trade entries under a cost basis method
+	Book struct {
+		method      Method
+		mu          sync.RWMutex
+		instruments map[string]*publicdata.Instrument
+	}
+)
+
+// Net is the realized PnL plus fees, funding and interest
+func (r *Realized) Net() float64 {
+	return r.Pnl + r.Fee + r.Funding + r.Interest
+}
+
+// NewBook returns a pointer to a fresh Book
+func NewBook(m Method) *Book {
+	return &Book{method: m, instruments: make(map[string]*publicdata.Instrument)}
+}
+
+// SetInstrument registers the contract specification and settlement currency of an instrument. Instruments without
+// one are treated as spot pairs, or linear contracts of value 1.
+func (b *Book) SetInstrument(i *publicdata.Instrument) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.instruments[i.InstID] = i
+}
+
+// Realize replays the entries in time order and returns the realized results per instrument and currency. Spot
+// trades are booked once per trade although their bills come in both currencies.
+func (b *Book) Realize(entries []*Entry) []*Realized {
+	es := make([]*Entry, len(entries))
+	copy(es, entries)
+	sort.SliceStable(es, func(i, j int) bool {
+		return es[i].TS.Before(es[j].TS)
+	})
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	res := make(map[realizedKey]*Realized)
+	get := func(instID string, posSide okex.PositionSide, ccy string) *Realized {
+		k := realizedKey{instID: instID, posSide: posSide, ccy: ccy}
+		r, ok := res[k]
+		if !ok {
+			r = &Realized{InstID: instID, PosSide: posSide, Ccy: ccy, Method: b.method}
+			res[k] = r
+		}
+		return r
+	}
+	lots := make(map[lotKey][]lot)
+	lotCcy := make(map[lotKey]string)
+	seen := make(map[string]bool)
+	for _, e := range es {
+		switch e.Category {
+		case CategoryFunding:
+			get(e.InstID, "", e.Ccy).Funding += e.Amount
+			continue
+		case CategoryInterest:
+			get("", "", e.Ccy).Interest += e.Amount
+			continue
+		case CategoryTrade, CategoryLiquidation, CategoryADL, CategoryDelivery:
+		default:
+			continue
+		}
+		get(e.InstID, e.PosSide, e.Ccy).Fee += e.Fee
+		get(e.InstID, e.PosSide, e.Ccy).ExchangePnl += e.Pnl
+		if e.Side == "" || e.Sz == 0 || e.Px == 0 {
+			continue
+		}
+		if id := e.InstID + "/" + e.TradeID; e.TradeID != "" {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		inst := b.instruments[e.InstID]
+		q := e.Sz
+		if e.Side == okex.OrderSell {
+			q = -q
+		}
+		// in long/short mode, buys open longs and close shorts, they only match the lots of their own side
+		k := lotKey{instID: e.InstID, posSide: e.PosSide}
+		var pnl float64
+		lots[k], pnl = b.fill(lots[k], q, e.Px, inst)
+		lotCcy[k] = settleCcy(inst, e)
+		get(e.InstID, e.PosSide, lotCcy[k]).Pnl += pnl
+	}
+
+	for k, ls := range lots {
+		var q, cost float64
+		for _, l := range ls {
+			q += l.qty
+			cost += l.qty * l.px
+		}
+		if q == 0 {
+			continue
+		}
+		r := get(k.instID, k.posSide, lotCcy[k])
+		r.OpenPos, r.OpenCost = q, cost/q
+	}
+
+	out := make([]*Realized, 0, len(res))
+	for _, r := range res {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].InstID != out[j].InstID {
+			return out[i].InstID < out[j].InstID
+		}
+		if out[i].PosSide != out[j].PosSide {
+			return out[i].PosSide < out[j].PosSide
+		}
+		return out[i].Ccy < out[j].Ccy
+	})
+	return out
+}
+
+// fill matches a signed quantity against the open lots and returns the remaining lots and the realized PnL
+func (b *Book) fill(ls []lot, q, px float64, inst *publicdata.Instrument) ([]lot, float64) {
+	var pnl float64
+	for q != 0 && len(ls) > 0 && sign(ls[0].qty) != sign(q) {
+		i := 0
+		if b.method == MethodLIFO {
+			i = len(ls) - 1
+		}
+		l := &ls[i]
+		closed := math.Min(math.Abs(q), math.Abs(l.qty)) * sign(l.qty)
+		pnl += realized(inst, closed, l.px, px)
+		l.qty -= closed
+		q += closed
+		if math.Abs(l.qty) < 1e-12 {
+			ls = append(ls[:i], ls[i+1:]...)
+		}
+	}
+	if math.Abs(q) < 1e-12 {
+		return ls, pnl
+	}
+	if b.method == MethodAverage && len(ls) > 0 {
+		total := ls[0].qty + q
+		ls[0] = lot{qty: total, px: (ls[0].qty*ls[0].px + q*px) / total}
+		return ls, pnl
+	}
+	return append(ls, lot{qty: q, px: px}), pnl
+}
+
+// realized is the PnL of closing a signed quantity opened at open at the price px
+func realized(inst *publicdata.Instrument, q, open, px float64) float64 {
+	if inst == nil || inst.InstType == okex.SpotInstrument || inst.InstType == okex.MarginInstrument {
+		return q * (px - open)
+	}
+	m := float64(inst.CtVal)
+	if m == 0 {
+		m = 1
+	}
+	if inst.CtMult > 0 {
+		m *= float64(inst.CtMult)
+	}
+	if inst.CtType == okex.ContractInverseType {
+		return q * m * (1/open - 1/px)
+	}
+	return q * m * (px - open)
+}
+
+// settleCcy is the currency PnL is realized in, the quote currency of spot pairs and the settlement currency of
+// contracts
+func settleCcy(inst *publicdata.Instrument, e *Entry) string {
+	if inst != nil {
+		if inst.SettleCcy != "" {
+			return inst.SettleCcy
+		}
+		if inst.QuoteCcy != "" {
+			return inst.QuoteCcy
+		}
+	}
+	if p := strings.Split(e.InstID, "-"); len(p) == 2 {
+		return p[1]
+	}
+	return e.Ccy
+}
+
+func sign(v float64) float64 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+type (
+	// Posting is one side of a double-entry record, positive amounts are debits and negative ones credits. The
+	// postings of an entry sum to zero in its currency.
+	Posting struct {
+		EntryID string  `json:"entryId"`
+		Account string  `json:"account"`
+		Ccy     string  `json:"ccy"`
+		Amount  float64 `json:"amount"`
+	}
+
+	// Record is an entry with its postings, as exported to JSON
+	Record struct {
+		*Entry
+		Postings []Posting `json:"postings"`
+	}
+)
+
+// Accounts of the postings. The balance accounts are suffixed with the currency.
+const (
+	AccountTrading     = "assets:okx:trading"
+	AccountFunding     = "assets:okx:funding"
+	AccountFees        = "expenses:fees"
+	AccountInterest    = "expenses:interest"
+	AccountTradingPnl  = "income:trading"
+	AccountFundingFees = "income:funding"
+	AccountConversion  = "income:conversion"
+	AccountRebates     = "income:rebates"
+	AccountRewards     = "income:rewards"
+	AccountEarn        = "income:earn"
+	AccountTransfers   = "equity:transfers"
+	AccountExternal    = "equity:external"
+	AccountSuspense    = "suspense"
+)
+
+var csvHeader = []string{"date", "entry_id", "source", "category", "inst_id", "account", "ccy", "debit", "credit", "memo"}
+
+// Debit is the amount of a debit posting, zero for credits
+func (p Posting) Debit() float64 {
+	return max(p.Amount, 0)
+}
+
+// Credit is the amount of a credit posting, zero for debits
+func (p Posting) Credit() float64 {
+	return max(-p.Amount, 0)
+}
+
+// Postings splits an entry into the change of the balance account, the fee and the counter account of its category
+func Postings(e *Entry) []Posting {
+	bal := AccountTrading
+	if e.Source == SourceFunding {
+		bal = AccountFunding
+	}
+	res := []Posting{{EntryID: e.ID, Account: bal + ":" + e.Ccy, Ccy: e.Ccy, Amount: e.Amount}}
+	rest := e.Amount
+	if e.Fee != 0 {
+		res = append(res, Posting{EntryID: e.ID, Account: AccountFees, Ccy: e.Ccy, Amount: -e.Fee})
+		rest -= e.Fee
+	}
+	if rest != 0 {
+		res = append(res, Posting{EntryID: e.ID, Account: counter(e.Category), Ccy: e.Ccy, Amount: -rest})
+	}
+	return res
+}
+
+// WriteCSV writes one row per posting
+func WriteCSV(w io.Writer, entries []*Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		for _, p := range Postings(e) {
+			row := []string{
+				e.TS.UTC().Format(time.RFC3339),
+				e.ID,
+				string(e.Source),
+				string(e.Category),
+				e.InstID,
+				p.Account,
+				p.Ccy,
+				amount(p.Debit()),
+				amount(p.Credit()),
+				e.Notes,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the entries with their postings as a JSON array
+func WriteJSON(w io.Writer, entries []*Entry) error {
+	rs := make([]Record, 0, len(entries))
+	for _, e := range entries {
+		rs = append(rs, Record{Entry: e, Postings: Postings(e)})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rs)
+}
+
+func counter(c Category) string {
+	switch c {
+	case CategoryTrade, CategoryLiquidation, CategoryADL, CategoryDelivery, CategoryClawback:
+		return AccountTradingPnl
+	case CategoryFunding:
+		return AccountFundingFees
+	case CategoryInterest:
+		return AccountInterest
+	case CategoryConversion:
+		return AccountConversion
+	case CategoryTransfer:
+		return AccountTransfers
+	case CategoryDeposit, CategoryWithdrawal:
+		return AccountExternal
+	case CategoryRebate:
+		return AccountRebates
+	case CategoryReward:
+		return AccountRewards
+	case CategoryEarn:
+		return AccountEarn
+	}
+	return AccountSuspense
+}
+
+func amount(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
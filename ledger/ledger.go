@@ -0,0 +1,311 @@
+// Package ledger turns the account and funding bills into classified ledger entries, computes the realized PnL of
+// the trades under a cost basis method and exports double-entry postings for accounting and tax tools.
+//
+// Amounts follow the sign convention of the bills, positive amounts are credited to the account and negative ones
+// debited; fees are negative when charged.
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/funding"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	fundingRequests "github.com/yitech/okex/requests/rest/funding"
+)
+
+const (
+	// RecentBills is how far back the account bills endpoint reaches, older bills are taken from the archive
+	RecentBills = 7 * 24 * time.Hour
+
+	pageLimit = 100
+)
+
+// Category is the ledger category of an entry
+type Category string
+
+const (
+	CategoryTrade       = Category("trade")
+	CategoryLiquidation = Category("liquidation")
+	CategoryADL         = Category("adl")
+	CategoryDelivery    = Category("delivery")
+	CategoryClawback    = Category("clawback")
+	CategoryFunding     = Category("funding")
+	CategoryInterest    = Category("interest")
+	CategoryConversion  = Category("conversion")
+	CategoryTransfer    = Category("transfer")
+	CategoryDeposit     = Category("deposit")
+	CategoryWithdrawal  = Category("withdrawal")
+	CategoryRebate      = Category("rebate")
+	CategoryReward      = Category("reward")
+	CategoryEarn        = Category("earn")
+	CategoryOther       = Category("other")
+)
+
+// Source is the account a bill was taken from
+type Source string
+
+const (
+	SourceTrading = Source("trading")
+	SourceFunding = Source("funding")
+)
+
+type (
+	// Entry is a classified bill
+	Entry struct {
+		ID       string         `json:"id"`
+		BillID   string         `json:"billId"`
+		Source   Source         `json:"source"`
+		Category Category       `json:"category"`
+		InstID   string         `json:"instId,omitempty"`
+		InstType string         `json:"instType,omitempty"`
+		OrdID    string         `json:"ordId,omitempty"`
+		TradeID  string         `json:"tradeId,omitempty"`
+		Ccy      string         `json:"ccy"`
+		Side     okex.OrderSide `json:"side,omitempty"`
+		// PosSide is the side of the position traded in long/short mode, empty in net mode and for spot
+		PosSide okex.PositionSide `json:"posSide,omitempty"`
+		Sz      float64           `json:"sz,omitempty"`
+		Px      float64           `json:"px,omitempty"`
+		Amount  float64           `json:"amount"`
+		Fee     float64           `json:"fee,omitempty"`
+		Pnl     float64           `json:"pnl,omitempty"`
+		Bal     float64           `json:"bal"`
+		MgnMode okex.MarginMode   `json:"mgnMode,omitempty"`
+		Notes   string            `json:"notes,omitempty"`
+		TS      time.Time         `json:"ts"`
+	}
+
+	// Ledger loads the bills of the trading and funding accounts
+	Ledger struct {
+		account api.AccountAPI
+		funding api.FundingAPI
+	}
+)
+
+// NewLedger returns a pointer to a fresh Ledger. Either client may be nil, its bills are then skipped.
+func NewLedger(a api.AccountAPI, f api.FundingAPI) *Ledger {
+	return &Ledger{account: a, funding: f}
+}
+
+// Load takes every bill of a window, oldest first. Trading account bills reach 3 months back through the archive,
+// funding account bills one month.
+func (l *Ledger) Load(begin, end time.Time) ([]*Entry, error) {
+	var res []*Entry
+	if l.account != nil {
+		bs, err := l.accountBills(begin, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bs {
+			res = append(res, FromBill(b))
+		}
+	}
+	if l.funding != nil {
+		bs, err := l.assetBills(begin, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bs {
+			res = append(res, FromAssetBill(b))
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].TS.Before(res[j].TS)
+	})
+	return res, nil
+}
+
+func (l *Ledger) accountBills(begin, end time.Time) ([]*account.Bill, error) {
+	arch := time.Since(begin) > RecentBills
+	req := accountRequests.GetBillsRequest{Begin: begin.UnixMilli(), End: end.UnixMilli(), Limit: pageLimit}
+	var res []*account.Bill
+	for {
+		page, err := l.account.GetBills(req, arch)
+		if err != nil {
+			return nil, err
+		}
+		if page.Code != 0 {
+			return nil, fmt.Errorf("ledger: get bills failed: %d %s", page.Code, page.Msg)
+		}
+		for _, b := range page.Bills {
+			if ts := time.Time(b.TS); !ts.Before(begin) && !ts.After(end) {
+				res = append(res, b)
+			}
+		}
+		if len(page.Bills) < pageLimit {
+			return res, nil
+		}
+		last := page.Bills[len(page.Bills)-1]
+		after, err := strconv.ParseInt(last.BillID, 10, 64)
+		if err != nil || time.Time(last.TS).Before(begin) {
+			return res, nil
+		}
+		req.After = after
+	}
+}
+
+// assetBills pages through the funding bills, which are paginated by timestamp
+func (l *Ledger) assetBills(begin, end time.Time) ([]*funding.Bill, error) {
+	req := fundingRequests.AssetBillsDetails{Limit: pageLimit}
+	if !end.IsZero() {
+		req.After = end.UnixMilli() + 1
+	}
+	var res []*funding.Bill
+	for {
+		page, err := l.funding.AssetBillsDetails(req)
+		if err != nil {
+			return nil, err
+		}
+		if page.Code != 0 {
+			return nil, fmt.Errorf("ledger: get asset bills failed: %d %s", page.Code, page.Msg)
+		}
+		for _, b := range page.Bills {
+			if ts := time.Time(b.TS); !ts.Before(begin) && !ts.After(end) {
+				res = append(res, b)
+			}
+		}
+		if len(page.Bills) < pageLimit {
+			return res, nil
+		}
+		last := time.Time(page.Bills[len(page.Bills)-1].TS)
+		if last.Before(begin) || (req.After != 0 && last.UnixMilli() >= req.After) {
+			return res, nil
+		}
+		req.After = last.UnixMilli()
+	}
+}
+
+// FromBill classifies a trading account bill
+func FromBill(b *account.Bill) *Entry {
+	e := &Entry{
+		ID:       "trading:" + b.BillID,
+		BillID:   b.BillID,
+		Source:   SourceTrading,
+		Category: Classify(b),
+		InstID:   b.InstID,
+		InstType: string(b.InstType),
+		OrdID:    b.OrdID,
+		TradeID:  b.TradeID,
+		Ccy:      b.Ccy,
+		Sz:       float64(b.Sz),
+		Px:       float64(b.Px),
+		Amount:   float64(b.BalChg),
+		Fee:      float64(b.Fee),
+		Pnl:      float64(b.Pnl),
+		Bal:      float64(b.Bal),
+		MgnMode:  b.MgnMode,
+		Notes:    b.Notes,
+		TS:       time.Time(b.TS),
+	}
+	switch direction(b.Type, b.SubType) {
+	case 1:
+		e.Side = okex.OrderBuy
+	case -1:
+		e.Side = okex.OrderSell
+	}
+	e.PosSide = positionSide(b.SubType)
+	return e
+}
+
+// FromAssetBill classifies a funding account bill
+func FromAssetBill(b *funding.Bill) *Entry {
+	return &Entry{
+		ID:       "funding:" + b.BillID,
+		BillID:   b.BillID,
+		Source:   SourceFunding,
+		Category: ClassifyAsset(b.Type),
+		Ccy:      b.Ccy,
+		Amount:   float64(b.BalChg),
+		Bal:      float64(b.Bal),
+		TS:       time.Time(b.TS),
+	}
+}
+
+// Classify returns the ledger category of a trading account bill
+func Classify(b *account.Bill) Category {
+	switch b.Type {
+	case okex.BillTradeType:
+		return CategoryTrade
+	case okex.BillLiquidationType:
+		return CategoryLiquidation
+	case okex.BillADLType:
+		return CategoryADL
+	case okex.BillDeliveryType:
+		return CategoryDelivery
+	case okex.BillClawbackType:
+		return CategoryClawback
+	case okex.BillFundingFeeType:
+		return CategoryFunding
+	case okex.BillInterestDeductionType:
+		return CategoryInterest
+	case okex.BillAutoTokenConversionType, okex.BillSystemTokenConversionType:
+		return CategoryConversion
+	case okex.BillTransferType, okex.BillMarginTransferType, okex.BillStrategyTransferType:
+		return CategoryTransfer
+	}
+	return CategoryOther
+}
+
+// ClassifyAsset returns the ledger category of a funding account bill type
+func ClassifyAsset(t okex.AssetBillType) Category {
+	switch t {
+	case okex.AssetBillDepositType:
+		return CategoryDeposit
+	case okex.AssetBillWithdrawalType, okex.AssetBillCanceledWithdrawalType:
+		return CategoryWithdrawal
+	case okex.AssetBillTransferToSubType, okex.AssetBillTransferFromSubType, okex.AssetBillTransferOutToMasterType,
+		okex.AssetBillTransferInFromMasterType, okex.AssetBillTransferFromTradingType, okex.AssetBillTransferToTradingType,
+		okex.AssetBillSavingsSubscriptionType, okex.AssetBillSavingsRedemptionType, okex.AssetBillStakingPurchaseType,
+		okex.AssetBillStakingRedemptionType:
+		return CategoryTransfer
+	case okex.AssetBillFeeRebateType:
+		return CategoryRebate
+	case okex.AssetBillAirdropType, okex.AssetBillEventRewardType, okex.AssetBillEventGiveawayType,
+		okex.AssetBillAffiliateCommissionType, okex.AssetBillReferralRewardType:
+		return CategoryReward
+	case okex.AssetBillStakingYieldType, okex.AssetBillSavingsYieldType:
+		return CategoryEarn
+	}
+	return CategoryOther
+}
+
+// positionSide is the side of the position a bill opens or closes, empty for buys and sells of spot or net mode
+func positionSide(s okex.BillSubType) okex.PositionSide {
+	switch s {
+	case okex.BillOpenLongSubType, okex.BillCloseLongSubType, okex.BillPartialLiquidationCloseLongSubType,
+		okex.BillLiquidationLongSubType, okex.BillADLCloseLongSubType, okex.BillDeliveryLongSubType:
+		return okex.PositionLongSide
+	case okex.BillOpenShortSubType, okex.BillCloseShortSubType, okex.BillPartialLiquidationCloseShortSubType,
+		okex.BillLiquidationShortSubType, okex.BillADLCloseShortSubType, okex.BillDeliveryShortSubType:
+		return okex.PositionShortSide
+	}
+	return ""
+}
+
+// direction is 1 for the bills of buys, -1 for sells and 0 for bills that do not trade
+func direction(t okex.BillType, s okex.BillSubType) int {
+	switch t {
+	case okex.BillTradeType, okex.BillLiquidationType, okex.BillADLType, okex.BillDeliveryType:
+	default:
+		return 0
+	}
+	switch s {
+	case okex.BillBuySubType, okex.BillOpenLongSubType, okex.BillCloseShortSubType,
+		okex.BillPartialLiquidationCloseShortSubType, okex.BillPartialLiquidationBuySubType,
+		okex.BillLiquidationShortSubType, okex.BillLiquidationBuySubType, okex.BillADLCloseShortSubType,
+		okex.BillADLBuySubType, okex.BillDeliveryShortSubType:
+		return 1
+	case okex.BillSellSubType, okex.BillOpenShortSubType, okex.BillCloseLongSubType,
+		okex.BillPartialLiquidationCloseLongSubType, okex.BillPartialLiquidationSellSubType,
+		okex.BillLiquidationLongSubType, okex.BillLiquidationSellSubType, okex.BillADLCloseLongSubType,
+		okex.BillADLSellSubType, okex.BillDeliveryLongSubType:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,343 @@
+// Package margin computes the margin requirements, margin ratio and estimated liquidation price of derivative
+// positions offline from the position tiers, for what-if analysis before changing leverage or margin.
+//
+// Fees and funding are left out, so results are slightly more optimistic than the exchange's.
+package margin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	requests "github.com/yitech/okex/requests/rest/public"
+)
+
+type (
+	// Position is the input of a calculation. Pos is signed, short positions are negative.
+	//
+	// Margin is the isolated margin in isolated mode. In cross mode it is the equity backing the position: the
+	// account equity without the unrealized PnL of the position, less the maintenance margin of other positions.
+	Position struct {
+		InstID  string
+		MgnMode okex.MarginMode
+		Pos     float64
+		AvgPx   float64
+		MarkPx  float64
+		Lever   float64
+		Margin  float64
+	}
+
+	// Result of a calculation, amounts are in the margin currency
+	Result struct {
+		Tier     *publicdata.PositionTier
+		Notional float64
+		Upl      float64
+		Imr      float64
+		Mmr      float64
+		// MgnRatio is the margin plus unrealized PnL over the maintenance margin, the position is liquidated below 1
+		MgnRatio float64
+		// LiqPx is zero when the margin covers any price
+		LiqPx float64
+	}
+
+	// Discrepancy is a position whose reported margin figures differ from the computed ones
+	Discrepancy struct {
+		InstID        string
+		MgnMode       okex.MarginMode
+		PosSide       okex.PositionSide
+		LocalLiqPx    float64
+		ExchangeLiqPx float64
+		LocalMmr      float64
+		ExchangeMmr   float64
+		TS            time.Time
+	}
+
+	// DiscrepancyHandler is called for every position whose reported figures differ from the computed ones
+	DiscrepancyHandler func(d *Discrepancy)
+
+	// Calculator holds the contract specifications and position tiers of the instruments
+	Calculator struct {
+		mu            sync.RWMutex
+		instruments   map[string]*publicdata.Instrument
+		tiers         map[string][]*publicdata.PositionTier
+		onDiscrepancy []DiscrepancyHandler
+		tol           float64
+	}
+)
+
+// NewCalculator returns a pointer to a fresh Calculator
+func NewCalculator() *Calculator {
+	return &Calculator{
+		instruments: make(map[string]*publicdata.Instrument),
+		tiers:       make(map[string][]*publicdata.PositionTier),
+		tol:         1e-3,
+	}
+}
+
+// SetTolerance sets the relative difference of liquidation prices and maintenance margins not reported by Check
+func (c *Calculator) SetTolerance(tol float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tol = tol
+}
+
+// OnDiscrepancy registers a handler for discrepancies found by Check
+func (c *Calculator) OnDiscrepancy(h DiscrepancyHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onDiscrepancy = append(c.onDiscrepancy, h)
+}
+
+// SetInstrument registers the contract specification of an instrument
+func (c *Calculator) SetInstrument(i *publicdata.Instrument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instruments[i.InstID] = i
+}
+
+// SetTiers registers position tiers, keyed by their instrument and, for derivatives, their underlying.
+// They replace the tiers registered before under the same keys.
+func (c *Calculator) SetTiers(ts []*publicdata.PositionTier) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fresh := make(map[string]bool)
+	add := func(k string, t *publicdata.PositionTier) {
+		if !fresh[k] {
+			fresh[k] = true
+			c.tiers[k] = nil
+		}
+		c.tiers[k] = append(c.tiers[k], t)
+	}
+	for _, t := range ts {
+		if t.InstID != "" {
+			add(t.InstID, t)
+		}
+		if t.Uly != "" && t.Uly != t.InstID {
+			add(t.Uly, t)
+		}
+	}
+	// ascending by maximum size, the unbounded tier last, for tier to take the first that fits
+	for k := range fresh {
+		ts := c.tiers[k]
+		sort.SliceStable(ts, func(i, j int) bool {
+			a, b := float64(ts[i].MaxSz), float64(ts[j].MaxSz)
+			return a != 0 && (b == 0 || a < b)
+		})
+	}
+}
+
+// LoadTiers takes the position tiers of an instrument type and underlying from the public data endpoint
+func (c *Calculator) LoadTiers(p api.PublicDataAPI, t okex.InstrumentType, mode okex.TradeMode, uly string) error {
+	res, err := p.GetPositionTiers(requests.GetPositionTiers{InstType: t, TdMode: mode, Uly: uly})
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("margin: get position tiers failed: %d %s", res.Code, res.Msg)
+	}
+	c.SetTiers(res.PositionTiers)
+	return nil
+}
+
+// Tier returns the tier of a position size
+func (c *Calculator) Tier(instID string, sz float64) (*publicdata.PositionTier, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tier(instID, sz)
+}
+
+// Calculate computes the margin requirements, margin ratio and liquidation price of a position
+func (c *Calculator) Calculate(p Position) (*Result, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.calculate(p)
+}
+
+// WithLeverage computes a position as if its leverage was changed. In isolated mode the margin follows the initial
+// margin at the new leverage, as it does when the leverage of an open position is set.
+func (c *Calculator) WithLeverage(p Position, lever float64) (*Result, error) {
+	if lever <= 0 {
+		return nil, fmt.Errorf("margin: invalid leverage %v", lever)
+	}
+	p.Lever = lever
+	if p.MgnMode == okex.MarginIsolatedMode {
+		c.mu.RLock()
+		m, inverse := c.contract(p.InstID)
+		c.mu.RUnlock()
+		p.Margin = notional(p.Pos, m, p.AvgPx, inverse) / lever
+	}
+	return c.Calculate(p)
+}
+
+// WithMargin computes a position as if margin was added, or removed when the amount is negative
+func (c *Calculator) WithMargin(p Position, amt float64) (*Result, error) {
+	p.Margin += amt
+	if p.Margin < 0 {
+		return nil, fmt.Errorf("margin: not enough margin to remove %v", -amt)
+	}
+	return c.Calculate(p)
+}
+
+// FromAccount converts a position reported by the exchange. In cross mode the equity backing the position must be
+// given, it is ignored in isolated mode.
+func FromAccount(p *account.Position, equity float64) Position {
+	q := float64(p.Pos)
+	switch p.PosSide {
+	case okex.PositionLongSide:
+		q = math.Abs(q)
+	case okex.PositionShortSide:
+		q = -math.Abs(q)
+	}
+	res := Position{
+		InstID:  p.InstID,
+		MgnMode: p.MgnMode,
+		Pos:     q,
+		AvgPx:   float64(p.AvgPx),
+		MarkPx:  float64(p.MarkPx),
+		Lever:   float64(p.Lever),
+		Margin:  float64(p.Margin),
+	}
+	if res.MarkPx == 0 {
+		res.MarkPx = float64(p.Last)
+	}
+	if p.MgnMode == okex.MarginCrossMode {
+		res.Margin = equity
+	}
+	return res
+}
+
+// Check computes a position reported by the exchange and reports the liquidation price and maintenance margin
+// differing from its LiqPx and Mmr by more than the tolerance
+func (c *Calculator) Check(p *account.Position, equity float64) (*Result, *Discrepancy, error) {
+	r, err := c.Calculate(FromAccount(p, equity))
+	if err != nil {
+		return nil, nil, err
+	}
+	c.mu.RLock()
+	tol, hs := c.tol, c.onDiscrepancy
+	c.mu.RUnlock()
+	if !differ(r.LiqPx, float64(p.LiqPx), tol) && !differ(r.Mmr, float64(p.Mmr), tol) {
+		return r, nil, nil
+	}
+	d := &Discrepancy{
+		InstID:        p.InstID,
+		MgnMode:       p.MgnMode,
+		PosSide:       p.PosSide,
+		LocalLiqPx:    r.LiqPx,
+		ExchangeLiqPx: float64(p.LiqPx),
+		LocalMmr:      r.Mmr,
+		ExchangeMmr:   float64(p.Mmr),
+		TS:            time.Time(p.UTime),
+	}
+	for _, h := range hs {
+		h(d)
+	}
+	return r, d, nil
+}
+
+func (c *Calculator) calculate(p Position) (*Result, error) {
+	if p.MarkPx <= 0 {
+		return nil, fmt.Errorf("margin: no mark price for %s", p.InstID)
+	}
+	sz := math.Abs(p.Pos)
+	t, ok := c.tier(p.InstID, sz)
+	if !ok {
+		return nil, fmt.Errorf("margin: no position tier for %s of size %v", p.InstID, sz)
+	}
+	m, inverse := c.contract(p.InstID)
+	r := &Result{Tier: t, Notional: notional(p.Pos, m, p.MarkPx, inverse)}
+	if inverse {
+		if p.AvgPx > 0 {
+			r.Upl = p.Pos * m * (1/p.AvgPx - 1/p.MarkPx)
+		}
+	} else {
+		r.Upl = p.Pos * m * (p.MarkPx - p.AvgPx)
+	}
+	if p.Lever > 0 {
+		r.Imr = r.Notional / p.Lever
+	} else {
+		r.Imr = r.Notional * float64(t.Imr)
+	}
+	mmr := float64(t.Mmr)
+	r.Mmr = r.Notional * mmr
+	if r.Mmr > 0 {
+		r.MgnRatio = (p.Margin + r.Upl) / r.Mmr
+	}
+	r.LiqPx = liquidation(p.Pos, m, p.AvgPx, p.Margin, mmr, inverse)
+	return r, nil
+}
+
+// liquidation solves margin + upl(px) = mmr * notional(px) with the tier of the current size
+func liquidation(q, m, avg, margin, mmr float64, inverse bool) float64 {
+	if q == 0 || avg <= 0 {
+		return 0
+	}
+	var px float64
+	if inverse {
+		if d := margin + q*m/avg; d != 0 {
+			px = (q*m + math.Abs(q)*m*mmr) / d
+		}
+	} else if d := q*m - math.Abs(q)*m*mmr; d != 0 {
+		px = (q*m*avg - margin) / d
+	}
+	if px <= 0 || math.IsInf(px, 0) || math.IsNaN(px) {
+		return 0
+	}
+	return px
+}
+
+func (c *Calculator) tier(instID string, sz float64) (*publicdata.PositionTier, bool) {
+	ts, ok := c.tiers[instID]
+	if !ok {
+		if i, found := c.instruments[instID]; found {
+			ts = c.tiers[i.Uly]
+		}
+	}
+	// sizes between the maximum of a tier and the minimum of the next one, e.g. 500.5 between 500 and 501, belong to
+	// the next one
+	for _, t := range ts {
+		if t.MaxSz == 0 || sz <= float64(t.MaxSz) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// contract returns the size multiplier and whether the instrument is inverse
+func (c *Calculator) contract(instID string) (float64, bool) {
+	i, ok := c.instruments[instID]
+	if !ok {
+		return 1, false
+	}
+	m := float64(i.CtVal)
+	if m == 0 {
+		m = 1
+	}
+	if i.CtMult > 0 {
+		m *= float64(i.CtMult)
+	}
+	return m, i.CtType == okex.ContractInverseType
+}
+
+func notional(q, m, px float64, inverse bool) float64 {
+	if inverse {
+		if px == 0 {
+			return 0
+		}
+		return math.Abs(q) * m / px
+	}
+	return math.Abs(q) * m * px
+}
+
+func differ(local, exchange, tol float64) bool {
+	if local == 0 && exchange == 0 {
+		return false
+	}
+	return math.Abs(local-exchange) > tol*math.Max(math.Abs(local), math.Abs(exchange))
+}
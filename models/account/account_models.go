@@ -66,6 +66,7 @@ type (
 		NotionalUsd okex.JSONFloat64    `json:"notionalUsd"`
 		ADL         okex.JSONFloat64    `json:"adl"`
 		Last        okex.JSONFloat64    `json:"last"`
+		MarkPx      okex.JSONFloat64    `json:"markPx"`
 		DeltaBS     okex.JSONFloat64    `json:"deltaBS"`
 		DeltaPA     okex.JSONFloat64    `json:"deltaPA"`
 		GammaBS     okex.JSONFloat64    `json:"gammaBS"`
@@ -115,6 +116,9 @@ type (
 		Notes     string              `json:"notes"`
 		BillID    string              `json:"billId"`
 		OrdID     string              `json:"ordId"`
+		TradeID   string              `json:"tradeId"`
+		Px        okex.JSONFloat64    `json:"px"`
+		ExecType  okex.OrderFlowType  `json:"execType"`
 		BalChg    okex.JSONFloat64    `json:"balChg"`
 		PosBalChg okex.JSONFloat64    `json:"posBalChg"`
 		Bal       okex.JSONFloat64    `json:"bal"`
@@ -125,7 +129,7 @@ type (
 		From      okex.AccountType    `json:"from,string"`
 		To        okex.AccountType    `json:"to,string"`
 		InstType  okex.InstrumentType `json:"instType"`
-		MgnMode   okex.MarginMode     `json:"MgnMode"`
+		MgnMode   okex.MarginMode     `json:"mgnMode"`
 		Type      okex.BillType       `json:"type,string"`
 		SubType   okex.BillSubType    `json:"subType,string"`
 		TS        okex.JSONTime       `json:"ts"`
@@ -174,14 +178,18 @@ type (
 		Side    okex.OrderSide   `json:"side,string"`
 	}
 	Fee struct {
-		Level    string              `json:"level"`
-		Taker    okex.JSONFloat64    `json:"taker"`
-		Maker    okex.JSONFloat64    `json:"maker"`
-		Delivery okex.JSONFloat64    `json:"delivery,omitempty"`
-		Exercise okex.JSONFloat64    `json:"exercise,omitempty"`
-		Category okex.FeeCategory    `json:"category,string"`
-		InstType okex.InstrumentType `json:"instType"`
-		TS       okex.JSONTime       `json:"ts"`
+		Level     string              `json:"level"`
+		Taker     okex.JSONFloat64    `json:"taker"`
+		Maker     okex.JSONFloat64    `json:"maker"`
+		TakerU    okex.JSONFloat64    `json:"takerU"`
+		MakerU    okex.JSONFloat64    `json:"makerU"`
+		TakerUSDC okex.JSONFloat64    `json:"takerUSDC"`
+		MakerUSDC okex.JSONFloat64    `json:"makerUSDC"`
+		Delivery  okex.JSONFloat64    `json:"delivery,omitempty"`
+		Exercise  okex.JSONFloat64    `json:"exercise,omitempty"`
+		Category  okex.FeeCategory    `json:"category,string"`
+		InstType  okex.InstrumentType `json:"instType"`
+		TS        okex.JSONTime       `json:"ts"`
 	}
 	InterestAccrued struct {
 		InstID       string           `json:"instId"`
@@ -0,0 +1,49 @@
+package convert
+
+import "github.com/yitech/okex"
+
+type (
+	Currency struct {
+		Ccy string           `json:"ccy"`
+		Min okex.JSONFloat64 `json:"min"`
+		Max okex.JSONFloat64 `json:"max"`
+	}
+	CurrencyPair struct {
+		InstID      string           `json:"instId"`
+		BaseCcy     string           `json:"baseCcy"`
+		BaseCcyMax  okex.JSONFloat64 `json:"baseCcyMax"`
+		BaseCcyMin  okex.JSONFloat64 `json:"baseCcyMin"`
+		QuoteCcy    string           `json:"quoteCcy"`
+		QuoteCcyMax okex.JSONFloat64 `json:"quoteCcyMax"`
+		QuoteCcyMin okex.JSONFloat64 `json:"quoteCcyMin"`
+	}
+	Quote struct {
+		QuoteID   string           `json:"quoteId"`
+		ClQReqID  string           `json:"clQReqId"`
+		BaseCcy   string           `json:"baseCcy"`
+		QuoteCcy  string           `json:"quoteCcy"`
+		Side      okex.OrderSide   `json:"side"`
+		OrigRfqSz okex.JSONFloat64 `json:"origRfqSz"`
+		RfqSz     okex.JSONFloat64 `json:"rfqSz"`
+		RfqSzCcy  string           `json:"rfqSzCcy"`
+		CnvtPx    okex.JSONFloat64 `json:"cnvtPx"`
+		BaseSz    okex.JSONFloat64 `json:"baseSz"`
+		QuoteSz   okex.JSONFloat64 `json:"quoteSz"`
+		TTLMs     okex.JSONInt64   `json:"ttlMs"`
+		QuoteTime okex.JSONTime    `json:"quoteTime"`
+	}
+	Trade struct {
+		TradeID     string                 `json:"tradeId"`
+		QuoteID     string                 `json:"quoteId,omitempty"`
+		ClTReqID    string                 `json:"clTReqId"`
+		State       okex.ConvertTradeState `json:"state"`
+		InstID      string                 `json:"instId"`
+		BaseCcy     string                 `json:"baseCcy"`
+		QuoteCcy    string                 `json:"quoteCcy"`
+		Side        okex.OrderSide         `json:"side"`
+		FillPx      okex.JSONFloat64       `json:"fillPx"`
+		FillBaseSz  okex.JSONFloat64       `json:"fillBaseSz"`
+		FillQuoteSz okex.JSONFloat64       `json:"fillQuoteSz"`
+		TS          okex.JSONTime          `json:"ts"`
+	}
+)
@@ -0,0 +1,93 @@
+package finance
+
+import "github.com/yitech/okex"
+
+type (
+	SavingsBalance struct {
+		Ccy        string           `json:"ccy"`
+		Amt        okex.JSONFloat64 `json:"amt"`
+		Earnings   okex.JSONFloat64 `json:"earnings"`
+		Rate       okex.JSONFloat64 `json:"rate"`
+		LoanAmt    okex.JSONFloat64 `json:"loanAmt"`
+		PendingAmt okex.JSONFloat64 `json:"pendingAmt"`
+		RedemptAmt okex.JSONFloat64 `json:"redemptAmt,omitempty"`
+	}
+	SavingsPurchaseRedemption struct {
+		Ccy  string           `json:"ccy"`
+		Amt  okex.JSONFloat64 `json:"amt"`
+		Side okex.ActionType  `json:"side"`
+		Rate okex.JSONFloat64 `json:"rate"`
+	}
+	LendingRate struct {
+		Ccy  string           `json:"ccy"`
+		Rate okex.JSONFloat64 `json:"rate"`
+	}
+	LendingHistory struct {
+		Ccy      string           `json:"ccy"`
+		Amt      okex.JSONFloat64 `json:"amt"`
+		Earnings okex.JSONFloat64 `json:"earnings"`
+		Rate     okex.JSONFloat64 `json:"rate"`
+		TS       okex.JSONTime    `json:"ts"`
+	}
+	LendingRateSummary struct {
+		Ccy       string           `json:"ccy"`
+		AvgAmt    okex.JSONFloat64 `json:"avgAmt"`
+		AvgAmtUsd okex.JSONFloat64 `json:"avgAmtUsd"`
+		AvgRate   okex.JSONFloat64 `json:"avgRate"`
+		PreRate   okex.JSONFloat64 `json:"preRate"`
+		EstRate   okex.JSONFloat64 `json:"estRate"`
+	}
+	LendingRateHistory struct {
+		Ccy  string           `json:"ccy"`
+		Amt  okex.JSONFloat64 `json:"amt"`
+		Rate okex.JSONFloat64 `json:"rate"`
+		TS   okex.JSONTime    `json:"ts"`
+	}
+	InvestData struct {
+		Ccy    string           `json:"ccy"`
+		Bal    okex.JSONFloat64 `json:"bal,omitempty"`
+		MinAmt okex.JSONFloat64 `json:"minAmt,omitempty"`
+		MaxAmt okex.JSONFloat64 `json:"maxAmt,omitempty"`
+		Amt    okex.JSONFloat64 `json:"amt,omitempty"`
+	}
+	EarningData struct {
+		Ccy              string           `json:"ccy"`
+		EarningType      string           `json:"earningType"`
+		Earnings         okex.JSONFloat64 `json:"earnings,omitempty"`
+		RealizedEarnings okex.JSONFloat64 `json:"realizedEarnings,omitempty"`
+	}
+	StakingOffer struct {
+		Ccy          string                 `json:"ccy"`
+		ProductID    string                 `json:"productId"`
+		Protocol     string                 `json:"protocol"`
+		ProtocolType okex.ProtocolType      `json:"protocolType"`
+		Term         okex.JSONInt64         `json:"term"`
+		Apy          okex.JSONFloat64       `json:"apy"`
+		EarlyRedeem  bool                   `json:"earlyRedeem"`
+		State        okex.StakingOfferState `json:"state"`
+		InvestData   []*InvestData          `json:"investData"`
+		EarningData  []*EarningData         `json:"earningData"`
+		RedeemPeriod []string               `json:"redeemPeriod"`
+	}
+	StakingOrderResult struct {
+		OrdID string `json:"ordId"`
+		Tag   string `json:"tag"`
+	}
+	StakingOrder struct {
+		Ccy                      string                 `json:"ccy"`
+		OrdID                    string                 `json:"ordId"`
+		ProductID                string                 `json:"productId"`
+		State                    okex.StakingOrderState `json:"state,omitempty"`
+		Protocol                 string                 `json:"protocol"`
+		ProtocolType             okex.ProtocolType      `json:"protocolType"`
+		Term                     okex.JSONInt64         `json:"term"`
+		Apy                      okex.JSONFloat64       `json:"apy"`
+		InvestData               []*InvestData          `json:"investData"`
+		EarningData              []*EarningData         `json:"earningData"`
+		PurchasedTime            okex.JSONTime          `json:"purchasedTime"`
+		RedeemedTime             okex.JSONTime          `json:"redeemedTime,omitempty"`
+		EstSettlementTime        okex.JSONTime          `json:"estSettlementTime,omitempty"`
+		CancelRedemptionDeadline okex.JSONTime          `json:"cancelRedemptionDeadline,omitempty"`
+		Tag                      string                 `json:"tag"`
+	}
+)
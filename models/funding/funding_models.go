@@ -7,7 +7,10 @@ type (
 		Ccy         string `json:"ccy"`
 		Name        string `json:"name"`
 		Chain       string `json:"chain"`
+		MainNet     bool   `json:"mainNet"`
 		MinWd       string `json:"minWd"`
+		MaxWd       string `json:"maxWd"`
+		WdTickSz    string `json:"wdTickSz"`
 		MinFee      string `json:"minFee"`
 		MaxFee      string `json:"maxFee"`
 		CanDep      bool   `json:"canDep"`
@@ -21,19 +24,20 @@ type (
 		AvailBal  string `json:"availBal"`
 	}
 	Transfer struct {
-		TransID string           `json:"transId"`
-		Ccy     string           `json:"ccy"`
-		Amt     okex.JSONFloat64 `json:"amt"`
-		From    okex.AccountType `json:"from,string"`
-		To      okex.AccountType `json:"to,string"`
+		TransID  string           `json:"transId"`
+		ClientID string           `json:"clientId"`
+		Ccy      string           `json:"ccy"`
+		Amt      okex.JSONFloat64 `json:"amt"`
+		From     okex.AccountType `json:"from,string"`
+		To       okex.AccountType `json:"to,string"`
 	}
 	Bill struct {
-		BillID string           `json:"billId"`
-		Ccy    string           `json:"ccy"`
-		Bal    okex.JSONFloat64 `json:"bal"`
-		BalChg okex.JSONFloat64 `json:"balChg"`
-		Type   okex.BillType    `json:"type,string"`
-		TS     okex.JSONTime    `json:"ts"`
+		BillID string             `json:"billId"`
+		Ccy    string             `json:"ccy"`
+		Bal    okex.JSONFloat64   `json:"bal"`
+		BalChg okex.JSONFloat64   `json:"balChg"`
+		Type   okex.AssetBillType `json:"type,string"`
+		TS     okex.JSONTime      `json:"ts"`
 	}
 	DepositAddress struct {
 		Addr     string           `json:"addr"`
@@ -48,41 +52,52 @@ type (
 		TS       okex.JSONTime    `json:"ts"`
 	}
 	DepositHistory struct {
-		Ccy   string            `json:"ccy"`
-		Chain string            `json:"chain"`
-		TxID  string            `json:"txId"`
-		From  string            `json:"from"`
-		To    string            `json:"to"`
-		DepId string            `json:"depId"`
-		Amt   okex.JSONFloat64  `json:"amt"`
-		State okex.DepositState `json:"state,string"`
-		TS    okex.JSONTime     `json:"ts"`
+		Ccy                 string            `json:"ccy"`
+		Chain               string            `json:"chain"`
+		TxID                string            `json:"txId"`
+		From                string            `json:"from"`
+		To                  string            `json:"to"`
+		DepId               string            `json:"depId"`
+		FromWdID            string            `json:"fromWdId"`
+		Amt                 okex.JSONFloat64  `json:"amt"`
+		ActualDepBlkConfirm okex.JSONInt64    `json:"actualDepBlkConfirm"`
+		State               okex.DepositState `json:"state,string"`
+		TS                  okex.JSONTime     `json:"ts"`
+	}
+	DepositInfo struct {
+		DepositHistory
+		UID     string        `json:"uid"`
+		SubAcct string        `json:"subAcct"`
+		PTime   okex.JSONTime `json:"pTime"`
 	}
 	Withdrawal struct {
-		Ccy   string           `json:"ccy"`
-		Chain string           `json:"chain"`
-		WdID  okex.JSONInt64   `json:"wdId"`
-		Amt   okex.JSONFloat64 `json:"amt"`
+		Ccy      string           `json:"ccy"`
+		Chain    string           `json:"chain"`
+		WdID     okex.JSONInt64   `json:"wdId"`
+		Amt      okex.JSONFloat64 `json:"amt"`
+		ClientID string           `json:"clientId"`
 	}
 	WithdrawalHistory struct {
-		Ccy   string               `json:"ccy"`
-		Chain string               `json:"chain"`
-		TxID  string               `json:"txId"`
-		From  string               `json:"from"`
-		To    string               `json:"to"`
-		Tag   string               `json:"tag,omitempty"`
-		PmtID string               `json:"pmtId,omitempty"`
-		Memo  string               `json:"memo,omitempty"`
-		Amt   okex.JSONFloat64     `json:"amt"`
-		Fee   okex.JSONFloat64     `json:"fee"`
-		WdID  okex.JSONInt64       `json:"wdId"`
-		State okex.WithdrawalState `json:"state,string"`
-		TS    okex.JSONTime        `json:"ts"`
+		Ccy      string               `json:"ccy"`
+		Chain    string               `json:"chain"`
+		TxID     string               `json:"txId"`
+		From     string               `json:"from"`
+		To       string               `json:"to"`
+		Tag      string               `json:"tag,omitempty"`
+		PmtID    string               `json:"pmtId,omitempty"`
+		Memo     string               `json:"memo,omitempty"`
+		Amt      okex.JSONFloat64     `json:"amt"`
+		Fee      okex.JSONFloat64     `json:"fee"`
+		WdID     okex.JSONInt64       `json:"wdId"`
+		ClientID string               `json:"clientId"`
+		State    okex.WithdrawalState `json:"state,string"`
+		TS       okex.JSONTime        `json:"ts"`
 	}
 	PiggyBank struct {
 		Ccy  string           `json:"ccy"`
 		Amt  okex.JSONFloat64 `json:"amt"`
-		Side okex.ActionType  `json:"side,string"`
+		Side okex.ActionType  `json:"side"`
+		Rate okex.JSONFloat64 `json:"rate"`
 	}
 	PiggyBankBalance struct {
 		Ccy      string           `json:"ccy"`
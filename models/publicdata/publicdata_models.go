@@ -48,10 +48,17 @@ type (
 		InstID          string              `json:"instId"`
 		InstType        okex.InstrumentType `json:"instType"`
 		FundingRate     okex.JSONFloat64    `json:"fundingRate"`
-		NextFundingRate okex.JSONFloat64    `json:"NextFundingRate"`
+		NextFundingRate okex.JSONFloat64    `json:"nextFundingRate"`
 		FundingTime     okex.JSONTime       `json:"fundingTime"`
 		NextFundingTime okex.JSONTime       `json:"nextFundingTime"`
 	}
+	FundingRateHistory struct {
+		InstID       string              `json:"instId"`
+		InstType     okex.InstrumentType `json:"instType"`
+		FundingRate  okex.JSONFloat64    `json:"fundingRate"`
+		RealizedRate okex.JSONFloat64    `json:"realizedRate"`
+		FundingTime  okex.JSONTime       `json:"fundingTime"`
+	}
 	LimitPrice struct {
 		InstID   string              `json:"instId"`
 		InstType okex.InstrumentType `json:"instType"`
@@ -18,17 +18,18 @@ type (
 		Label      string        `json:"label,omitempty"`
 		APIKey     string        `json:"apiKey,omitempty"`
 		SecretKey  string        `json:"secretKey,omitempty"`
-		Passphrase string        `json:"Passphrase,omitempty"`
+		Passphrase string        `json:"passphrase,omitempty"`
 		Perm       string        `json:"perm,omitempty"`
 		IP         string        `json:"ip,omitempty"`
 		TS         okex.JSONTime `json:"ts,omitempty"`
 	}
 	HistoryTransfer struct {
-		SubAcct string         `json:"subAcct,omitempty"`
-		Ccy     string         `json:"ccy,omitempty"`
-		BillID  okex.JSONInt64 `json:"billId,omitempty"`
-		Type    okex.BillType  `json:"type,omitempty,string"`
-		TS      okex.JSONTime  `json:"ts,omitempty"`
+		SubAcct string                  `json:"subAcct,omitempty"`
+		Ccy     string                  `json:"ccy,omitempty"`
+		BillID  okex.JSONInt64          `json:"billId,omitempty"`
+		Type    okex.SubAccountBillType `json:"type,omitempty,string"`
+		Amt     okex.JSONFloat64        `json:"amt,omitempty"`
+		TS      okex.JSONTime           `json:"ts,omitempty"`
 	}
 	Transfer struct {
 		TransID okex.JSONInt64 `json:"transId"`
@@ -46,6 +46,9 @@ type (
 		FillPx      okex.JSONFloat64    `json:"fillPx"`
 		FillSz      okex.JSONFloat64    `json:"fillSz"`
 		FillTime    okex.JSONFloat64    `json:"fillTime"`
+		FillFee     okex.JSONFloat64    `json:"fillFee"`
+		FillFeeCcy  string              `json:"fillFeeCcy"`
+		ExecType    okex.OrderFlowType  `json:"execType"`
 		AvgPx       okex.JSONFloat64    `json:"avgPx"`
 		Lever       okex.JSONFloat64    `json:"lever"`
 		TpTriggerPx okex.JSONFloat64    `json:"tpTriggerPx"`
@@ -82,9 +85,18 @@ type (
 		TS       okex.JSONTime       `json:"ts"`
 	}
 	PlaceAlgoOrder struct {
-		AlgoID string         `json:"algoId"`
-		SMsg   string         `json:"sMsg"`
-		SCode  okex.JSONInt64 `json:"sCode"`
+		AlgoID      string         `json:"algoId"`
+		AlgoClOrdID string         `json:"algoClOrdId"`
+		Tag         string         `json:"tag"`
+		SMsg        string         `json:"sMsg"`
+		SCode       okex.JSONInt64 `json:"sCode"`
+	}
+	AmendAlgoOrder struct {
+		AlgoID      string         `json:"algoId"`
+		AlgoClOrdID string         `json:"algoClOrdId"`
+		ReqID       string         `json:"reqId"`
+		SMsg        string         `json:"sMsg"`
+		SCode       okex.JSONInt64 `json:"sCode"`
 	}
 	CancelAlgoOrder struct {
 		AlgoID string         `json:"algoId"`
@@ -92,48 +104,66 @@ type (
 		SCode  okex.JSONInt64 `json:"sCode"`
 	}
 	AlgoOrder struct {
-		InstID       string              `json:"instId"`
-		Ccy          string              `json:"ccy"`
-		OrdID        string              `json:"ordId"`
-		AlgoID       string              `json:"algoId"`
-		ClOrdID      string              `json:"clOrdId"`
-		TradeID      string              `json:"tradeId"`
-		Tag          string              `json:"tag"`
-		Category     string              `json:"category"`
-		FeeCcy       string              `json:"feeCcy"`
-		RebateCcy    string              `json:"rebateCcy"`
-		TimeInterval string              `json:"timeInterval"`
-		Px           okex.JSONFloat64    `json:"px"`
-		PxVar        okex.JSONFloat64    `json:"pxVar"`
-		PxSpread     okex.JSONFloat64    `json:"pxSpread"`
-		PxLimit      okex.JSONFloat64    `json:"pxLimit"`
-		Sz           okex.JSONFloat64    `json:"sz"`
-		SzLimit      okex.JSONFloat64    `json:"szLimit"`
-		ActualSz     okex.JSONFloat64    `json:"actualSz"`
-		ActualPx     okex.JSONFloat64    `json:"actualPx"`
-		Pnl          okex.JSONFloat64    `json:"pnl"`
-		AccFillSz    okex.JSONFloat64    `json:"accFillSz"`
-		FillPx       okex.JSONFloat64    `json:"fillPx"`
-		FillSz       okex.JSONFloat64    `json:"fillSz"`
-		FillTime     okex.JSONFloat64    `json:"fillTime"`
-		AvgPx        okex.JSONFloat64    `json:"avgPx"`
-		Lever        okex.JSONFloat64    `json:"lever"`
-		TpTriggerPx  okex.JSONFloat64    `json:"tpTriggerPx"`
-		TpOrdPx      okex.JSONFloat64    `json:"tpOrdPx"`
-		SlTriggerPx  okex.JSONFloat64    `json:"slTriggerPx"`
-		SlOrdPx      okex.JSONFloat64    `json:"slOrdPx"`
-		OrdPx        okex.JSONFloat64    `json:"ordPx"`
-		Fee          okex.JSONFloat64    `json:"fee"`
-		Rebate       okex.JSONFloat64    `json:"rebate"`
-		State        okex.OrderState     `json:"state"`
-		TdMode       okex.TradeMode      `json:"tdMode"`
-		ActualSide   okex.PositionSide   `json:"actualSide"`
-		PosSide      okex.PositionSide   `json:"posSide"`
-		Side         okex.OrderSide      `json:"side"`
-		OrdType      okex.AlgoOrderType  `json:"ordType"`
-		InstType     okex.InstrumentType `json:"instType"`
-		TgtCcy       okex.QuantityType   `json:"tgtCcy"`
-		CTime        okex.JSONTime       `json:"cTime"`
-		TriggerTime  okex.JSONTime       `json:"triggerTime"`
+		InstID          string              `json:"instId"`
+		Ccy             string              `json:"ccy"`
+		OrdID           string              `json:"ordId"`
+		AlgoID          string              `json:"algoId"`
+		ClOrdID         string              `json:"clOrdId"`
+		AlgoClOrdID     string              `json:"algoClOrdId"`
+		TradeID         string              `json:"tradeId"`
+		Tag             string              `json:"tag"`
+		Category        string              `json:"category"`
+		FeeCcy          string              `json:"feeCcy"`
+		RebateCcy       string              `json:"rebateCcy"`
+		TimeInterval    string              `json:"timeInterval"`
+		ReduceOnly      string              `json:"reduceOnly"`
+		FailCode        string              `json:"failCode"`
+		Px              okex.JSONFloat64    `json:"px"`
+		PxVar           okex.JSONFloat64    `json:"pxVar"`
+		PxSpread        okex.JSONFloat64    `json:"pxSpread"`
+		PxLimit         okex.JSONFloat64    `json:"pxLimit"`
+		Sz              okex.JSONFloat64    `json:"sz"`
+		SzLimit         okex.JSONFloat64    `json:"szLimit"`
+		CloseFraction   okex.JSONFloat64    `json:"closeFraction"`
+		ActualSz        okex.JSONFloat64    `json:"actualSz"`
+		ActualPx        okex.JSONFloat64    `json:"actualPx"`
+		Pnl             okex.JSONFloat64    `json:"pnl"`
+		AccFillSz       okex.JSONFloat64    `json:"accFillSz"`
+		FillPx          okex.JSONFloat64    `json:"fillPx"`
+		FillSz          okex.JSONFloat64    `json:"fillSz"`
+		FillTime        okex.JSONFloat64    `json:"fillTime"`
+		AvgPx           okex.JSONFloat64    `json:"avgPx"`
+		Lever           okex.JSONFloat64    `json:"lever"`
+		Last            okex.JSONFloat64    `json:"last"`
+		TpTriggerPx     okex.JSONFloat64    `json:"tpTriggerPx"`
+		TpOrdPx         okex.JSONFloat64    `json:"tpOrdPx"`
+		SlTriggerPx     okex.JSONFloat64    `json:"slTriggerPx"`
+		SlOrdPx         okex.JSONFloat64    `json:"slOrdPx"`
+		TriggerPx       okex.JSONFloat64    `json:"triggerPx"`
+		OrdPx           okex.JSONFloat64    `json:"ordPx"`
+		CallbackRatio   okex.JSONFloat64    `json:"callbackRatio"`
+		CallbackSpread  okex.JSONFloat64    `json:"callbackSpread"`
+		ActivePx        okex.JSONFloat64    `json:"activePx"`
+		MoveTriggerPx   okex.JSONFloat64    `json:"moveTriggerPx"`
+		Fee             okex.JSONFloat64    `json:"fee"`
+		Rebate          okex.JSONFloat64    `json:"rebate"`
+		State           okex.OrderState     `json:"state"`
+		TdMode          okex.TradeMode      `json:"tdMode"`
+		ActualSide      okex.ActualSide     `json:"actualSide"`
+		PosSide         okex.PositionSide   `json:"posSide"`
+		Side            okex.OrderSide      `json:"side"`
+		OrdType         okex.AlgoOrderType  `json:"ordType"`
+		InstType        okex.InstrumentType `json:"instType"`
+		TgtCcy          okex.QuantityType   `json:"tgtCcy"`
+		TpTriggerPxType okex.TriggerPxType  `json:"tpTriggerPxType"`
+		SlTriggerPxType okex.TriggerPxType  `json:"slTriggerPxType"`
+		TriggerPxType   okex.TriggerPxType  `json:"triggerPxType"`
+		CTime           okex.JSONTime       `json:"cTime"`
+		UTime           okex.JSONTime       `json:"uTime"`
+		TriggerTime     okex.JSONTime       `json:"triggerTime"`
+	}
+	CancelAllAfter struct {
+		TriggerTime okex.JSONTime `json:"triggerTime"`
+		TS          okex.JSONTime `json:"ts"`
 	}
 )
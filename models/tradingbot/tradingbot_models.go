@@ -0,0 +1,167 @@
+package tradingbot
+
+import "github.com/yitech/okex"
+
+type (
+	TriggerParam struct {
+		TriggerAction   string            `json:"triggerAction"`
+		TriggerStrategy string            `json:"triggerStrategy"`
+		DelaySeconds    okex.JSONInt64    `json:"delaySeconds,omitempty"`
+		Timeframe       string            `json:"timeframe,omitempty"`
+		Thold           string            `json:"thold,omitempty"`
+		TriggerCond     string            `json:"triggerCond,omitempty"`
+		TimePeriod      string            `json:"timePeriod,omitempty"`
+		TriggerPx       okex.JSONFloat64  `json:"triggerPx,omitempty"`
+		StopType        okex.GridStopType `json:"stopType,omitempty"`
+		TriggerTime     okex.JSONTime     `json:"triggerTime,omitempty"`
+		TriggerType     string            `json:"triggerType,omitempty"`
+	}
+	Rebate struct {
+		Rebate    okex.JSONFloat64 `json:"rebate"`
+		RebateCcy string           `json:"rebateCcy"`
+	}
+	GridOrder struct {
+		AlgoID              string                 `json:"algoId"`
+		AlgoClOrdID         string                 `json:"algoClOrdId"`
+		InstType            okex.InstrumentType    `json:"instType"`
+		InstID              string                 `json:"instId"`
+		Uly                 string                 `json:"uly"`
+		InstFamily          string                 `json:"instFamily"`
+		AlgoOrdType         okex.GridAlgoOrderType `json:"algoOrdType"`
+		State               okex.GridState         `json:"state"`
+		MaxPx               okex.JSONFloat64       `json:"maxPx"`
+		MinPx               okex.JSONFloat64       `json:"minPx"`
+		GridNum             okex.JSONInt64         `json:"gridNum"`
+		RunType             okex.GridRunType       `json:"runType"`
+		TpTriggerPx         okex.JSONFloat64       `json:"tpTriggerPx"`
+		SlTriggerPx         okex.JSONFloat64       `json:"slTriggerPx"`
+		TpRatio             okex.JSONFloat64       `json:"tpRatio"`
+		SlRatio             okex.JSONFloat64       `json:"slRatio"`
+		TriggerParams       []*TriggerParam        `json:"triggerParams"`
+		ArbitrageNum        okex.JSONInt64         `json:"arbitrageNum"`
+		TradeNum            okex.JSONInt64         `json:"tradeNum"`
+		ActiveOrdNum        okex.JSONInt64         `json:"activeOrdNum"`
+		TotalPnl            okex.JSONFloat64       `json:"totalPnl"`
+		PnlRatio            okex.JSONFloat64       `json:"pnlRatio"`
+		GridProfit          okex.JSONFloat64       `json:"gridProfit"`
+		FloatProfit         okex.JSONFloat64       `json:"floatProfit"`
+		Profit              okex.JSONFloat64       `json:"profit"`
+		TotalAnnualizedRate okex.JSONFloat64       `json:"totalAnnualizedRate"`
+		AnnualizedRate      okex.JSONFloat64       `json:"annualizedRate"`
+		PerMinProfitRate    okex.JSONFloat64       `json:"perMinProfitRate"`
+		PerMaxProfitRate    okex.JSONFloat64       `json:"perMaxProfitRate"`
+		Investment          okex.JSONFloat64       `json:"investment"`
+		SingleAmt           okex.JSONFloat64       `json:"singleAmt"`
+		QuoteSz             okex.JSONFloat64       `json:"quoteSz"`
+		BaseSz              okex.JSONFloat64       `json:"baseSz"`
+		CurQuoteSz          okex.JSONFloat64       `json:"curQuoteSz"`
+		CurBaseSz           okex.JSONFloat64       `json:"curBaseSz"`
+		Direction           okex.GridDirection     `json:"direction"`
+		BasePos             bool                   `json:"basePos"`
+		Sz                  okex.JSONFloat64       `json:"sz"`
+		Lever               okex.JSONFloat64       `json:"lever"`
+		ActualLever         okex.JSONFloat64       `json:"actualLever"`
+		LiqPx               okex.JSONFloat64       `json:"liqPx"`
+		Eq                  okex.JSONFloat64       `json:"eq"`
+		AvailEq             okex.JSONFloat64       `json:"availEq"`
+		OrdFrozen           okex.JSONFloat64       `json:"ordFrozen"`
+		Fee                 okex.JSONFloat64       `json:"fee"`
+		FundingFee          okex.JSONFloat64       `json:"fundingFee"`
+		RebateTrans         []*Rebate              `json:"rebateTrans"`
+		CancelType          string                 `json:"cancelType"`
+		StopType            okex.GridStopType      `json:"stopType"`
+		StopResult          string                 `json:"stopResult"`
+		ProfitSharingRatio  okex.JSONFloat64       `json:"profitSharingRatio"`
+		CopyType            string                 `json:"copyType"`
+		Tag                 string                 `json:"tag"`
+		CTime               okex.JSONTime          `json:"cTime"`
+		UTime               okex.JSONTime          `json:"uTime"`
+		PTime               okex.JSONTime          `json:"pTime"`
+	}
+	GridOrderResult struct {
+		AlgoID      string         `json:"algoId"`
+		AlgoClOrdID string         `json:"algoClOrdId"`
+		Tag         string         `json:"tag"`
+		SCode       okex.JSONInt64 `json:"sCode"`
+		SMsg        string         `json:"sMsg"`
+	}
+	GridSubOrder struct {
+		AlgoID      string                 `json:"algoId"`
+		AlgoClOrdID string                 `json:"algoClOrdId"`
+		InstType    okex.InstrumentType    `json:"instType"`
+		InstID      string                 `json:"instId"`
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		GroupID     string                 `json:"groupId"`
+		OrdID       string                 `json:"ordId"`
+		TdMode      okex.TradeMode         `json:"tdMode"`
+		Ccy         string                 `json:"ccy"`
+		OrdType     okex.OrderType         `json:"ordType"`
+		Side        okex.OrderSide         `json:"side"`
+		PosSide     okex.PositionSide      `json:"posSide"`
+		State       okex.OrderState        `json:"state"`
+		Px          okex.JSONFloat64       `json:"px"`
+		Sz          okex.JSONFloat64       `json:"sz"`
+		AvgPx       okex.JSONFloat64       `json:"avgPx"`
+		AccFillSz   okex.JSONFloat64       `json:"accFillSz"`
+		Fee         okex.JSONFloat64       `json:"fee"`
+		FeeCcy      string                 `json:"feeCcy"`
+		Pnl         okex.JSONFloat64       `json:"pnl"`
+		CtVal       okex.JSONFloat64       `json:"ctVal"`
+		Lever       okex.JSONFloat64       `json:"lever"`
+		Tag         string                 `json:"tag"`
+		CTime       okex.JSONTime          `json:"cTime"`
+		UTime       okex.JSONTime          `json:"uTime"`
+		PTime       okex.JSONTime          `json:"pTime"`
+	}
+	GridPosition struct {
+		AlgoID      string              `json:"algoId"`
+		AlgoClOrdID string              `json:"algoClOrdId"`
+		InstType    okex.InstrumentType `json:"instType"`
+		InstID      string              `json:"instId"`
+		Ccy         string              `json:"ccy"`
+		MgnMode     okex.MarginMode     `json:"mgnMode"`
+		PosSide     okex.PositionSide   `json:"posSide"`
+		Pos         okex.JSONFloat64    `json:"pos"`
+		AvgPx       okex.JSONFloat64    `json:"avgPx"`
+		Lever       okex.JSONFloat64    `json:"lever"`
+		LiqPx       okex.JSONFloat64    `json:"liqPx"`
+		MgnRatio    okex.JSONFloat64    `json:"mgnRatio"`
+		Imr         okex.JSONFloat64    `json:"imr"`
+		Mmr         okex.JSONFloat64    `json:"mmr"`
+		Upl         okex.JSONFloat64    `json:"upl"`
+		UplRatio    okex.JSONFloat64    `json:"uplRatio"`
+		Last        okex.JSONFloat64    `json:"last"`
+		MarkPx      okex.JSONFloat64    `json:"markPx"`
+		NotionalUsd okex.JSONFloat64    `json:"notionalUsd"`
+		Adl         okex.JSONInt64      `json:"adl"`
+		CTime       okex.JSONTime       `json:"cTime"`
+		UTime       okex.JSONTime       `json:"uTime"`
+		PTime       okex.JSONTime       `json:"pTime"`
+	}
+	AIParam struct {
+		InstID             string                 `json:"instId"`
+		AlgoOrdType        okex.GridAlgoOrderType `json:"algoOrdType"`
+		Duration           string                 `json:"duration"`
+		GridNum            okex.JSONInt64         `json:"gridNum"`
+		MaxPx              okex.JSONFloat64       `json:"maxPx"`
+		MinPx              okex.JSONFloat64       `json:"minPx"`
+		PerMaxProfitRate   okex.JSONFloat64       `json:"perMaxProfitRate"`
+		PerMinProfitRate   okex.JSONFloat64       `json:"perMinProfitRate"`
+		PerGridProfitRatio okex.JSONFloat64       `json:"perGridProfitRatio"`
+		AnnualizedRate     okex.JSONFloat64       `json:"annualizedRate"`
+		MinInvestment      okex.JSONFloat64       `json:"minInvestment"`
+		Ccy                string                 `json:"ccy"`
+		SourceCcy          string                 `json:"sourceCcy"`
+		RunType            okex.GridRunType       `json:"runType"`
+		Direction          okex.GridDirection     `json:"direction"`
+		Lever              okex.JSONFloat64       `json:"lever"`
+	}
+	Investment struct {
+		Amt okex.JSONFloat64 `json:"amt"`
+		Ccy string           `json:"ccy"`
+	}
+	MinInvestment struct {
+		MinInvestmentData []*Investment    `json:"minInvestmentData"`
+		SingleAmt         okex.JSONFloat64 `json:"singleAmt"`
+	}
+)
@@ -0,0 +1,456 @@
+// Package oms keeps track of the orders sent by the process, from submission to a terminal state.
+//
+// Orders are created when they are submitted through the Manager, updated by the place order acknowledgements
+// and by the `orders` private channel pushes, and can be rebuilt from the exchange after a restart.
+package oms
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/models/trade"
+	requests "github.com/yitech/okex/requests/rest/trade"
+	wsRequests "github.com/yitech/okex/requests/ws/trade"
+)
+
+type (
+	// UpdateHandler is called whenever an order changes state
+	UpdateHandler func(o *Order, prev okex.OrderState)
+	// FillHandler is called once for every new execution of an order
+	FillHandler func(o *Order, f *Fill)
+
+	// Manager owns the orders sent through either the rest or the websocket Trade client
+	Manager struct {
+		rest     api.OrderAPI
+		ws       api.TradeStream
+		prefix   string
+		seq      uint64
+		mu       sync.RWMutex
+		orders   map[string]*Order
+		byOrdID  map[string]string
+		onUpdate []UpdateHandler
+		onFill   []fillHandler
+		handlers uint64
+	}
+
+	fillHandler struct {
+		id uint64
+		h  FillHandler
+	}
+
+	notification struct {
+		order *Order
+		prev  okex.OrderState
+		fill  *Fill
+	}
+)
+
+const defaultPrefix = "oms"
+
+// NewManager returns a pointer to a fresh Manager. Either client may be nil if it is not used.
+func NewManager(r api.OrderAPI, w api.TradeStream) *Manager {
+	return &Manager{
+		rest:    r,
+		ws:      w,
+		prefix:  defaultPrefix,
+		orders:  make(map[string]*Order),
+		byOrdID: make(map[string]string),
+	}
+}
+
+// SetPrefix sets the prefix of the generated client order ids. It must start with a letter and only contain alphanumerics.
+func (m *Manager) SetPrefix(p string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prefix = p
+}
+
+// OnUpdate registers a handler for state changes
+func (m *Manager) OnUpdate(h UpdateHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onUpdate = append(m.onUpdate, h)
+}
+
+// OnFill registers a handler for new executions and returns a function removing it
+func (m *Manager) OnFill(h FillHandler) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers++
+	id := m.handlers
+	m.onFill = append(m.onFill, fillHandler{id: id, h: h})
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		hs := make([]fillHandler, 0, len(m.onFill))
+		for _, f := range m.onFill {
+			if f.id != id {
+				hs = append(hs, f)
+			}
+		}
+		m.onFill = hs
+	}
+}
+
+// NewClOrdID returns a unique client order id
+func (m *Manager) NewClOrdID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nextClOrdID()
+}
+
+// Track registers an order as submitted and returns its record. A client order id is generated if the request has none.
+func (m *Manager) Track(req requests.PlaceOrderRequest) *Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.track(req).clone()
+}
+
+// PlaceOrder submits the orders through the rest client and applies the acknowledgements
+func (m *Manager) PlaceOrder(req ...requests.PlaceOrderRequest) ([]*Order, error) {
+	if m.rest == nil {
+		return nil, fmt.Errorf("oms: rest trade client is not set")
+	}
+	if len(req) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	ids := make([]string, len(req))
+	for i := range req {
+		o := m.track(req[i])
+		req[i].ClOrdId = o.ClOrdID
+		ids[i] = o.ClOrdID
+	}
+	m.mu.Unlock()
+
+	res, err := m.rest.PlaceOrder(req)
+	if err != nil {
+		m.reject(ids, err.Error())
+		return m.snapshot(ids), err
+	}
+	if len(res.Orders) == 0 && res.Code != 0 {
+		m.reject(ids, res.Msg)
+		return m.snapshot(ids), fmt.Errorf("oms: place order failed: %d %s", res.Code, res.Msg)
+	}
+	m.HandleAck(res.Orders)
+	return m.snapshot(ids), nil
+}
+
+// PlaceOrderWs submits the orders through the websocket client.
+// The acknowledgements arrive asynchronously and must be fed to HandleSuccess and HandleError.
+func (m *Manager) PlaceOrderWs(req ...wsRequests.PlaceOrder) ([]*Order, error) {
+	if m.ws == nil {
+		return nil, fmt.Errorf("oms: websocket trade client is not set")
+	}
+	if len(req) == 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	ids := make([]string, len(req))
+	for i, r := range req {
+		o := m.track(r.PlaceOrderRequest)
+		req[i].ClOrdId = o.ClOrdID
+		if req[i].ID == "" {
+			req[i].ID = o.ClOrdID
+		}
+		ids[i] = o.ClOrdID
+	}
+	m.mu.Unlock()
+
+	if err := m.ws.PlaceOrder(req...); err != nil {
+		m.reject(ids, err.Error())
+		return m.snapshot(ids), err
+	}
+	return m.snapshot(ids), nil
+}
+
+// HandleAck applies the place order acknowledgements returned by the rest client
+func (m *Manager) HandleAck(acks []*trade.PlaceOrder) {
+	var n []notification
+	m.mu.Lock()
+	for _, a := range acks {
+		n = append(n, m.ack(a.ClOrdID, a.OrdID, int64(a.SCode), a.SMsg)...)
+	}
+	m.mu.Unlock()
+	m.notify(n)
+}
+
+// HandleSuccess applies a websocket place order acknowledgement
+func (m *Manager) HandleSuccess(e *events.Success) {
+	if e.Op != okex.OrderOperation && e.Op != okex.BatchOrderOperation {
+		return
+	}
+	m.handleWsAck(e.Data)
+}
+
+// HandleError applies a failed websocket place order acknowledgement
+func (m *Manager) HandleError(e *events.Error) {
+	if e.Op != string(okex.OrderOperation) && e.Op != string(okex.BatchOrderOperation) {
+		return
+	}
+	m.handleWsAck(e.Data)
+}
+
+// HandleOrder applies an `orders` channel push
+func (m *Manager) HandleOrder(e *private.Order) {
+	for _, u := range e.Orders {
+		m.Apply(u)
+	}
+}
+
+// Apply merges an exchange order update. Updates older than the record are only used for the fills they carry.
+func (m *Manager) Apply(u *trade.Order) {
+	m.mu.Lock()
+	n := m.apply(u)
+	m.mu.Unlock()
+	m.notify(n)
+}
+
+// Rebuild loads every incomplete order from the exchange and settles the tracked open orders that are no longer listed
+func (m *Manager) Rebuild() error {
+	if m.rest == nil {
+		return fmt.Errorf("oms: rest trade client is not set")
+	}
+	listed := make(map[string]bool)
+	req := requests.OrderListRequest{Limit: 100}
+	for {
+		res, err := m.rest.GetOrderList(req)
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("oms: get order list failed: %d %s", res.Code, res.Msg)
+		}
+		for _, u := range res.Orders {
+			listed[u.OrdID] = true
+			m.Apply(u)
+		}
+		if len(res.Orders) < int(req.Limit) {
+			break
+		}
+		after, err := strconv.ParseInt(res.Orders[len(res.Orders)-1].OrdID, 10, 64)
+		if err != nil {
+			return err
+		}
+		req.After = after
+	}
+
+	for _, o := range m.OpenOrders() {
+		if o.OrdID == "" || listed[o.OrdID] {
+			continue
+		}
+		res, err := m.rest.GetOrderDetail(requests.OrderDetailsRequest{InstID: o.InstID, OrdId: o.OrdID})
+		if err != nil {
+			return err
+		}
+		for _, u := range res.Orders {
+			m.Apply(u)
+		}
+	}
+	return nil
+}
+
+// Order returns a copy of the order with the given client order id
+func (m *Manager) Order(clOrdID string) (*Order, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	o, ok := m.orders[clOrdID]
+	if !ok {
+		return nil, false
+	}
+	return o.clone(), true
+}
+
+// OrderByID returns a copy of the order with the given exchange order id
+func (m *Manager) OrderByID(ordID string) (*Order, bool) {
+	m.mu.RLock()
+	k, ok := m.byOrdID[ordID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return m.Order(k)
+}
+
+// OpenOrders returns a copy of every order that is not in a terminal state
+func (m *Manager) OpenOrders() []*Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []*Order
+	for _, o := range m.orders {
+		if o.IsOpen() {
+			res = append(res, o.clone())
+		}
+	}
+	return res
+}
+
+// Fills returns the executions of the order with the given client order id
+func (m *Manager) Fills(clOrdID string) []*Fill {
+	o, ok := m.Order(clOrdID)
+	if !ok {
+		return nil
+	}
+	return o.Fills
+}
+
+// Prune forgets the orders that reached a terminal state before t
+func (m *Manager) Prune(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, o := range m.orders {
+		if o.IsDone() && o.UTime.Before(t) {
+			delete(m.orders, k)
+			delete(m.byOrdID, o.OrdID)
+		}
+	}
+}
+
+func (m *Manager) nextClOrdID() string {
+	m.seq++
+	return m.prefix + strconv.FormatInt(time.Now().UnixMilli(), 36) + strconv.FormatUint(m.seq, 36)
+}
+
+func (m *Manager) track(req requests.PlaceOrderRequest) *Order {
+	if req.ClOrdId == "" {
+		req.ClOrdId = m.nextClOrdID()
+	}
+	px, _ := strconv.ParseFloat(req.Px, 64)
+	sz, _ := strconv.ParseFloat(req.Sz, 64)
+	o := &Order{
+		InstID:  req.InstID,
+		ClOrdID: req.ClOrdId,
+		Tag:     req.Tag,
+		Px:      px,
+		Sz:      sz,
+		State:   OrderPending,
+		TdMode:  req.TdMode,
+		Side:    req.Side,
+		PosSide: req.PosSide,
+		OrdType: req.OrdType,
+		CTime:   time.Now(),
+	}
+	m.orders[o.ClOrdID] = o
+	return o
+}
+
+func (m *Manager) ack(clOrdID, ordID string, code int64, msg string) []notification {
+	o, ok := m.orders[clOrdID]
+	if !ok {
+		return nil
+	}
+	if ordID != "" && o.OrdID == "" {
+		o.OrdID = ordID
+		m.byOrdID[ordID] = clOrdID
+	}
+	if o.State != OrderPending {
+		return nil
+	}
+	if code != 0 {
+		o.State = OrderRejected
+		o.RejectMsg = msg
+		o.UTime = time.Now()
+	} else {
+		o.State = okex.OrderLive
+	}
+	return []notification{{order: o.clone(), prev: OrderPending}}
+}
+
+func (m *Manager) reject(ids []string, msg string) {
+	var n []notification
+	m.mu.Lock()
+	for _, id := range ids {
+		n = append(n, m.ack(id, "", 1, msg)...)
+	}
+	m.mu.Unlock()
+	m.notify(n)
+}
+
+func (m *Manager) handleWsAck(data []*events.Argument) {
+	var n []notification
+	m.mu.Lock()
+	for _, a := range data {
+		code, _ := strconv.ParseInt(argString(a, "sCode"), 10, 64)
+		n = append(n, m.ack(argString(a, "clOrdId"), argString(a, "ordId"), code, argString(a, "sMsg"))...)
+	}
+	m.mu.Unlock()
+	m.notify(n)
+}
+
+func (m *Manager) apply(u *trade.Order) []notification {
+	k := u.ClOrdID
+	if k == "" {
+		k = m.byOrdID[u.OrdID]
+	}
+	if k == "" {
+		k = u.OrdID
+	}
+	o, ok := m.orders[k]
+	if !ok {
+		o = &Order{ClOrdID: u.ClOrdID, State: OrderPending}
+		m.orders[k] = o
+	}
+	if u.OrdID != "" {
+		m.byOrdID[u.OrdID] = k
+	}
+
+	prev := o.State
+	f := o.apply(u)
+	var n []notification
+	if o.State != prev {
+		n = append(n, notification{order: o.clone(), prev: prev})
+	}
+	if f != nil {
+		ff := *f
+		n = append(n, notification{order: o.clone(), fill: &ff})
+	}
+	return n
+}
+
+func (m *Manager) snapshot(ids []string) []*Order {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := make([]*Order, 0, len(ids))
+	for _, id := range ids {
+		if o, ok := m.orders[id]; ok {
+			res = append(res, o.clone())
+		}
+	}
+	return res
+}
+
+func (m *Manager) notify(n []notification) {
+	if len(n) == 0 {
+		return
+	}
+	m.mu.RLock()
+	onUpdate := m.onUpdate
+	onFill := m.onFill
+	m.mu.RUnlock()
+	for _, e := range n {
+		if e.fill != nil {
+			for _, f := range onFill {
+				f.h(e.order, e.fill)
+			}
+			continue
+		}
+		for _, h := range onUpdate {
+			h(e.order, e.prev)
+		}
+	}
+}
+
+func argString(a *events.Argument, k string) string {
+	v, ok := a.Get(k)
+	if !ok {
+		return ""
+	}
+	s, _ := v.(string)
+	return s
+}
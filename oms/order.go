@@ -0,0 +1,178 @@
+package oms
+
+import (
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/models/trade"
+)
+
+const (
+	// OrderPending is the local state of an order that has been submitted but not acknowledged yet
+	OrderPending = okex.OrderState("pending")
+	// OrderRejected is the local state of an order that the exchange refused on submission
+	OrderRejected = okex.OrderState("rejected")
+)
+
+type (
+	// Order is the lifecycle record of a single order owned by the Manager
+	Order struct {
+		InstID    string
+		OrdID     string
+		ClOrdID   string
+		Tag       string
+		RejectMsg string
+		Px        float64
+		Sz        float64
+		AccFillSz float64
+		AvgPx     float64
+		Fee       float64
+		FeeCcy    string
+		State     okex.OrderState
+		TdMode    okex.TradeMode
+		Side      okex.OrderSide
+		PosSide   okex.PositionSide
+		OrdType   okex.OrderType
+		InstType  okex.InstrumentType
+		Fills     []*Fill
+		CTime     time.Time
+		UTime     time.Time
+	}
+
+	// Fill is a single execution of an Order
+	Fill struct {
+		TradeID string
+		FillPx  float64
+		FillSz  float64
+		Fee     float64
+		FeeCcy  string
+		// ExecType tells whether the fill was maker or taker, empty when the push did not say
+		ExecType okex.OrderFlowType
+		TS       time.Time
+	}
+)
+
+// transitions lists the states an order may move into from a given state
+var transitions = map[okex.OrderState][]okex.OrderState{
+	OrderPending:              {okex.OrderLive, okex.OrderPartiallyFilled, okex.OrderFilled, okex.OrderCancel, OrderRejected},
+	okex.OrderLive:            {okex.OrderLive, okex.OrderPartiallyFilled, okex.OrderFilled, okex.OrderCancel},
+	okex.OrderPartiallyFilled: {okex.OrderPartiallyFilled, okex.OrderFilled, okex.OrderCancel},
+}
+
+// CanTransition reports whether an order in state from may move into state to
+func CanTransition(from, to okex.OrderState) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether the order can still be filled or canceled
+func (o *Order) IsOpen() bool {
+	return o.State == OrderPending || o.State == okex.OrderLive || o.State == okex.OrderPartiallyFilled
+}
+
+// IsDone reports whether the order reached a terminal state
+func (o *Order) IsDone() bool {
+	return !o.IsOpen()
+}
+
+// RemainingSz returns the size that is still unfilled
+func (o *Order) RemainingSz() float64 {
+	if o.Sz <= o.AccFillSz {
+		return 0
+	}
+	return o.Sz - o.AccFillSz
+}
+
+func (o *Order) clone() *Order {
+	c := *o
+	c.Fills = make([]*Fill, len(o.Fills))
+	for i, f := range o.Fills {
+		ff := *f
+		c.Fills[i] = &ff
+	}
+	return &c
+}
+
+func (o *Order) hasFill(tradeID string) bool {
+	for _, f := range o.Fills {
+		if f.TradeID == tradeID {
+			return true
+		}
+	}
+	return false
+}
+
+// isStale reports whether u is older than what the record already reflects
+func (o *Order) isStale(u *trade.Order) bool {
+	ut := time.Time(u.UTime)
+	if ut.Before(o.UTime) {
+		return true
+	}
+	if ut.Equal(o.UTime) && float64(u.AccFillSz) < o.AccFillSz {
+		return true
+	}
+	return false
+}
+
+// apply merges an exchange update into the record and returns the new fill it carried, if any
+func (o *Order) apply(u *trade.Order) *Fill {
+	if o.isStale(u) || !CanTransition(o.State, u.State) {
+		return o.addFill(u)
+	}
+	if o.OrdID == "" {
+		o.OrdID = u.OrdID
+	}
+	if o.InstID == "" {
+		o.InstID = u.InstID
+	}
+	if u.Px > 0 {
+		o.Px = float64(u.Px)
+	}
+	if u.Sz > 0 {
+		o.Sz = float64(u.Sz)
+	}
+	if u.Tag != "" {
+		o.Tag = u.Tag
+	}
+	o.TdMode = u.TdMode
+	o.Side = u.Side
+	o.PosSide = u.PosSide
+	o.OrdType = u.OrdType
+	o.InstType = u.InstType
+	o.FeeCcy = u.FeeCcy
+	o.Fee = float64(u.Fee)
+	if o.CTime.IsZero() {
+		o.CTime = time.Time(u.CTime)
+	}
+	if ut := time.Time(u.UTime); ut.After(o.UTime) {
+		o.UTime = ut
+	}
+	if float64(u.AccFillSz) >= o.AccFillSz {
+		o.AccFillSz = float64(u.AccFillSz)
+		o.AvgPx = float64(u.AvgPx)
+	}
+	o.State = u.State
+	return o.addFill(u)
+}
+
+// addFill records the execution carried by u unless it was already seen
+func (o *Order) addFill(u *trade.Order) *Fill {
+	if u.TradeID == "" || float64(u.FillSz) == 0 || o.hasFill(u.TradeID) {
+		return nil
+	}
+	f := &Fill{
+		TradeID:  u.TradeID,
+		FillPx:   float64(u.FillPx),
+		FillSz:   float64(u.FillSz),
+		Fee:      float64(u.FillFee),
+		FeeCcy:   u.FillFeeCcy,
+		ExecType: u.ExecType,
+		TS:       time.UnixMilli(int64(u.FillTime)),
+	}
+	o.Fills = append(o.Fills, f)
+	return f
+}
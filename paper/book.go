@@ -0,0 +1,95 @@
+package paper
+
+import (
+	"sort"
+
+	"github.com/yitech/okex/models/market"
+)
+
+type (
+	level struct {
+		px float64
+		sz float64
+	}
+
+	// book is the local copy of an order book. Liquidity taken by simulated orders is removed until the next update.
+	book struct {
+		bids []*level
+		asks []*level
+		last float64
+	}
+)
+
+func (b *book) apply(bids, asks []*market.OrderBookEntity, snapshot bool) {
+	if snapshot {
+		b.bids, b.asks = nil, nil
+	}
+	b.bids = merge(b.bids, bids, true)
+	b.asks = merge(b.asks, asks, false)
+}
+
+// side returns the levels a taker on the given side trades against
+func (b *book) side(buy bool) []*level {
+	if buy {
+		return b.asks
+	}
+	return b.bids
+}
+
+// level returns the level at px on the bid (buy) or ask side
+func (b *book) level(buy bool, px float64) *level {
+	ls := b.asks
+	if buy {
+		ls = b.bids
+	}
+	for _, l := range ls {
+		if l.px == px {
+			return l
+		}
+	}
+	return nil
+}
+
+// take removes sz from the level at px
+func (b *book) take(buy bool, l *level, sz float64) {
+	l.sz -= sz
+	if l.sz > 1e-12 {
+		return
+	}
+	ls := b.side(buy)
+	for i, v := range ls {
+		if v == l {
+			ls = append(ls[:i:i], ls[i+1:]...)
+			break
+		}
+	}
+	if buy {
+		b.asks = ls
+	} else {
+		b.bids = ls
+	}
+}
+
+func merge(ls []*level, es []*market.OrderBookEntity, desc bool) []*level {
+	for _, e := range es {
+		i := sort.Search(len(ls), func(i int) bool {
+			if desc {
+				return ls[i].px <= e.DepthPrice
+			}
+			return ls[i].px >= e.DepthPrice
+		})
+		found := i < len(ls) && ls[i].px == e.DepthPrice
+		switch {
+		case e.Size == 0 && found:
+			ls = append(ls[:i], ls[i+1:]...)
+		case e.Size == 0:
+		case found:
+			ls[i].sz = e.Size
+		default:
+			ls = append(ls, nil)
+			copy(ls[i+1:], ls[i:])
+			ls[i] = &level{px: e.DepthPrice, sz: e.Size}
+		}
+	}
+	return ls
+}
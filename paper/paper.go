@@ -0,0 +1,598 @@
+// Package paper simulates the trade endpoints locally, matching orders against the public order book and trades feeds.
+//
+// Exchange implements api.TradeAPI, so a strategy can switch between paper and live trading by swapping the
+// implementation. Algo orders are not simulated. Order and position updates are pushed as private.Order and private.Position events.
+// Positions are kept in net mode, balances are not simulated.
+package paper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/fee"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/models/trade"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+)
+
+type (
+	// OrderHandler is called with every order update
+	OrderHandler func(e *private.Order)
+
+	// PositionHandler is called with every position update
+	PositionHandler func(e *private.Position)
+
+	// Fee rates of an instrument type, negative rates are charged and positive ones are rebates
+	Fee = fee.Rate
+
+	order struct {
+		*trade.Order
+		// quote is set on market orders sized in quote currency
+		quote  bool
+		active bool
+		seq    int64
+		// ahead is the size queued before the order at its price
+		ahead float64
+	}
+
+	action struct {
+		at time.Time
+		fn func(now time.Time)
+	}
+
+	// Exchange is a simulated exchange
+	Exchange struct {
+		mu          sync.Mutex
+		now         func() time.Time
+		latency     time.Duration
+		queue       bool
+		fees        *fee.Model
+		instruments map[string]*publicdata.Instrument
+		books       map[string]*book
+		orders      map[string]*order
+		clOrdIDs    map[string]string
+		positions   map[string]*account.Position
+		fills       []*trade.TransactionDetail
+		actions     []action
+		cancelAt    time.Time
+		seq         int64
+		onOrder     []OrderHandler
+		onPosition  []PositionHandler
+		outOrders   []*trade.Order
+		outPos      []*account.Position
+	}
+)
+
+// NewExchange returns a pointer to a fresh Exchange without latency nor fees, running on the wall clock
+func NewExchange() *Exchange {
+	return &Exchange{
+		now:         time.Now,
+		fees:        fee.NewModel(nil),
+		instruments: make(map[string]*publicdata.Instrument),
+		books:       make(map[string]*book),
+		orders:      make(map[string]*order),
+		clOrdIDs:    make(map[string]string),
+		positions:   make(map[string]*account.Position),
+		seq:         time.Now().UnixMilli() * 1000,
+	}
+}
+
+// SetClock sets the time source, e.g. the timestamps of a replayed feed. Before the first order, the IDs are reseeded
+// from it so that replays are reproducible.
+func (e *Exchange) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+	if len(e.orders) == 0 {
+		e.seq = now().UnixMilli() * 1000
+	}
+}
+
+// SetLatency sets the delay before placements, cancellations and amendments reach the simulated book
+func (e *Exchange) SetLatency(d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.latency = d
+}
+
+// SetQueuePosition makes resting orders wait behind the size shown at their price when they were placed. Without it
+// they are filled by the first trade at their price.
+func (e *Exchange) SetQueuePosition(on bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queue = on
+}
+
+// SetFeeModel replaces the fee model, e.g. with one shared with the reports. The instruments set so far are registered
+// with it.
+func (e *Exchange) SetFeeModel(m *fee.Model) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fees = m
+	for _, i := range e.instruments {
+		m.SetInstrument(i)
+	}
+}
+
+// FeeModel returns the fee model charging the fills
+func (e *Exchange) FeeModel() *fee.Model {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.fees
+}
+
+// SetFee sets the fee rates of an instrument type
+func (e *Exchange) SetFee(t okex.InstrumentType, f Fee) {
+	e.FeeModel().SetRate(t, "", f)
+}
+
+// LoadFees takes the fee rates of the account for the given instrument types
+func (e *Exchange) LoadFees(a api.AccountAPI, types ...okex.InstrumentType) error {
+	for _, t := range types {
+		res, err := a.GetFeeRates(accountRequests.GetFeeRatesRequest{InstType: t})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("paper: get fee rates failed: %d %s", res.Code, res.Msg)
+		}
+		for _, f := range res.Fees {
+			e.SetFee(t, fee.RateOf(f))
+		}
+	}
+	return nil
+}
+
+// SetInstrument sets the contract value, contract type and currencies used for fees and PnL of an instrument
+func (e *Exchange) SetInstrument(i *publicdata.Instrument) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.instruments[i.InstID] = i
+	e.fees.SetInstrument(i)
+}
+
+// OnOrder registers a handler for order updates
+func (e *Exchange) OnOrder(h OrderHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onOrder = append(e.onOrder, h)
+}
+
+// OnPosition registers a handler for position updates
+func (e *Exchange) OnPosition(h PositionHandler) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onPosition = append(e.onPosition, h)
+}
+
+// HandleOrderBook updates the book from a `books` channel push and fills the resting orders it crosses
+func (e *Exchange) HandleOrderBook(ev *public.OrderBook) {
+	instID := ev.InstID
+	if v, ok := ev.Arg.Get("instId"); ok && instID == "" {
+		instID, _ = v.(string)
+	}
+	e.do(func(now time.Time) {
+		b := e.book(instID)
+		for _, d := range ev.Books {
+			b.apply(d.Bids, d.Asks, ev.Action != "update")
+		}
+		e.shrinkQueues(instID)
+		e.matchBook(instID, now)
+	})
+}
+
+// HandleTrades fills the resting orders a `trades` channel push trades through
+func (e *Exchange) HandleTrades(ev *public.Trades) {
+	e.do(func(now time.Time) {
+		for _, t := range ev.Trades {
+			e.book(t.InstID).last = float64(t.Px)
+			e.matchTrade(t.InstID, t.Side == okex.TradeBuySide, float64(t.Px), float64(t.Sz), now)
+		}
+	})
+}
+
+// Advance applies the placements, cancellations and amendments that are due
+func (e *Exchange) Advance() {
+	e.do(func(time.Time) {})
+}
+
+// Run calls Advance at the given interval until the context is done, for feeds too sparse to drive the latency queue
+func (e *Exchange) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.Advance()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// do runs fn under the lock between two passes over the latency queue, then pushes the resulting events
+func (e *Exchange) do(fn func(now time.Time)) {
+	e.mu.Lock()
+	now := e.now()
+	e.advance(now)
+	fn(now)
+	e.advance(now)
+	os, ps := e.outOrders, e.outPos
+	e.outOrders, e.outPos = nil, nil
+	oh, ph := e.onOrder, e.onPosition
+	e.mu.Unlock()
+
+	if len(os) > 0 {
+		ev := &private.Order{Arg: events.NewArgument(map[string]interface{}{"channel": "orders", "instType": "ANY"}), Orders: os}
+		for _, h := range oh {
+			h(ev)
+		}
+	}
+	if len(ps) > 0 {
+		ev := &private.Position{Arg: events.NewArgument(map[string]interface{}{"channel": "positions", "instType": "ANY"}), Positions: ps}
+		for _, h := range ph {
+			h(ev)
+		}
+	}
+}
+
+func (e *Exchange) advance(now time.Time) {
+	for len(e.actions) > 0 && !e.actions[0].at.After(now) {
+		a := e.actions[0]
+		e.actions = e.actions[1:]
+		a.fn(now)
+	}
+	if !e.cancelAt.IsZero() && !now.Before(e.cancelAt) {
+		e.cancelAt = time.Time{}
+		os := make([]*order, 0, len(e.orders))
+		for _, o := range e.orders {
+			os = append(os, o)
+		}
+		for _, o := range bySeq(os) {
+			e.cancel(o, now)
+		}
+	}
+}
+
+// schedule queues fn after the latency, keeping the queue in time order
+func (e *Exchange) schedule(now time.Time, fn func(now time.Time)) {
+	a := action{at: now.Add(e.latency), fn: fn}
+	i := len(e.actions)
+	for i > 0 && e.actions[i-1].at.After(a.at) {
+		i--
+	}
+	e.actions = append(e.actions, action{})
+	copy(e.actions[i+1:], e.actions[i:])
+	e.actions[i] = a
+}
+
+func (e *Exchange) book(instID string) *book {
+	b, ok := e.books[instID]
+	if !ok {
+		b = &book{}
+		e.books[instID] = b
+	}
+	return b
+}
+
+func (e *Exchange) nextID() string {
+	e.seq++
+	return strconv.FormatInt(e.seq, 10)
+}
+
+// activate puts an accepted order on the simulated book
+func (e *Exchange) activate(o *order, now time.Time) {
+	if !o.open() {
+		return
+	}
+	o.active = true
+	o.UTime = okex.JSONTime(now)
+	e.push(o)
+	e.take(o, now)
+}
+
+// take matches an order as taker against the book and handles whatever is left according to its type
+func (e *Exchange) take(o *order, now time.Time) {
+	b := e.book(o.InstID)
+	buy := o.Side == okex.OrderBuy
+	px := float64(o.Px)
+	market := o.OrdType == okex.OrderMarket || o.OrdType == okex.OrderOptimalLimitIoc
+	crosses := func(l *level) bool {
+		return market || (buy && l.px <= px) || (!buy && l.px >= px)
+	}
+
+	switch o.OrdType {
+	case okex.OrderPostOnly:
+		if ls := b.side(buy); len(ls) > 0 && crosses(ls[0]) {
+			e.cancel(o, now)
+			return
+		}
+		e.enqueue(o)
+		return
+	case okex.OrderFOK:
+		// orders sized in quote currency compare the notional the book can fill
+		var avail float64
+		want := o.remaining(0)
+		if o.quote {
+			want = math.Max(0, float64(o.Sz)-float64(o.AvgPx)*float64(o.AccFillSz))
+		}
+		for _, l := range b.side(buy) {
+			if !crosses(l) {
+				break
+			}
+			if o.quote {
+				avail += l.sz * l.px
+			} else {
+				avail += l.sz
+			}
+		}
+		if avail < want-1e-12 {
+			e.cancel(o, now)
+			return
+		}
+	}
+
+	for _, l := range append([]*level(nil), b.side(buy)...) {
+		rem := o.remaining(l.px)
+		if rem <= 1e-12 || !crosses(l) {
+			break
+		}
+		sz := math.Min(rem, l.sz)
+		b.take(buy, l, sz)
+		e.fill(o, l.px, sz, false, now)
+	}
+
+	switch o.OrdType {
+	case okex.OrderLimit:
+		e.enqueue(o)
+	default:
+		e.cancel(o, now)
+	}
+}
+
+// enqueue puts a resting order behind the size shown at its price
+func (e *Exchange) enqueue(o *order) {
+	o.ahead = 0
+	if !e.queue || !o.open() {
+		return
+	}
+	if l := e.book(o.InstID).level(o.Side == okex.OrderBuy, float64(o.Px)); l != nil {
+		o.ahead = l.sz
+	}
+}
+
+// shrinkQueues moves the resting orders up when the size shown at their price drops
+func (e *Exchange) shrinkQueues(instID string) {
+	b := e.book(instID)
+	for _, o := range e.resting(instID) {
+		if o.ahead == 0 {
+			continue
+		}
+		var sz float64
+		if l := b.level(o.Side == okex.OrderBuy, float64(o.Px)); l != nil {
+			sz = l.sz
+		}
+		o.ahead = math.Min(o.ahead, sz)
+	}
+}
+
+// matchBook fills the resting orders crossed by the book as maker, at their own price
+func (e *Exchange) matchBook(instID string, now time.Time) {
+	b := e.book(instID)
+	for _, o := range e.resting(instID) {
+		buy := o.Side == okex.OrderBuy
+		px := float64(o.Px)
+		for _, l := range append([]*level(nil), b.side(buy)...) {
+			rem := o.remaining(px)
+			if rem <= 1e-12 || (buy && l.px > px) || (!buy && l.px < px) {
+				break
+			}
+			sz := math.Min(rem, l.sz)
+			b.take(buy, l, sz)
+			e.fill(o, px, sz, true, now)
+		}
+	}
+}
+
+// matchTrade fills the resting orders a public trade went through, in time priority
+func (e *Exchange) matchTrade(instID string, takerBuy bool, px, sz float64, now time.Time) {
+	for _, o := range e.resting(instID) {
+		if sz <= 1e-12 {
+			return
+		}
+		buy := o.Side == okex.OrderBuy
+		opx := float64(o.Px)
+		if buy == takerBuy || (buy && px > opx) || (!buy && px < opx) {
+			continue
+		}
+		avail := sz
+		if px == opx {
+			avail = math.Max(0, sz-o.ahead)
+			o.ahead = math.Max(0, o.ahead-sz)
+		}
+		n := math.Min(o.remaining(opx), avail)
+		sz -= n
+		e.fill(o, opx, n, true, now)
+	}
+}
+
+// resting returns the active open orders of an instrument in time priority
+func (e *Exchange) resting(instID string) []*order {
+	var res []*order
+	for _, o := range e.orders {
+		if o.InstID == instID && o.active && o.open() {
+			res = append(res, o)
+		}
+	}
+	return bySeq(res)
+}
+
+// bySeq sorts orders in time priority
+func bySeq(os []*order) []*order {
+	sort.Slice(os, func(i, j int) bool { return os[i].seq < os[j].seq })
+	return os
+}
+
+func (e *Exchange) fill(o *order, px, sz float64, maker bool, now time.Time) {
+	if sz <= 0 {
+		return
+	}
+	inst := e.instruments[o.InstID]
+	flow := okex.OrderTakerFlow
+	if maker {
+		flow = okex.OrderMakerFlow
+	}
+	est := e.fees.Estimate(fee.Order{InstID: o.InstID, InstType: o.InstType, Side: o.Side, Px: px, Sz: sz, Maker: maker})
+	paid, feeCcy := est.Fee, est.Ccy
+
+	acc := float64(o.AccFillSz)
+	o.AvgPx = okex.JSONFloat64((float64(o.AvgPx)*acc + px*sz) / (acc + sz))
+	o.AccFillSz = okex.JSONFloat64(acc + sz)
+	o.FillPx = okex.JSONFloat64(px)
+	o.FillSz = okex.JSONFloat64(sz)
+	o.FillTime = okex.JSONFloat64(now.UnixMilli())
+	o.FillFee = okex.JSONFloat64(paid)
+	o.FillFeeCcy = feeCcy
+	o.ExecType = flow
+	o.Fee += okex.JSONFloat64(paid)
+	o.FeeCcy = feeCcy
+	o.TradeID = e.nextID()
+	o.State = okex.OrderPartiallyFilled
+	if o.remaining(px) <= 1e-12 {
+		o.State = okex.OrderFilled
+	}
+	o.UTime = okex.JSONTime(now)
+
+	e.fills = append(e.fills, &trade.TransactionDetail{
+		InstID:   o.InstID,
+		OrdID:    o.OrdID,
+		TradeID:  o.TradeID,
+		ClOrdID:  o.ClOrdID,
+		BillID:   e.nextID(),
+		FillPx:   okex.JSONFloat64(px),
+		FillSz:   okex.JSONFloat64(sz),
+		FeeCcy:   feeCcy,
+		Fee:      okex.JSONFloat64(paid),
+		InstType: o.InstType,
+		Side:     o.Side,
+		PosSide:  o.PosSide,
+		ExecType: flow,
+		TS:       okex.JSONTime(now),
+	})
+	e.push(o)
+	if o.TdMode != okex.TradeCashMode {
+		e.position(o, inst, px, sz, now)
+	}
+}
+
+// position applies a fill to the net position of the instrument
+func (e *Exchange) position(o *order, inst *publicdata.Instrument, px, sz float64, now time.Time) {
+	k := o.InstID + "/" + string(o.TdMode)
+	p, ok := e.positions[k]
+	if !ok {
+		p = &account.Position{
+			InstID:   o.InstID,
+			PosID:    e.nextID(),
+			PosSide:  okex.PositionNetSide,
+			MgnMode:  okex.MarginMode(o.TdMode),
+			InstType: o.InstType,
+			CTime:    okex.JSONTime(now),
+		}
+		e.positions[k] = p
+	}
+	ctVal, inverse, ccy := contract(inst)
+	q := sz
+	if o.Side == okex.OrderSell {
+		q = -sz
+	}
+	pos, avg := float64(p.Pos), float64(p.AvgPx)
+	switch {
+	case pos == 0 || (pos > 0) == (q > 0):
+		if inverse {
+			avg = (pos + q) / (pos/nz(avg) + q/px)
+		} else {
+			avg = (pos*avg + q*px) / (pos + q)
+		}
+	case math.Abs(q) > math.Abs(pos):
+		avg = px
+	}
+	pos += q
+	if math.Abs(pos) <= 1e-12 {
+		pos, avg = 0, 0
+	}
+	p.Pos = okex.JSONFloat64(pos)
+	p.AvailPos = okex.JSONFloat64(math.Abs(pos))
+	p.AvgPx = okex.JSONFloat64(avg)
+	p.Ccy = ccy
+	p.Last = okex.JSONFloat64(px)
+	p.Upl = 0
+	if pos != 0 {
+		if inverse {
+			p.Upl = okex.JSONFloat64((1/avg - 1/px) * pos * ctVal)
+		} else {
+			p.Upl = okex.JSONFloat64((px - avg) * pos * ctVal)
+		}
+	}
+	p.TradeID = o.TradeID
+	p.UTime = okex.JSONTime(now)
+	c := *p
+	e.outPos = append(e.outPos, &c)
+}
+
+func (e *Exchange) cancel(o *order, now time.Time) {
+	if !o.open() {
+		return
+	}
+	o.State = okex.OrderCancel
+	o.UTime = okex.JSONTime(now)
+	e.push(o)
+}
+
+func (e *Exchange) push(o *order) {
+	c := *o.Order
+	e.outOrders = append(e.outOrders, &c)
+}
+
+func (o *order) open() bool {
+	return o.State == okex.OrderLive || o.State == okex.OrderPartiallyFilled
+}
+
+// remaining returns the unfilled size in base units, quote sized orders are converted at px
+func (o *order) remaining(px float64) float64 {
+	if o.quote {
+		if px <= 0 {
+			return 0
+		}
+		return math.Max(0, float64(o.Sz)-float64(o.AvgPx)*float64(o.AccFillSz)) / px
+	}
+	return math.Max(0, float64(o.Sz-o.AccFillSz))
+}
+
+func contract(inst *publicdata.Instrument) (ctVal float64, inverse bool, ccy string) {
+	if inst == nil {
+		return 1, false, ""
+	}
+	ctVal = float64(inst.CtVal)
+	if ctVal == 0 {
+		ctVal = 1
+	}
+	return ctVal, inst.CtType == okex.ContractInverseType, inst.SettleCcy
+}
+
+func nz(v float64) float64 {
+	if v == 0 {
+		return math.Inf(1)
+	}
+	return v
+}
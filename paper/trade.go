@@ -0,0 +1,423 @@
+package paper
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/trade"
+	requests "github.com/yitech/okex/requests/rest/trade"
+	responses "github.com/yitech/okex/responses/trade"
+)
+
+const errAlgo = "paper: algo orders are not simulated"
+
+var _ api.TradeAPI = (*Exchange)(nil)
+
+// Error codes returned by the simulated endpoints, as documented by the exchange
+const (
+	CodeParameter        = 51000
+	CodeDuplicateClOrdID = 51016
+	CodeCancelFailed     = 51400
+	CodeAmendFailed      = 51503
+	CodeNoOrder          = 51603
+	CodePositionNotExist = 51023
+	CodeReduceOnly       = 51169
+)
+
+// PlaceOrder accepts the orders, they reach the book after the latency
+func (e *Exchange) PlaceOrder(req []requests.PlaceOrderRequest) (response responses.PlaceOrderResponse, err error) {
+	e.do(func(now time.Time) {
+		for _, r := range req {
+			response.Orders = append(response.Orders, e.place(r, now))
+		}
+	})
+	var failed int
+	for _, o := range response.Orders {
+		if o.SCode != 0 {
+			failed++
+		}
+	}
+	response.Code, response.Msg = code(failed, len(req))
+	return
+}
+
+// PlaceMultipleOrders accepts the orders, they reach the book after the latency
+func (e *Exchange) PlaceMultipleOrders(req []requests.PlaceOrderRequest) (response responses.PlaceOrderResponse, err error) {
+	return e.PlaceOrder(req)
+}
+
+// CancelOrder cancels an order once the latency has passed, unless it is filled in the meantime
+func (e *Exchange) CancelOrder(req requests.CancelOrderRequest) (response responses.CancelOrderResponse, err error) {
+	return e.CancelBatchOrders([]requests.CancelOrderRequest{req})
+}
+
+// CancelBatchOrders cancels orders once the latency has passed, unless they are filled in the meantime
+func (e *Exchange) CancelBatchOrders(req []requests.CancelOrderRequest) (response responses.CancelOrderResponse, err error) {
+	var failed int
+	e.do(func(now time.Time) {
+		for _, r := range req {
+			res := &trade.CancelOrder{OrdID: r.OrdId, ClOrdID: r.ClOrdId}
+			o, ok := e.lookup(r.OrdId, r.ClOrdId)
+			switch {
+			case !ok || o.InstID != r.InstID:
+				res.SCode, res.SMsg = CodeNoOrder, "Order does not exist"
+			case !o.open():
+				res.SCode, res.SMsg = CodeCancelFailed, "Order has been completed"
+			default:
+				res.OrdID, res.ClOrdID = o.OrdID, o.ClOrdID
+				e.schedule(now, func(now time.Time) {
+					e.cancel(o, now)
+				})
+			}
+			if res.SCode != 0 {
+				failed++
+			}
+			response.Orders = append(response.Orders, res)
+		}
+	})
+	response.Code, response.Msg = code(failed, len(req))
+	return
+}
+
+// AmendOrder changes the price or size of orders once the latency has passed. An amended price crossing the book
+// trades as taker.
+func (e *Exchange) AmendOrder(req []requests.AmendOrderRequest) (response responses.AmendOrderResponse, err error) {
+	var failed int
+	e.do(func(now time.Time) {
+		for _, r := range req {
+			res := &trade.AmendOrder{OrdID: r.OrdId, ClOrdID: r.ClOrdId, ReqID: r.ReqId}
+			px, pxErr := parse(r.NewPx)
+			sz, szErr := parse(r.NewSz)
+			o, ok := e.lookup(r.OrdId, r.ClOrdId)
+			switch {
+			case !ok || o.InstID != r.InstID:
+				res.SCode, res.SMsg = CodeNoOrder, "Order does not exist"
+			case pxErr != nil || szErr != nil || (px == 0 && sz == 0) || px < 0 || sz < 0:
+				res.SCode, res.SMsg = CodeParameter, "Parameter error"
+			case !o.open() || o.quote || o.OrdType == okex.OrderMarket:
+				res.SCode, res.SMsg = CodeAmendFailed, "Order cannot be amended"
+			default:
+				res.OrdID, res.ClOrdID = o.OrdID, o.ClOrdID
+				cxl := r.CxlOnFail
+				e.schedule(now, func(now time.Time) {
+					e.amend(o, px, sz, cxl, now)
+				})
+			}
+			if res.SCode != 0 {
+				failed++
+			}
+			response.Orders = append(response.Orders, res)
+		}
+	})
+	response.Code, response.Msg = code(failed, len(req))
+	return
+}
+
+// ClosePosition closes the position of an instrument with a market order
+func (e *Exchange) ClosePosition(req requests.ClosePositionRequest) (response responses.ClosePositionResponse, err error) {
+	e.do(func(now time.Time) {
+		p, ok := e.positions[req.InstID+"/"+string(req.MgnMode)]
+		if !ok || p.Pos == 0 {
+			response.Code, response.Msg = CodePositionNotExist, "Position does not exist"
+			return
+		}
+		side := okex.OrderSell
+		if p.Pos < 0 {
+			side = okex.OrderBuy
+		}
+		res := e.place(requests.PlaceOrderRequest{
+			InstID:  req.InstID,
+			TdMode:  req.MgnMode,
+			Side:    side,
+			OrdType: okex.OrderMarket,
+			Sz:      strconv.FormatFloat(float64(p.AvailPos), 'f', -1, 64),
+		}, now)
+		if res.SCode != 0 {
+			response.Code, response.Msg = int(res.SCode), res.SMsg
+			return
+		}
+		response.Positions = append(response.Positions, &trade.ClosePosition{InstID: req.InstID, PosSide: p.PosSide})
+	})
+	return
+}
+
+// GetOrderDetail returns a single order in any state
+func (e *Exchange) GetOrderDetail(req requests.OrderDetailsRequest) (response responses.OrderListResponse, err error) {
+	e.do(func(time.Time) {
+		o, ok := e.lookup(req.OrdId, req.ClOrdId)
+		if !ok || o.InstID != req.InstID {
+			response.Code, response.Msg = CodeNoOrder, "Order does not exist"
+			return
+		}
+		c := *o.Order
+		response.Orders = append(response.Orders, &c)
+	})
+	return
+}
+
+// GetOrderList returns the open orders, newest first
+func (e *Exchange) GetOrderList(req requests.OrderListRequest) (response responses.OrderListResponse, err error) {
+	e.do(func(time.Time) {
+		response.Orders = e.list(req, true)
+	})
+	return
+}
+
+// GetOrderHistory returns the completed orders, newest first. The archive flag is ignored.
+func (e *Exchange) GetOrderHistory(req requests.OrderListRequest, _ bool) (response responses.OrderListResponse, err error) {
+	e.do(func(time.Time) {
+		response.Orders = e.list(req, false)
+	})
+	return
+}
+
+func (e *Exchange) list(req requests.OrderListRequest, open bool) []*trade.Order {
+	var os []*order
+	for _, o := range e.orders {
+		id, _ := strconv.ParseInt(o.OrdID, 10, 64)
+		switch {
+		case o.open() != open:
+		case req.InstType != "" && o.InstType != req.InstType:
+		case req.InstID != "" && o.InstID != req.InstID:
+		case req.Uly != "" && !strings.HasPrefix(o.InstID, req.Uly+"-"):
+		case req.OrdType != "" && o.OrdType != req.OrdType:
+		case req.State != "" && o.State != req.State:
+		case req.After != 0 && id >= req.After:
+		case req.Before != 0 && id <= req.Before:
+		default:
+			os = append(os, o)
+		}
+	}
+	sort.Slice(os, func(i, j int) bool { return os[i].seq > os[j].seq })
+	res := make([]*trade.Order, 0, min(len(os), limit(req.Limit)))
+	for _, o := range os[:min(len(os), limit(req.Limit))] {
+		c := *o.Order
+		res = append(res, &c)
+	}
+	return res
+}
+
+// GetTransactionDetails returns the fills, newest first. The archive flag is ignored.
+func (e *Exchange) GetTransactionDetails(req requests.TransactionDetailsRequest, _ bool) (response responses.TransactionDetailResponse, err error) {
+	e.do(func(time.Time) {
+		for i := len(e.fills) - 1; i >= 0 && len(response.Transactions) < limit(req.Limit); i-- {
+			f := e.fills[i]
+			id, _ := strconv.ParseInt(f.BillID, 10, 64)
+			switch {
+			case req.InstID != "" && f.InstID != req.InstID:
+			case req.OrdId != "" && f.OrdID != req.OrdId:
+			case req.After != 0 && id >= req.After:
+			case req.Before != 0 && id <= req.Before:
+			default:
+				c := *f
+				response.Transactions = append(response.Transactions, &c)
+			}
+		}
+	})
+	return
+}
+
+// CancelAllAfter cancels every open order once the countdown expires, a zero timeout turns it off
+func (e *Exchange) CancelAllAfter(req requests.CancelAllAfterRequest) (response responses.CancelAllAfterResponse, err error) {
+	if req.TimeOut != 0 && (req.TimeOut < 10 || req.TimeOut > 120) {
+		response.Code, response.Msg = CodeParameter, "Parameter error"
+		return
+	}
+	e.do(func(now time.Time) {
+		e.cancelAt = time.Time{}
+		if req.TimeOut != 0 {
+			e.cancelAt = now.Add(time.Duration(req.TimeOut) * time.Second)
+		}
+		response.Results = append(response.Results, &trade.CancelAllAfter{
+			TriggerTime: okex.JSONTime(e.cancelAt),
+			TS:          okex.JSONTime(now),
+		})
+	})
+	return
+}
+
+// PlaceAlgoOrder is not simulated
+func (e *Exchange) PlaceAlgoOrder(requests.PlaceAlgoOrderRequest) (response responses.PlaceAlgoOrderResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// AmendAlgoOrder is not simulated
+func (e *Exchange) AmendAlgoOrder(requests.AmendAlgoOrderRequest) (response responses.AmendAlgoOrderResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// GetAlgoOrderDetail is not simulated
+func (e *Exchange) GetAlgoOrderDetail(requests.AlgoOrderDetailsRequest) (response responses.AlgoOrderListResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// CancelAlgoOrder is not simulated
+func (e *Exchange) CancelAlgoOrder(requests.CancelAlgoOrderRequest) (response responses.CancelAlgoOrderResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// CancelAlgoOrders is not simulated
+func (e *Exchange) CancelAlgoOrders([]requests.CancelAlgoOrderRequest) (response responses.CancelAlgoOrderResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// CancelAdvanceAlgoOrder is not simulated
+func (e *Exchange) CancelAdvanceAlgoOrder(requests.CancelAlgoOrderRequest) (response responses.CancelAlgoOrderResponse, err error) {
+	response.Code, response.Msg = 1, errAlgo
+	return
+}
+
+// GetAlgoOrderList always returns an empty list, algo orders are not simulated
+func (e *Exchange) GetAlgoOrderList(requests.AlgoOrderListRequest, bool) (response responses.AlgoOrderListResponse, err error) {
+	return
+}
+
+func (e *Exchange) place(r requests.PlaceOrderRequest, now time.Time) *trade.PlaceOrder {
+	res := &trade.PlaceOrder{ClOrdID: r.ClOrdId, Tag: r.Tag}
+	sz, szErr := parse(r.Sz)
+	px, pxErr := parse(r.Px)
+	priced := r.OrdType != okex.OrderMarket && r.OrdType != okex.OrderOptimalLimitIoc
+	if err := r.Validate(); err != nil {
+		res.SCode, res.SMsg = CodeParameter, err.Error()
+		return res
+	}
+	switch {
+	case szErr != nil || pxErr != nil || sz <= 0 || (priced && px <= 0):
+		res.SCode, res.SMsg = CodeParameter, "Parameter error"
+		return res
+	case r.PosSide == okex.PositionLongSide || r.PosSide == okex.PositionShortSide:
+		res.SCode, res.SMsg = CodeParameter, "Parameter posSide error, only net mode is simulated"
+		return res
+	}
+	if r.ReduceOnly {
+		// Only checked when placed, the size is not capped to the position
+		p, ok := e.positions[r.InstID+"/"+string(r.TdMode)]
+		if !ok || p.Pos == 0 || (p.Pos > 0) == (r.Side == okex.OrderBuy) {
+			res.SCode, res.SMsg = CodeReduceOnly, "No position to reduce"
+			return res
+		}
+	}
+	if r.ClOrdId != "" {
+		if o, ok := e.lookup("", r.ClOrdId); ok && o.open() {
+			res.SCode, res.SMsg = CodeDuplicateClOrdID, "Duplicated clOrdId"
+			return res
+		}
+	}
+	t := instType(r.InstID, r.TdMode)
+	if i, ok := e.instruments[r.InstID]; ok {
+		t = i.InstType
+	}
+	o := &order{
+		Order: &trade.Order{
+			InstID:   r.InstID,
+			OrdID:    e.nextID(),
+			ClOrdID:  r.ClOrdId,
+			Tag:      r.Tag,
+			Px:       okex.JSONFloat64(px),
+			Sz:       okex.JSONFloat64(sz),
+			State:    okex.OrderLive,
+			TdMode:   r.TdMode,
+			PosSide:  okex.PositionNetSide,
+			Side:     r.Side,
+			OrdType:  r.OrdType,
+			InstType: t,
+			TgtCcy:   r.TgtCcy,
+			CTime:    okex.JSONTime(now),
+			UTime:    okex.JSONTime(now),
+		},
+		quote: !priced && (r.TgtCcy == okex.QuantityQuoteCcy ||
+			(r.TgtCcy == "" && r.Side == okex.OrderBuy && t == okex.SpotInstrument)),
+	}
+	o.seq = e.seq
+	e.orders[o.OrdID] = o
+	if o.ClOrdID != "" {
+		e.clOrdIDs[o.ClOrdID] = o.OrdID
+	}
+	e.schedule(now, func(now time.Time) {
+		e.activate(o, now)
+	})
+	res.OrdID = o.OrdID
+	return res
+}
+
+func (e *Exchange) amend(o *order, px, sz float64, cxlOnFail bool, now time.Time) {
+	if !o.open() {
+		return
+	}
+	if sz != 0 && sz <= float64(o.AccFillSz) {
+		if cxlOnFail {
+			e.cancel(o, now)
+		}
+		return
+	}
+	if px != 0 {
+		o.Px = okex.JSONFloat64(px)
+	}
+	if sz != 0 {
+		o.Sz = okex.JSONFloat64(sz)
+	}
+	o.UTime = okex.JSONTime(now)
+	e.push(o)
+	if o.active {
+		// The order loses its place in the queue, as on the exchange
+		e.take(o, now)
+	}
+}
+
+func (e *Exchange) lookup(ordID, clOrdID string) (*order, bool) {
+	if ordID == "" {
+		ordID = e.clOrdIDs[clOrdID]
+	}
+	o, ok := e.orders[ordID]
+	return o, ok
+}
+
+// instType guesses the instrument type from the instrument ID when the instrument was not set
+func instType(instID string, mode okex.TradeMode) okex.InstrumentType {
+	parts := strings.Split(instID, "-")
+	switch {
+	case strings.HasSuffix(instID, "-SWAP"):
+		return okex.SwapInstrument
+	case len(parts) == 5:
+		return okex.OptionsInstrument
+	case len(parts) == 3:
+		return okex.FuturesInstrument
+	case mode != okex.TradeCashMode:
+		return okex.MarginInstrument
+	}
+	return okex.SpotInstrument
+}
+
+// code returns the top level code of a batch, one when everything failed and two when only a part did
+func code(failed, n int) (int, string) {
+	switch {
+	case failed == 0:
+		return 0, ""
+	case failed == n:
+		return 1, "Operation failed."
+	}
+	return 2, "Bulk operation partially succeeded."
+}
+
+func parse(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func limit(l int64) int {
+	if l <= 0 || l > 100 {
+		return 100
+	}
+	return int(l)
+}
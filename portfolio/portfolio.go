@@ -0,0 +1,464 @@
+// Package portfolio aggregates the balances, positions and equity of the master account, its funding account and
+// savings, and the sub-accounts into a consolidated snapshot valued in a reporting currency.
+//
+// Currencies are converted with index prices, directly when an index against the reporting currency exists and
+// through USD otherwise.
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/models/account"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	fundingRequests "github.com/yitech/okex/requests/rest/funding"
+	marketRequests "github.com/yitech/okex/requests/rest/market"
+	subAccountRequests "github.com/yitech/okex/requests/rest/subaccount"
+)
+
+// Wallet is the part of an account a holding sits in
+type Wallet string
+
+const (
+	WalletTrading = Wallet("trading")
+	WalletFunding = Wallet("funding")
+	WalletSavings = Wallet("savings")
+)
+
+type (
+	// Source is an account to aggregate. The master account, and sub-accounts with an API key of their own, set the
+	// Account and Funding clients. Other sub-accounts only set SubAcct, their trading balance is then read through
+	// the SubAccount client of the master, without positions.
+	Source struct {
+		Name    string
+		Account api.AccountAPI
+		Funding api.FundingAPI
+		SubAcct string
+	}
+
+	// Holding is the balance of a currency in a wallet. Trading balances are the equity, including unrealized PnL.
+	Holding struct {
+		Wallet Wallet
+		Ccy    string
+		Amount float64
+		// Value is the amount in the reporting currency, zero when the currency has no price
+		Value float64
+	}
+
+	// AccountSnapshot is the breakdown of a single account
+	AccountSnapshot struct {
+		Name      string
+		Equity    float64
+		Holdings  []*Holding
+		Positions []*account.Position
+		// Err is the error of the last refresh of the account, its holdings are then those of the refresh before
+		Err   error
+		UTime time.Time
+	}
+
+	// Snapshot is the consolidated view of every account
+	Snapshot struct {
+		Ccy      string
+		Equity   float64
+		Accounts []*AccountSnapshot
+		// Totals sums the holdings of every account and wallet per currency
+		Totals []*Holding
+		// Unpriced lists the currencies held without a price, they are left out of the equity
+		Unpriced []string
+		TS       time.Time
+	}
+
+	// SnapshotHandler is called with every new snapshot
+	SnapshotHandler func(s *Snapshot)
+
+	holdingKey struct {
+		wallet Wallet
+		ccy    string
+	}
+
+	// state is what is known of an account
+	state struct {
+		source    Source
+		holdings  map[holdingKey]float64
+		cash      map[string]float64
+		positions map[string]*account.Position
+		err       error
+		uTime     time.Time
+	}
+
+	// Portfolio holds the state of every account and the index prices
+	Portfolio struct {
+		ccy        string
+		market     api.MarketAPI
+		sub        api.SubAccountAPI
+		mu         sync.RWMutex
+		names      []string
+		states     map[string]*state
+		prices     map[string]float64
+		onSnapshot []SnapshotHandler
+	}
+)
+
+// NewPortfolio returns a pointer to a fresh Portfolio reporting in ccy. The SubAccount client of the master is only
+// needed by sources without clients of their own.
+func NewPortfolio(ccy string, m api.MarketAPI, sub api.SubAccountAPI) *Portfolio {
+	return &Portfolio{
+		ccy:    ccy,
+		market: m,
+		sub:    sub,
+		states: make(map[string]*state),
+		prices: make(map[string]float64),
+	}
+}
+
+// AddSource registers an account, replacing the one with the same name
+func (p *Portfolio) AddSource(s Source) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.states[s.Name]; !ok {
+		p.names = append(p.names, s.Name)
+	}
+	p.states[s.Name] = newState(s)
+}
+
+// OnSnapshot registers a handler for new snapshots
+func (p *Portfolio) OnSnapshot(h SnapshotHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onSnapshot = append(p.onSnapshot, h)
+}
+
+// SetIndexPrice sets the price of an index such as BTC-USD
+func (p *Portfolio) SetIndexPrice(instID string, px float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.prices[instID] = px
+}
+
+// HandleIndexTickers updates the index prices from an `index-tickers` channel push
+func (p *Portfolio) HandleIndexTickers(e *public.IndexTickers) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range e.Tickers {
+		p.prices[t.InstID] = float64(t.IdxPx)
+	}
+}
+
+// Refresh fetches the index prices and the balances and positions of every account, and publishes the snapshot.
+// Accounts failing to refresh keep their previous holdings and carry the error in the snapshot; the first error is
+// returned as well.
+func (p *Portfolio) Refresh() (*Snapshot, error) {
+	first := p.loadPrices()
+	p.mu.RLock()
+	states := make([]*state, 0, len(p.names))
+	for _, n := range p.names {
+		states = append(states, p.states[n])
+	}
+	p.mu.RUnlock()
+
+	for _, st := range states {
+		fresh := newState(st.source)
+		fresh.err = p.load(fresh)
+		p.mu.Lock()
+		if fresh.err == nil {
+			fresh.uTime = time.Now()
+			p.states[st.source.Name] = fresh
+		} else {
+			st.err = fresh.err
+		}
+		p.mu.Unlock()
+		if fresh.err != nil && first == nil {
+			first = fresh.err
+		}
+	}
+	return p.publish(), first
+}
+
+// Run refreshes the portfolio on every tick until the context is done. Refresh errors are reported in the snapshots.
+func (p *Portfolio) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_, _ = p.Refresh()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// HandleAccount applies an `account` channel push of the named account and publishes the snapshot
+func (p *Portfolio) HandleAccount(name string, e *private.Account) {
+	p.mu.Lock()
+	st, ok := p.states[name]
+	if ok {
+		for _, b := range e.Balances {
+			for _, d := range b.Details {
+				st.holdings[holdingKey{wallet: WalletTrading, ccy: d.Ccy}] = float64(d.Eq)
+				st.cash[d.Ccy] = float64(d.CashBal)
+			}
+			st.uTime = time.Time(b.UTime)
+		}
+	}
+	p.mu.Unlock()
+	if ok {
+		p.publish()
+	}
+}
+
+// HandleBalanceAndPosition applies a `balance_and_position` channel push of the named account and publishes the
+// snapshot. The push carries cash balances only, the unrealized PnL of the equity is kept until the next refresh or
+// `account` push.
+func (p *Portfolio) HandleBalanceAndPosition(name string, e *private.BalanceAndPosition) {
+	p.mu.Lock()
+	st, ok := p.states[name]
+	if ok {
+		for _, bp := range e.BalanceAndPositions {
+			for _, d := range bp.BalData {
+				k := holdingKey{wallet: WalletTrading, ccy: d.Ccy}
+				st.holdings[k] += float64(d.CashBal) - st.cash[d.Ccy]
+				st.cash[d.Ccy] = float64(d.CashBal)
+			}
+			for _, pos := range bp.PosData {
+				if pos.Pos == 0 {
+					delete(st.positions, positionKey(pos))
+					continue
+				}
+				st.positions[positionKey(pos)] = pos
+			}
+			st.uTime = time.Time(bp.PTime)
+		}
+	}
+	p.mu.Unlock()
+	if ok {
+		p.publish()
+	}
+}
+
+// Snapshot returns the consolidated view at the current prices
+func (p *Portfolio) Snapshot() *Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshot()
+}
+
+// Rate returns the price of a currency in the reporting currency
+func (p *Portfolio) Rate(ccy string) (float64, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rate(ccy)
+}
+
+func (p *Portfolio) publish() *Snapshot {
+	p.mu.RLock()
+	s := p.snapshot()
+	hs := p.onSnapshot
+	p.mu.RUnlock()
+	for _, h := range hs {
+		h(s)
+	}
+	return s
+}
+
+func (p *Portfolio) snapshot() *Snapshot {
+	s := &Snapshot{Ccy: p.ccy, TS: time.Now()}
+	totals := make(map[string]float64)
+	unpriced := make(map[string]bool)
+	for _, n := range p.names {
+		st := p.states[n]
+		as := &AccountSnapshot{Name: n, Err: st.err, UTime: st.uTime}
+		for k, amt := range st.holdings {
+			if amt == 0 {
+				continue
+			}
+			h := &Holding{Wallet: k.wallet, Ccy: k.ccy, Amount: amt}
+			if r, ok := p.rate(k.ccy); ok {
+				h.Value = amt * r
+			} else {
+				unpriced[k.ccy] = true
+			}
+			as.Equity += h.Value
+			as.Holdings = append(as.Holdings, h)
+			totals[k.ccy] += amt
+		}
+		sortHoldings(as.Holdings)
+		for _, pos := range st.positions {
+			c := *pos
+			as.Positions = append(as.Positions, &c)
+		}
+		sort.Slice(as.Positions, func(i, j int) bool {
+			return positionKey(as.Positions[i]) < positionKey(as.Positions[j])
+		})
+		s.Equity += as.Equity
+		s.Accounts = append(s.Accounts, as)
+	}
+	for ccy, amt := range totals {
+		h := &Holding{Ccy: ccy, Amount: amt}
+		if r, ok := p.rate(ccy); ok {
+			h.Value = amt * r
+		}
+		s.Totals = append(s.Totals, h)
+	}
+	sortHoldings(s.Totals)
+	for ccy := range unpriced {
+		s.Unpriced = append(s.Unpriced, ccy)
+	}
+	sort.Strings(s.Unpriced)
+	return s
+}
+
+// rate converts through the index against the reporting currency, or through USD
+func (p *Portfolio) rate(ccy string) (float64, bool) {
+	if ccy == p.ccy {
+		return 1, true
+	}
+	if px := p.prices[ccy+"-"+p.ccy]; px > 0 {
+		return px, true
+	}
+	usd := func(c string) float64 {
+		if c == "USD" {
+			return 1
+		}
+		return p.prices[c+"-USD"]
+	}
+	if a, b := usd(ccy), usd(p.ccy); a > 0 && b > 0 {
+		return a / b, true
+	}
+	return 0, false
+}
+
+func (p *Portfolio) loadPrices() error {
+	if p.market == nil {
+		return nil
+	}
+	quotes := []string{"USD"}
+	if p.ccy != "USD" {
+		quotes = append(quotes, p.ccy)
+	}
+	for _, q := range quotes {
+		res, err := p.market.GetIndexTickers(marketRequests.GetIndexTickersRequest{QuoteCcy: q})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("portfolio: get index tickers failed: %d %s", res.Code, res.Msg)
+		}
+		p.mu.Lock()
+		for _, t := range res.IndexTickers {
+			p.prices[t.InstID] = float64(t.IdxPx)
+		}
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// load fetches the holdings and positions of an account into a fresh state
+func (p *Portfolio) load(st *state) error {
+	s := st.source
+	switch {
+	case s.Account != nil:
+		res, err := s.Account.GetBalance(accountRequests.GetBalanceRequest{})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("portfolio: %s: get balance failed: %d %s", s.Name, res.Code, res.Msg)
+		}
+		st.addBalances(res.Balances)
+		ps, err := s.Account.GetPositions(accountRequests.GetPositionsRequest{})
+		if err != nil {
+			return err
+		}
+		if ps.Code != 0 {
+			return fmt.Errorf("portfolio: %s: get positions failed: %d %s", s.Name, ps.Code, ps.Msg)
+		}
+		for _, pos := range ps.Positions {
+			if pos.Pos != 0 {
+				st.positions[positionKey(pos)] = pos
+			}
+		}
+	case s.SubAcct != "":
+		if p.sub == nil {
+			return fmt.Errorf("portfolio: %s: sub-account client is not set", s.Name)
+		}
+		res, err := p.sub.GetBalance(subAccountRequests.GetBalance{SubAcct: s.SubAcct})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("portfolio: %s: get sub-account balance failed: %d %s", s.Name, res.Code, res.Msg)
+		}
+		st.addBalances(res.Balances)
+	}
+	if s.Funding == nil {
+		return nil
+	}
+	fb, err := s.Funding.GetBalance(fundingRequests.GetBalance{})
+	if err != nil {
+		return err
+	}
+	if fb.Code != 0 {
+		return fmt.Errorf("portfolio: %s: get funding balance failed: %d %s", s.Name, fb.Code, fb.Msg)
+	}
+	for _, b := range fb.Balances {
+		// the funding balances are strings in the model, an empty one is zero
+		bal, _ := strconv.ParseFloat(b.Bal, 64)
+		st.holdings[holdingKey{wallet: WalletFunding, ccy: b.Ccy}] += bal
+	}
+	sb, err := s.Funding.GetPiggyBankBalance(fundingRequests.GetPiggyBankBalance{})
+	if err != nil {
+		return err
+	}
+	if sb.Code != 0 {
+		return fmt.Errorf("portfolio: %s: get savings balance failed: %d %s", s.Name, sb.Code, sb.Msg)
+	}
+	for _, b := range sb.Balances {
+		st.holdings[holdingKey{wallet: WalletSavings, ccy: b.Ccy}] += float64(b.Amt)
+	}
+	return nil
+}
+
+func newState(s Source) *state {
+	return &state{
+		source:    s,
+		holdings:  make(map[holdingKey]float64),
+		cash:      make(map[string]float64),
+		positions: make(map[string]*account.Position),
+	}
+}
+
+func (st *state) addBalances(bs []*account.Balance) {
+	for _, b := range bs {
+		for _, d := range b.Details {
+			st.holdings[holdingKey{wallet: WalletTrading, ccy: d.Ccy}] += float64(d.Eq)
+			st.cash[d.Ccy] += float64(d.CashBal)
+		}
+	}
+}
+
+func positionKey(p *account.Position) string {
+	if p.PosID != "" {
+		return p.PosID
+	}
+	return p.InstID + "/" + string(p.MgnMode) + "/" + string(p.PosSide)
+}
+
+func sortHoldings(hs []*Holding) {
+	sort.Slice(hs, func(i, j int) bool {
+		if hs[i].Value != hs[j].Value {
+			return hs[i].Value > hs[j].Value
+		}
+		if hs[i].Ccy != hs[j].Ccy {
+			return hs[i].Ccy < hs[j].Ccy
+		}
+		return hs[i].Wallet < hs[j].Wallet
+	})
+}
@@ -0,0 +1,120 @@
+package position
+
+import (
+	"math"
+	"time"
+
+	"github.com/yitech/okex"
+)
+
+type (
+	// Key identifies a position in the book
+	Key struct {
+		InstID  string
+		MgnMode okex.MarginMode
+		PosSide okex.PositionSide
+	}
+
+	// Position is the locally reconciled state of a single position.
+	// Pos is signed, short positions are negative whatever the position mode is.
+	Position struct {
+		Key
+		InstType      okex.InstrumentType
+		Pos           float64
+		AvgPx         float64
+		MarkPx        float64
+		RealizedPnl   float64
+		UnrealizedPnl float64
+		Fee           float64
+		Funding       float64
+		UTime         time.Time
+	}
+
+	// Contract describes how sizes and prices of an instrument convert into PnL
+	Contract struct {
+		Multiplier float64
+		Inverse    bool
+	}
+)
+
+// TotalPnl returns realized and unrealized PnL including fees and funding
+func (p *Position) TotalPnl() float64 {
+	return p.RealizedPnl + p.UnrealizedPnl + p.Fee + p.Funding
+}
+
+// ExchangePos returns the size the way the exchange reports it for the position side
+func (p *Position) ExchangePos() float64 {
+	if p.PosSide == okex.PositionShortSide {
+		return -p.Pos
+	}
+	return p.Pos
+}
+
+// pnl returns the PnL of closing sz at px against entry, with dir the sign of the closed position
+func (c Contract) pnl(entry, px, sz, dir float64) float64 {
+	if entry == 0 || px == 0 {
+		return 0
+	}
+	if c.Inverse {
+		return sz * c.Multiplier * (1/entry - 1/px) * dir
+	}
+	return (px - entry) * sz * c.Multiplier * dir
+}
+
+// fill applies a signed execution and returns the realized PnL it produced
+func (p *Position) fill(c Contract, px, q float64) float64 {
+	if q == 0 {
+		return 0
+	}
+	if p.Pos == 0 || sign(p.Pos) == sign(q) {
+		n := math.Abs(p.Pos) + math.Abs(q)
+		if c.Inverse {
+			// Inverse contracts average the entry harmonically
+			p.AvgPx = n / (math.Abs(p.Pos)/nonZero(p.AvgPx) + math.Abs(q)/px)
+		} else {
+			p.AvgPx = (p.AvgPx*math.Abs(p.Pos) + px*math.Abs(q)) / n
+		}
+		p.Pos += q
+		return 0
+	}
+
+	closed := math.Min(math.Abs(q), math.Abs(p.Pos))
+	r := c.pnl(p.AvgPx, px, closed, sign(p.Pos))
+	p.RealizedPnl += r
+	prev := p.Pos
+	p.Pos += q
+	switch {
+	case math.Abs(p.Pos) < epsilon:
+		p.Pos = 0
+		p.AvgPx = 0
+	case sign(p.Pos) != sign(prev):
+		p.AvgPx = px
+	}
+	return r
+}
+
+// mark revalues the position at px
+func (p *Position) mark(c Contract, px float64) {
+	p.MarkPx = px
+	if p.Pos == 0 {
+		p.UnrealizedPnl = 0
+		return
+	}
+	p.UnrealizedPnl = c.pnl(p.AvgPx, px, math.Abs(p.Pos), sign(p.Pos))
+}
+
+const epsilon = 1e-12
+
+func sign(f float64) float64 {
+	if f < 0 {
+		return -1
+	}
+	return 1
+}
+
+func nonZero(f float64) float64 {
+	if f == 0 {
+		return math.Inf(1)
+	}
+	return f
+}
@@ -0,0 +1,386 @@
+// Package position keeps a locally reconciled book of positions built from fills, mark prices, fees and funding,
+// and cross-checks it against the positions reported by the exchange.
+package position
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/events/private"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/fee"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/models/trade"
+	"github.com/yitech/okex/oms"
+	requests "github.com/yitech/okex/requests/rest/account"
+)
+
+type (
+	// Drift describes a disagreement between the local book and the exchange
+	Drift struct {
+		Key
+		LocalPos      float64
+		ExchangePos   float64
+		LocalAvgPx    float64
+		ExchangeAvgPx float64
+		TS            time.Time
+	}
+
+	// DriftHandler is called for every position that disagrees with the exchange
+	DriftHandler func(d *Drift)
+
+	// Tracker is the book of positions per instrument, margin mode and position side
+	Tracker struct {
+		account   api.AccountAPI
+		mu        sync.RWMutex
+		positions map[Key]*Position
+		contracts map[string]Contract
+		seen      map[string]time.Time
+		onDrift   []DriftHandler
+		posTol    float64
+		pxTol     float64
+		fees      *fee.Model
+	}
+)
+
+// NewTracker returns a pointer to a fresh Tracker. The account client is only needed by Reconcile.
+func NewTracker(a api.AccountAPI) *Tracker {
+	return &Tracker{
+		account:   a,
+		positions: make(map[Key]*Position),
+		contracts: make(map[string]Contract),
+		seen:      make(map[string]time.Time),
+		posTol:    1e-9,
+		pxTol:     1e-6,
+	}
+}
+
+// SetTolerance sets the absolute size and relative price differences that are not reported as drift
+func (t *Tracker) SetTolerance(pos, px float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.posTol = pos
+	t.pxTol = px
+}
+
+// OnDrift registers a handler for drifts found by Reconcile and HandlePosition
+func (t *Tracker) OnDrift(h DriftHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onDrift = append(t.onDrift, h)
+}
+
+// SetFeeModel reconciles the fee of every applied fill against the model, whose handlers report the discrepancies
+func (t *Tracker) SetFeeModel(m *fee.Model) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fees = m
+}
+
+// SetInstrument registers the contract specification of an instrument. Instruments without one are treated as spot.
+func (t *Tracker) SetInstrument(i *publicdata.Instrument) {
+	c := Contract{Multiplier: 1}
+	if i.InstType == okex.SwapInstrument || i.InstType == okex.FuturesInstrument || i.InstType == okex.OptionsInstrument {
+		c.Multiplier = float64(i.CtVal)
+		if i.CtMult > 0 {
+			c.Multiplier *= float64(i.CtMult)
+		}
+		c.Inverse = i.CtType == okex.ContractInverseType
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.contracts[i.InstID] = c
+}
+
+// ApplyFill applies an execution to the position it belongs to. Fills are deduplicated by trade id.
+func (t *Tracker) ApplyFill(mgnMode okex.MarginMode, f *trade.TransactionDetail) {
+	if m := t.apply(mgnMode, f); m != nil {
+		m.Reconcile(f)
+	}
+}
+
+func (t *Tracker) apply(mgnMode okex.MarginMode, f *trade.TransactionDetail) *fee.Model {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.markSeen(f.InstID, f.TradeID, time.Time(f.TS)) {
+		return nil
+	}
+	p := t.position(Key{InstID: f.InstID, MgnMode: mgnMode, PosSide: posSide(f.PosSide)}, f.InstType)
+	c := t.contract(f.InstID)
+	q := float64(f.FillSz)
+	if f.Side == okex.OrderSell {
+		q = -q
+	}
+	p.fill(c, float64(f.FillPx), q)
+	p.Fee += float64(f.Fee)
+	if p.MarkPx > 0 {
+		p.mark(c, p.MarkPx)
+	}
+	p.UTime = time.Time(f.TS)
+	return t.fees
+}
+
+// HandleFill is an oms.FillHandler feeding the executions of the order manager into the book
+func (t *Tracker) HandleFill(o *oms.Order, f *oms.Fill) {
+	t.ApplyFill(okex.MarginMode(o.TdMode), &trade.TransactionDetail{
+		InstID:   o.InstID,
+		OrdID:    o.OrdID,
+		TradeID:  f.TradeID,
+		ClOrdID:  o.ClOrdID,
+		FillPx:   okex.JSONFloat64(f.FillPx),
+		FillSz:   okex.JSONFloat64(f.FillSz),
+		FeeCcy:   f.FeeCcy,
+		Fee:      okex.JSONFloat64(f.Fee),
+		InstType: o.InstType,
+		Side:     o.Side,
+		PosSide:  o.PosSide,
+		ExecType: f.ExecType,
+		TS:       okex.JSONTime(f.TS),
+	})
+}
+
+// HandleMarkPrice revalues the positions of the instruments in a `mark-price` channel push
+func (t *Tracker) HandleMarkPrice(e *public.MarkPrice) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, mp := range e.Prices {
+		c := t.contract(mp.InstID)
+		for k, p := range t.positions {
+			if k.InstID == mp.InstID {
+				p.mark(c, float64(mp.MarkPx))
+			}
+		}
+	}
+}
+
+// HandleBill books the funding fees found among account bills
+func (t *Tracker) HandleBill(b *account.Bill) {
+	if b.Type != okex.BillFundingFeeType {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.markSeen("bill", b.BillID, time.Time(b.TS)) {
+		return
+	}
+	var p *Position
+	for k, pp := range t.positions {
+		if k.InstID == b.InstID && k.MgnMode == b.MgnMode && pp.Pos != 0 {
+			p = pp
+			break
+		}
+	}
+	if p == nil {
+		p = t.position(Key{InstID: b.InstID, MgnMode: b.MgnMode, PosSide: okex.PositionNetSide}, b.InstType)
+	}
+	p.Funding += float64(b.BalChg)
+}
+
+// HandlePosition compares a `positions` channel snapshot against the book
+func (t *Tracker) HandlePosition(e *private.Position) {
+	t.notify(t.compare(e.Positions, false))
+}
+
+// Adopt overwrites the size and entry price of the book with the exchange position, e.g. at startup or after a drift
+func (t *Tracker) Adopt(ps ...*account.Position) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ep := range ps {
+		p := t.position(keyOf(ep), ep.InstType)
+		p.Pos = signedPos(ep)
+		p.AvgPx = float64(ep.AvgPx)
+		p.UTime = time.Time(ep.UTime)
+		if p.MarkPx > 0 {
+			p.mark(t.contract(ep.InstID), p.MarkPx)
+		}
+	}
+}
+
+// Reconcile fetches the positions from the exchange and reports every drift. Local positions missing on the exchange are reported as well.
+func (t *Tracker) Reconcile() ([]*Drift, error) {
+	if t.account == nil {
+		return nil, fmt.Errorf("position: account client is not set")
+	}
+	res, err := t.account.GetPositions(requests.GetPositionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("position: get positions failed: %d %s", res.Code, res.Msg)
+	}
+	d := t.compare(res.Positions, true)
+	t.notify(d)
+	return d, nil
+}
+
+// Run reconciles the book on every tick until the context is done
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := t.Reconcile(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Position returns a copy of a single position
+func (t *Tracker) Position(k Key) (*Position, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p, ok := t.positions[k]
+	if !ok {
+		return nil, false
+	}
+	c := *p
+	return &c, true
+}
+
+// Prune forgets the ids of the fills and bills older than before, which are no longer deduplicated
+func (t *Tracker) Prune(before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, ts := range t.seen {
+		if ts.Before(before) {
+			delete(t.seen, k)
+		}
+	}
+}
+
+// Positions returns a copy of every position of the book, sorted by instrument, margin mode and position side
+func (t *Tracker) Positions() []*Position {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	res := make([]*Position, 0, len(t.positions))
+	for _, p := range t.positions {
+		c := *p
+		res = append(res, &c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].Key, res[j].Key
+		if a.InstID != b.InstID {
+			return a.InstID < b.InstID
+		}
+		if a.MgnMode != b.MgnMode {
+			return a.MgnMode < b.MgnMode
+		}
+		return a.PosSide < b.PosSide
+	})
+	return res
+}
+
+func (t *Tracker) compare(ps []*account.Position, full bool) []*Drift {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var res []*Drift
+	found := make(map[Key]bool)
+	now := time.Now()
+	for _, ep := range ps {
+		k := keyOf(ep)
+		found[k] = true
+		d := &Drift{Key: k, ExchangePos: signedPos(ep), ExchangeAvgPx: float64(ep.AvgPx), TS: now}
+		if p, ok := t.positions[k]; ok {
+			d.LocalPos = p.Pos
+			d.LocalAvgPx = p.AvgPx
+		}
+		if t.drifted(d) {
+			res = append(res, d)
+		}
+	}
+	if !full {
+		return res
+	}
+	for k, p := range t.positions {
+		if found[k] || math.Abs(p.Pos) <= t.posTol {
+			continue
+		}
+		res = append(res, &Drift{Key: k, LocalPos: p.Pos, LocalAvgPx: p.AvgPx, TS: now})
+	}
+	return res
+}
+
+func (t *Tracker) drifted(d *Drift) bool {
+	if math.Abs(d.LocalPos-d.ExchangePos) > t.posTol {
+		return true
+	}
+	if d.ExchangePos == 0 || d.ExchangeAvgPx == 0 {
+		return false
+	}
+	return math.Abs(d.LocalAvgPx-d.ExchangeAvgPx) > t.pxTol*d.ExchangeAvgPx
+}
+
+func (t *Tracker) notify(d []*Drift) {
+	if len(d) == 0 {
+		return
+	}
+	t.mu.RLock()
+	hs := t.onDrift
+	t.mu.RUnlock()
+	for _, dd := range d {
+		for _, h := range hs {
+			h(dd)
+		}
+	}
+}
+
+func (t *Tracker) position(k Key, instType okex.InstrumentType) *Position {
+	p, ok := t.positions[k]
+	if !ok {
+		p = &Position{Key: k, InstType: instType}
+		t.positions[k] = p
+	}
+	return p
+}
+
+func (t *Tracker) contract(instID string) Contract {
+	if c, ok := t.contracts[instID]; ok {
+		return c
+	}
+	return Contract{Multiplier: 1}
+}
+
+// markSeen records the id of a fill or bill at its time, reporting whether it was new
+func (t *Tracker) markSeen(scope, id string, ts time.Time) bool {
+	if id == "" {
+		return true
+	}
+	k := scope + "/" + id
+	if _, ok := t.seen[k]; ok {
+		return false
+	}
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	t.seen[k] = ts
+	return true
+}
+
+func keyOf(p *account.Position) Key {
+	return Key{InstID: p.InstID, MgnMode: p.MgnMode, PosSide: posSide(p.PosSide)}
+}
+
+func posSide(s okex.PositionSide) okex.PositionSide {
+	if s == "" {
+		return okex.PositionNetSide
+	}
+	return s
+}
+
+func signedPos(p *account.Position) float64 {
+	if p.PosSide == okex.PositionShortSide {
+		return -float64(p.Pos)
+	}
+	return float64(p.Pos)
+}
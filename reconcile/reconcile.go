@@ -0,0 +1,467 @@
+// Package reconcile compares the fills recorded by the process with the transaction history and bills of the
+// exchange, and corrects the order and position trackers where they disagree, e.g. after a crash or a websocket gap.
+package reconcile
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/account"
+	"github.com/yitech/okex/models/trade"
+	"github.com/yitech/okex/oms"
+	"github.com/yitech/okex/position"
+	accountRequests "github.com/yitech/okex/requests/rest/account"
+	tradeRequests "github.com/yitech/okex/requests/rest/trade"
+)
+
+const (
+	// RecentFills is how far back the fills endpoint reaches, older fills are taken from the archive
+	RecentFills = 3 * 24 * time.Hour
+	// RecentBills is how far back the bills endpoint reaches, older bills are taken from the archive
+	RecentBills = 7 * 24 * time.Hour
+
+	pageLimit = 100
+)
+
+// CorrectionKind names a correction applied by the Reconciler
+type CorrectionKind string
+
+const (
+	// CorrectionAddFill applies a fill missing locally to the order manager and the position tracker
+	CorrectionAddFill = CorrectionKind("add_fill")
+	// CorrectionReplaceFill replaces a local fill differing from the exchange
+	CorrectionReplaceFill = CorrectionKind("replace_fill")
+	// CorrectionDropFill forgets a local fill the exchange does not know
+	CorrectionDropFill = CorrectionKind("drop_fill")
+	// CorrectionDropDuplicate forgets the extra copies of a fill recorded more than once
+	CorrectionDropDuplicate = CorrectionKind("drop_duplicate")
+	// CorrectionAdoptPosition overwrites a tracked position with the exchange one, as replaced and dropped fills
+	// cannot be undone in the book
+	CorrectionAdoptPosition = CorrectionKind("adopt_position")
+)
+
+type (
+	// Mismatch is a fill whose local record differs from the exchange
+	Mismatch struct {
+		Local    *trade.TransactionDetail
+		Exchange *trade.TransactionDetail
+	}
+
+	// Correction is a change applied to the local view
+	Correction struct {
+		Kind     CorrectionKind
+		InstID   string
+		TradeID  string
+		Fill     *trade.TransactionDetail
+		Position *account.Position
+	}
+
+	// CorrectionHandler is called for every correction applied by Reconcile
+	CorrectionHandler func(c *Correction)
+
+	// Report is the outcome of a reconciliation
+	Report struct {
+		Begin time.Time
+		End   time.Time
+		// Local, Exchange and Bills count the fills and trade bills of the window
+		Local    int
+		Exchange int
+		Bills    int
+		// Missing fills are on the exchange but were not recorded locally
+		Missing []*trade.TransactionDetail
+		// Unknown fills were recorded locally but are not on the exchange
+		Unknown    []*trade.TransactionDetail
+		Duplicates []*trade.TransactionDetail
+		Mismatches []*Mismatch
+		// Unbilled fills have no matching bill
+		Unbilled []*trade.TransactionDetail
+		// Unmatched bills are trade bills without a matching fill
+		Unmatched   []*account.Bill
+		Corrections []*Correction
+	}
+
+	fillKey struct {
+		instID  string
+		tradeID string
+	}
+
+	record struct {
+		mgnMode okex.MarginMode
+		fill    *trade.TransactionDetail
+		// corrected records were added by Reconcile, the next HandleFill of the same trade is not a duplicate
+		corrected bool
+	}
+
+	// Reconciler keeps the fills recorded by the process and reconciles them against the exchange
+	Reconciler struct {
+		trade        api.OrderAPI
+		account      api.AccountAPI
+		orders       *oms.Manager
+		positions    *position.Tracker
+		mu           sync.Mutex
+		instTypes    []okex.InstrumentType
+		local        map[fillKey][]*record
+		onCorrection []CorrectionHandler
+	}
+)
+
+// Clean reports whether the local view agreed with the exchange
+func (r *Report) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Unknown) == 0 && len(r.Duplicates) == 0 && len(r.Mismatches) == 0 &&
+		len(r.Unbilled) == 0 && len(r.Unmatched) == 0
+}
+
+// NewReconciler returns a pointer to a fresh Reconciler. The account client is needed for the bills and for adopting
+// positions.
+func NewReconciler(t api.OrderAPI, a api.AccountAPI) *Reconciler {
+	return &Reconciler{
+		trade:     t,
+		account:   a,
+		instTypes: []okex.InstrumentType{okex.SpotInstrument, okex.MarginInstrument, okex.SwapInstrument, okex.FuturesInstrument, okex.OptionsInstrument},
+		local:     make(map[fillKey][]*record),
+	}
+}
+
+// SetInstTypes restricts the fills taken from the archive, which is queried per instrument type
+func (r *Reconciler) SetInstTypes(ts ...okex.InstrumentType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instTypes = ts
+}
+
+// SetOrderManager sets the order manager missing fills are applied to
+func (r *Reconciler) SetOrderManager(m *oms.Manager) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orders = m
+}
+
+// SetTracker sets the position tracker missing fills are applied to and positions are adopted by
+func (r *Reconciler) SetTracker(t *position.Tracker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions = t
+}
+
+// OnCorrection registers a handler for the corrections applied by Reconcile
+func (r *Reconciler) OnCorrection(h CorrectionHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onCorrection = append(r.onCorrection, h)
+}
+
+// Record keeps a fill seen by the process. Every call is recorded, so fills delivered twice show up as duplicates.
+func (r *Reconciler) Record(mgnMode okex.MarginMode, f *trade.TransactionDetail) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := fillKey{instID: f.InstID, tradeID: f.TradeID}
+	rs := r.local[k]
+	if len(rs) == 1 && rs[0].corrected {
+		rs[0].corrected = false
+		return
+	}
+	r.local[k] = append(rs, &record{mgnMode: mgnMode, fill: f})
+}
+
+// HandleFill is an oms.FillHandler recording the executions of the order manager
+func (r *Reconciler) HandleFill(o *oms.Order, f *oms.Fill) {
+	r.Record(okex.MarginMode(o.TdMode), &trade.TransactionDetail{
+		InstID:   o.InstID,
+		OrdID:    o.OrdID,
+		TradeID:  f.TradeID,
+		ClOrdID:  o.ClOrdID,
+		FillPx:   okex.JSONFloat64(f.FillPx),
+		FillSz:   okex.JSONFloat64(f.FillSz),
+		FeeCcy:   f.FeeCcy,
+		Fee:      okex.JSONFloat64(f.Fee),
+		InstType: o.InstType,
+		Side:     o.Side,
+		PosSide:  o.PosSide,
+		TS:       okex.JSONTime(f.TS),
+	})
+}
+
+// Prune forgets the local fills older than t
+func (r *Reconciler) Prune(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, rs := range r.local {
+		if time.Time(rs[0].fill.TS).Before(t) {
+			delete(r.local, k)
+		}
+	}
+}
+
+// Reconcile compares the local fills of a window with the exchange fills and bills, applies the corrections and
+// returns the report
+func (r *Reconciler) Reconcile(begin, end time.Time) (*Report, error) {
+	fills, err := r.fills(begin, end)
+	if err != nil {
+		return nil, err
+	}
+	var bills []*account.Bill
+	if r.account != nil {
+		if bills, err = r.bills(begin, end); err != nil {
+			return nil, err
+		}
+	}
+
+	rep := &Report{Begin: begin, End: end, Exchange: len(fills), Bills: len(bills)}
+	exchange := make(map[fillKey]*trade.TransactionDetail, len(fills))
+	for _, f := range fills {
+		exchange[fillKey{instID: f.InstID, tradeID: f.TradeID}] = f
+	}
+
+	r.mu.Lock()
+	var (
+		corrections []*Correction
+		missing     []*record
+		adopt       = make(map[string]bool)
+	)
+	keys := make([]fillKey, 0, len(r.local))
+	for k := range r.local {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return time.Time(r.local[keys[i]][0].fill.TS).Before(time.Time(r.local[keys[j]][0].fill.TS))
+	})
+	for _, k := range keys {
+		rs := r.local[k]
+		ts := time.Time(rs[0].fill.TS)
+		if ts.Before(begin) || ts.After(end) {
+			continue
+		}
+		rep.Local++
+		if len(rs) > 1 {
+			for _, d := range rs[1:] {
+				rep.Duplicates = append(rep.Duplicates, d.fill)
+				corrections = append(corrections, &Correction{Kind: CorrectionDropDuplicate, InstID: k.instID, TradeID: k.tradeID, Fill: d.fill})
+			}
+			r.local[k] = rs[:1]
+		}
+		l := rs[0].fill
+		e, ok := exchange[k]
+		switch {
+		case !ok:
+			rep.Unknown = append(rep.Unknown, l)
+			corrections = append(corrections, &Correction{Kind: CorrectionDropFill, InstID: k.instID, TradeID: k.tradeID, Fill: l})
+			delete(r.local, k)
+			adopt[k.instID] = true
+		case differ(l, e):
+			rep.Mismatches = append(rep.Mismatches, &Mismatch{Local: l, Exchange: e})
+			corrections = append(corrections, &Correction{Kind: CorrectionReplaceFill, InstID: k.instID, TradeID: k.tradeID, Fill: e})
+			rs[0].fill = e
+			adopt[k.instID] = true
+		}
+	}
+	billed := make(map[string]*account.Bill, len(bills))
+	for _, b := range bills {
+		billed[b.BillID] = b
+	}
+	for _, f := range fills {
+		k := fillKey{instID: f.InstID, tradeID: f.TradeID}
+		var mgnMode okex.MarginMode
+		if b, ok := billed[f.BillID]; ok {
+			mgnMode = b.MgnMode
+		} else if r.account != nil {
+			rep.Unbilled = append(rep.Unbilled, f)
+		}
+		if _, ok := r.local[k]; ok {
+			continue
+		}
+		rec := &record{mgnMode: mgnMode, fill: f, corrected: true}
+		r.local[k] = []*record{rec}
+		rep.Missing = append(rep.Missing, f)
+		missing = append(missing, rec)
+	}
+	orders, positions, hs := r.orders, r.positions, r.onCorrection
+	r.mu.Unlock()
+
+	byBill := make(map[string]bool, len(fills))
+	for _, f := range fills {
+		byBill[f.BillID] = true
+	}
+	for _, b := range bills {
+		if !byBill[b.BillID] {
+			rep.Unmatched = append(rep.Unmatched, b)
+		}
+	}
+
+	for _, rec := range missing {
+		r.applyMissing(orders, positions, rec)
+		corrections = append(corrections, &Correction{Kind: CorrectionAddFill, InstID: rec.fill.InstID, TradeID: rec.fill.TradeID, Fill: rec.fill})
+	}
+	if positions != nil && len(adopt) > 0 {
+		ps, err := r.adopt(positions, adopt)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			corrections = append(corrections, &Correction{Kind: CorrectionAdoptPosition, InstID: p.InstID, Position: p})
+		}
+	}
+
+	rep.Corrections = corrections
+	for _, c := range corrections {
+		for _, h := range hs {
+			h(c)
+		}
+	}
+	return rep, nil
+}
+
+// applyMissing feeds a missing fill to the order manager, when it tracks the order, and to the position tracker.
+// The margin mode is taken from the bill or else from the tracked order; without either the fill is not applied to
+// the position tracker.
+func (r *Reconciler) applyMissing(orders *oms.Manager, positions *position.Tracker, rec *record) {
+	f := rec.fill
+	if orders != nil {
+		o, ok := orders.OrderByID(f.OrdID)
+		if !ok && f.ClOrdID != "" {
+			o, ok = orders.Order(f.ClOrdID)
+		}
+		if ok {
+			if rec.mgnMode == "" {
+				rec.mgnMode = okex.MarginMode(o.TdMode)
+			}
+			// A zero update time makes the update stale, so only the fill it carries is applied
+			orders.Apply(&trade.Order{
+				InstID:     f.InstID,
+				OrdID:      f.OrdID,
+				ClOrdID:    o.ClOrdID,
+				TradeID:    f.TradeID,
+				FillPx:     f.FillPx,
+				FillSz:     f.FillSz,
+				FillFee:    f.Fee,
+				FillFeeCcy: f.FeeCcy,
+				FillTime:   okex.JSONFloat64(time.Time(f.TS).UnixMilli()),
+				InstType:   f.InstType,
+				Side:       f.Side,
+				PosSide:    f.PosSide,
+			})
+		}
+	}
+	if positions != nil && rec.mgnMode != "" {
+		positions.ApplyFill(rec.mgnMode, f)
+	}
+}
+
+// adopt overwrites the tracked positions of the instruments with the exchange ones
+func (r *Reconciler) adopt(t *position.Tracker, instIDs map[string]bool) ([]*account.Position, error) {
+	if r.account == nil {
+		return nil, fmt.Errorf("reconcile: account client is not set")
+	}
+	req := accountRequests.GetPositionsRequest{}
+	for id := range instIDs {
+		req.InstID = append(req.InstID, id)
+	}
+	sort.Strings(req.InstID)
+	res, err := r.account.GetPositions(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("reconcile: get positions failed: %d %s", res.Code, res.Msg)
+	}
+	t.Adopt(res.Positions...)
+	return res.Positions, nil
+}
+
+// fills takes the exchange fills of the window, from the archive when it starts before the recent fills
+func (r *Reconciler) fills(begin, end time.Time) ([]*trade.TransactionDetail, error) {
+	if r.trade == nil {
+		return nil, fmt.Errorf("reconcile: trade client is not set")
+	}
+	arch := time.Since(begin) > RecentFills
+	types := []okex.InstrumentType{""}
+	if arch {
+		r.mu.Lock()
+		types = r.instTypes
+		r.mu.Unlock()
+	}
+	var res []*trade.TransactionDetail
+	for _, t := range types {
+		req := tradeRequests.TransactionDetailsRequest{
+			InstType: t,
+			Begin:    begin.UnixMilli(),
+			End:      end.UnixMilli(),
+			Limit:    pageLimit,
+		}
+		for {
+			page, err := r.trade.GetTransactionDetails(req, arch)
+			if err != nil {
+				return nil, err
+			}
+			if page.Code != 0 {
+				return nil, fmt.Errorf("reconcile: get transaction details failed: %d %s", page.Code, page.Msg)
+			}
+			for _, f := range page.Transactions {
+				if ts := time.Time(f.TS); !ts.Before(begin) && !ts.After(end) {
+					res = append(res, f)
+				}
+			}
+			if len(page.Transactions) < pageLimit {
+				break
+			}
+			last := page.Transactions[len(page.Transactions)-1]
+			after, err := strconv.ParseInt(last.BillID, 10, 64)
+			if err != nil || time.Time(last.TS).Before(begin) {
+				break
+			}
+			req.After = after
+		}
+	}
+	return res, nil
+}
+
+// bills takes the trade, liquidation and ADL bills of the window, from the archive when it starts before the recent
+// bills
+func (r *Reconciler) bills(begin, end time.Time) ([]*account.Bill, error) {
+	arch := time.Since(begin) > RecentBills
+	req := accountRequests.GetBillsRequest{Begin: begin.UnixMilli(), End: end.UnixMilli(), Limit: pageLimit}
+	var res []*account.Bill
+	for {
+		page, err := r.account.GetBills(req, arch)
+		if err != nil {
+			return nil, err
+		}
+		if page.Code != 0 {
+			return nil, fmt.Errorf("reconcile: get bills failed: %d %s", page.Code, page.Msg)
+		}
+		for _, b := range page.Bills {
+			ts := time.Time(b.TS)
+			if ts.Before(begin) || ts.After(end) {
+				continue
+			}
+			switch b.Type {
+			case okex.BillTradeType, okex.BillLiquidationType, okex.BillADLType:
+				res = append(res, b)
+			}
+		}
+		if len(page.Bills) < pageLimit {
+			break
+		}
+		last := page.Bills[len(page.Bills)-1]
+		after, err := strconv.ParseInt(last.BillID, 10, 64)
+		if err != nil || time.Time(last.TS).Before(begin) {
+			break
+		}
+		req.After = after
+	}
+	return res, nil
+}
+
+func differ(l, e *trade.TransactionDetail) bool {
+	return l.Side != e.Side || !equal(float64(l.FillPx), float64(e.FillPx)) ||
+		!equal(float64(l.FillSz), float64(e.FillSz)) || !equal(float64(l.Fee), float64(e.Fee))
+}
+
+func equal(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
@@ -24,6 +24,8 @@ type (
 		CtType   okex.ContractType   `json:"ctType,omitempty"`
 		Type     okex.BillType       `json:"type,omitempty,string"`
 		SubType  okex.BillSubType    `json:"subType,omitempty,string"`
+		Begin    int64               `json:"begin,omitempty,string"`
+		End      int64               `json:"end,omitempty,string"`
 	}
 	SetPositionModeRequest struct {
 		PositionMode okex.PositionType `json:"positionMode"`
@@ -44,7 +46,7 @@ type (
 	GetMaxAvailableTradeAmountRequest struct {
 		Ccy        string         `json:"ccy,omitempty"`
 		InstID     string         `json:"instId"`
-		ReduceOnly bool           `json:"reduceOnly,omitempty"`
+		ReduceOnly bool           `json:"reduceOnly,omitempty,string"`
 		TdMode     okex.TradeMode `json:"tdMode"`
 	}
 	IncreaseDecreaseMarginRequest struct {
@@ -0,0 +1,36 @@
+package convert
+
+import "github.com/yitech/okex"
+
+type (
+	GetCurrencyPair struct {
+		FromCcy string `json:"fromCcy"`
+		ToCcy   string `json:"toCcy"`
+	}
+	EstimateQuote struct {
+		BaseCcy  string         `json:"baseCcy"`
+		QuoteCcy string         `json:"quoteCcy"`
+		Side     okex.OrderSide `json:"side"`
+		RfqSz    float64        `json:"rfqSz,string"`
+		RfqSzCcy string         `json:"rfqSzCcy"`
+		ClQReqID string         `json:"clQReqId,omitempty"`
+		Tag      string         `json:"tag,omitempty"`
+	}
+	Trade struct {
+		QuoteID  string         `json:"quoteId"`
+		BaseCcy  string         `json:"baseCcy"`
+		QuoteCcy string         `json:"quoteCcy"`
+		Side     okex.OrderSide `json:"side"`
+		Sz       float64        `json:"sz,string"`
+		SzCcy    string         `json:"szCcy"`
+		ClTReqID string         `json:"clTReqId,omitempty"`
+		Tag      string         `json:"tag,omitempty"`
+	}
+	GetHistory struct {
+		ClTReqID string `json:"clTReqId,omitempty"`
+		After    int64  `json:"after,omitempty,string"`
+		Before   int64  `json:"before,omitempty,string"`
+		Limit    int64  `json:"limit,omitempty,string"`
+		Tag      string `json:"tag,omitempty"`
+	}
+)
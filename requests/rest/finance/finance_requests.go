@@ -0,0 +1,71 @@
+package finance
+
+import "github.com/yitech/okex"
+
+type (
+	GetSavingsBalance struct {
+		Ccy string `json:"ccy,omitempty"`
+	}
+	SavingsPurchaseRedemption struct {
+		Ccy  string          `json:"ccy"`
+		Amt  float64         `json:"amt,string"`
+		Side okex.ActionType `json:"side"`
+		Rate float64         `json:"rate,omitempty,string"`
+	}
+	SetLendingRate struct {
+		Ccy  string  `json:"ccy"`
+		Rate float64 `json:"rate,string"`
+	}
+	GetLendingHistory struct {
+		Ccy    string `json:"ccy,omitempty"`
+		After  int64  `json:"after,omitempty,string"`
+		Before int64  `json:"before,omitempty,string"`
+		Limit  int64  `json:"limit,omitempty,string"`
+	}
+	GetLendingRateSummary struct {
+		Ccy string `json:"ccy,omitempty"`
+	}
+	GetLendingRateHistory struct {
+		Ccy    string `json:"ccy,omitempty"`
+		After  int64  `json:"after,omitempty,string"`
+		Before int64  `json:"before,omitempty,string"`
+		Limit  int64  `json:"limit,omitempty,string"`
+	}
+	GetStakingOffers struct {
+		ProductID    string            `json:"productId,omitempty"`
+		ProtocolType okex.ProtocolType `json:"protocolType,omitempty"`
+		Ccy          string            `json:"ccy,omitempty"`
+	}
+	InvestData struct {
+		Ccy string  `json:"ccy"`
+		Amt float64 `json:"amt,string"`
+	}
+	StakingPurchase struct {
+		ProductID  string       `json:"productId"`
+		InvestData []InvestData `json:"investData"`
+		Term       int64        `json:"term,omitempty,string"`
+	}
+	StakingRedeem struct {
+		OrdID            string            `json:"ordId"`
+		ProtocolType     okex.ProtocolType `json:"protocolType"`
+		AllowEarlyRedeem bool              `json:"allowEarlyRedeem,omitempty"`
+	}
+	StakingCancel struct {
+		OrdID        string            `json:"ordId"`
+		ProtocolType okex.ProtocolType `json:"protocolType"`
+	}
+	GetStakingActiveOrders struct {
+		ProductID    string                 `json:"productId,omitempty"`
+		ProtocolType okex.ProtocolType      `json:"protocolType,omitempty"`
+		Ccy          string                 `json:"ccy,omitempty"`
+		State        okex.StakingOrderState `json:"state,omitempty"`
+	}
+	GetStakingOrderHistory struct {
+		ProductID    string            `json:"productId,omitempty"`
+		ProtocolType okex.ProtocolType `json:"protocolType,omitempty"`
+		Ccy          string            `json:"ccy,omitempty"`
+		After        int64             `json:"after,omitempty,string"`
+		Before       int64             `json:"before,omitempty,string"`
+		Limit        int64             `json:"limit,omitempty,string"`
+	}
+)
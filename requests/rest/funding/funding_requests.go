@@ -7,26 +7,30 @@ type (
 		Ccy []string `json:"ccy,omitempty"`
 	}
 	FundsTransfer struct {
-		Ccy      string            `json:"ccy"`
-		Amt      float64           `json:"amt,string"`
-		SubAcct  string            `json:"subAcct,omitempty"`
-		InstID   string            `json:"instID,omitempty"`
-		ToInstID string            `json:"instId,omitempty"`
-		Type     okex.TransferType `json:"type,omitempty,string"`
-		From     okex.AccountType  `json:"from,string"`
-		To       okex.AccountType  `json:"to,string"`
+		Ccy       string            `json:"ccy"`
+		Amt       float64           `json:"amt,string"`
+		SubAcct   string            `json:"subAcct,omitempty"`
+		InstID    string            `json:"instId,omitempty"`
+		ToInstID  string            `json:"toInstId,omitempty"`
+		ClientID  string            `json:"clientId,omitempty"`
+		LoanTrans bool              `json:"loanTrans,omitempty"`
+		Type      okex.TransferType `json:"type,omitempty,string"`
+		From      okex.AccountType  `json:"from,string"`
+		To        okex.AccountType  `json:"to,string"`
 	}
 	AssetBillsDetails struct {
-		Type   okex.BillType `json:"type,string,omitempty"`
-		After  int64         `json:"after,string,omitempty"`
-		Before int64         `json:"before,string,omitempty"`
-		Limit  int64         `json:"limit,string,omitempty"`
+		Ccy    string             `json:"ccy,omitempty"`
+		Type   okex.AssetBillType `json:"type,string,omitempty"`
+		After  int64              `json:"after,string,omitempty"`
+		Before int64              `json:"before,string,omitempty"`
+		Limit  int64              `json:"limit,string,omitempty"`
 	}
 	GetDepositAddress struct {
 		Ccy string `json:"ccy"`
 	}
 	GetDepositHistory struct {
 		Ccy    string            `json:"ccy,omitempty"`
+		DepID  string            `json:"depId,omitempty"`
 		TxID   string            `json:"txId,omitempty"`
 		After  int64             `json:"after,omitempty,string"`
 		Before int64             `json:"before,omitempty,string"`
@@ -34,29 +38,33 @@ type (
 		State  okex.DepositState `json:"state,omitempty,string"`
 	}
 	Withdrawal struct {
-		Ccy    string                     `json:"ccy"`
-		Chain  string                     `json:"chain,omitempty"`
-		ToAddr string                     `json:"toAddr"`
-		Pwd    string                     `json:"pwd"`
-		Amt    float64                    `json:"amt,string"`
-		Fee    float64                    `json:"fee,string"`
-		Dest   okex.WithdrawalDestination `json:"dest,string"`
+		Ccy      string                     `json:"ccy"`
+		Chain    string                     `json:"chain,omitempty"`
+		ToAddr   string                     `json:"toAddr"`
+		Pwd      string                     `json:"pwd,omitempty"`
+		Amt      float64                    `json:"amt,string"`
+		Fee      float64                    `json:"fee,string"`
+		Dest     okex.WithdrawalDestination `json:"dest,string"`
+		ClientID string                     `json:"clientId,omitempty"`
+	}
+	CancelWithdrawal struct {
+		WdID int64 `json:"wdId,string"`
 	}
 	GetWithdrawalHistory struct {
-		Ccy    string               `json:"ccy,omitempty"`
-		TxID   string               `json:"txId,omitempty"`
-		After  int64                `json:"after,omitempty,string"`
-		Before int64                `json:"before,omitempty,string"`
-		Limit  int64                `json:"limit,omitempty,string"`
-		State  okex.WithdrawalState `json:"state,omitempty,string"`
+		Ccy      string               `json:"ccy,omitempty"`
+		WdID     int64                `json:"wdId,omitempty,string"`
+		ClientID string               `json:"clientId,omitempty"`
+		TxID     string               `json:"txId,omitempty"`
+		After    int64                `json:"after,omitempty,string"`
+		Before   int64                `json:"before,omitempty,string"`
+		Limit    int64                `json:"limit,omitempty,string"`
+		State    okex.WithdrawalState `json:"state,omitempty,string"`
 	}
 	PiggyBankPurchaseRedemption struct {
-		Ccy    string               `json:"ccy,omitempty"`
-		TxID   string               `json:"txId,omitempty"`
-		After  int64                `json:"after,omitempty,string"`
-		Before int64                `json:"before,omitempty,string"`
-		Limit  int64                `json:"limit,omitempty,string"`
-		State  okex.WithdrawalState `json:"state,omitempty,string"`
+		Ccy  string          `json:"ccy"`
+		Amt  float64         `json:"amt,string"`
+		Side okex.ActionType `json:"side"`
+		Rate float64         `json:"rate,omitempty,string"`
 	}
 	GetPiggyBankBalance struct {
 		Ccy string `json:"ccy,omitempty"`
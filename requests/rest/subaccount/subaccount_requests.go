@@ -5,16 +5,17 @@ import "github.com/yitech/okex"
 type (
 	ViewList struct {
 		SubAcct string `json:"subAcct,omitempty"`
-		Enable  bool   `json:"enable,omitempty"`
+		Enable  bool   `json:"enable,omitempty,string"`
 		After   int64  `json:"after,omitempty,string"`
 		Before  int64  `json:"before,omitempty,string"`
 		Limit   int64  `json:"limit,omitempty,string"`
 	}
 	CreateAPIKey struct {
-		Pwd        string            `json:"pwd"`
+		Pwd        string            `json:"pwd,omitempty"`
 		SubAcct    string            `json:"subAcct"`
+		APIKey     string            `json:"apiKey,omitempty"`
 		Label      string            `json:"label"`
-		Passphrase string            `json:"Passphrase"`
+		Passphrase string            `json:"passphrase,omitempty"`
 		IP         []string          `json:"ip,omitempty"`
 		Perm       okex.APIKeyAccess `json:"perm,omitempty"`
 	}
@@ -23,7 +24,7 @@ type (
 		SubAcct string `json:"subAcct"`
 	}
 	DeleteAPIKey struct {
-		Pwd     string `json:"pwd"`
+		Pwd     string `json:"pwd,omitempty"`
 		APIKey  string `json:"apiKey"`
 		SubAcct string `json:"subAcct"`
 	}
@@ -31,19 +32,20 @@ type (
 		SubAcct string `json:"subAcct"`
 	}
 	HistoryTransfer struct {
-		Ccy     string            `json:"ccy,omitempty"`
-		SubAcct string            `json:"subAcct,omitempty"`
-		After   int64             `json:"after,omitempty,string"`
-		Before  int64             `json:"before,omitempty,string"`
-		Limit   int64             `json:"limit,omitempty,string"`
-		Type    okex.TransferType `json:"type,omitempty,string"`
+		Ccy     string                  `json:"ccy,omitempty"`
+		SubAcct string                  `json:"subAcct,omitempty"`
+		After   int64                   `json:"after,omitempty,string"`
+		Before  int64                   `json:"before,omitempty,string"`
+		Limit   int64                   `json:"limit,omitempty,string"`
+		Type    okex.SubAccountBillType `json:"type,omitempty,string"`
 	}
 	ManageTransfers struct {
 		Ccy            string           `json:"ccy"`
 		FromSubAccount string           `json:"fromSubAccount"`
-		ToSubAccount   string           `json:"tiSubAccount"`
+		ToSubAccount   string           `json:"toSubAccount"`
 		Amt            float64          `json:"amt,string"`
 		From           okex.AccountType `json:"from,string"`
 		To             okex.AccountType `json:"to,string"`
+		LoanTrans      bool             `json:"loanTrans,omitempty"`
 	}
 )
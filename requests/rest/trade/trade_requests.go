@@ -4,15 +4,39 @@ import "github.com/yitech/okex"
 
 type (
 	PlaceOrderRequest struct {
-		InstID  string            `json:"instId"`
-		TdMode  okex.TradeMode    `json:"tdMode"`
-		Side    okex.OrderSide    `json:"side"`
-		OrdType okex.OrderType    `json:"ordType"`
-		Sz      string            `json:"sz"`
-		Px      string            `json:"px,omitempty"`
-		Tag     string            `json:"tag,omitempty"`
-		ClOrdId string            `json:"clOrdId,omitempty"`
-		TgtCcy  okex.QuantityType `json:"tgtCcy,omitempty"`
+		InstID       string               `json:"instId"`
+		TdMode       okex.TradeMode       `json:"tdMode"`
+		Ccy          string               `json:"ccy,omitempty"`
+		Side         okex.OrderSide       `json:"side"`
+		PosSide      okex.PositionSide    `json:"posSide,omitempty"`
+		OrdType      okex.OrderType       `json:"ordType"`
+		Sz           string               `json:"sz"`
+		Px           string               `json:"px,omitempty"`
+		PxUsd        string               `json:"pxUsd,omitempty"`
+		PxVol        string               `json:"pxVol,omitempty"`
+		ReduceOnly   bool                 `json:"reduceOnly,omitempty"`
+		Tag          string               `json:"tag,omitempty"`
+		ClOrdId      string               `json:"clOrdId,omitempty"`
+		TgtCcy       okex.QuantityType    `json:"tgtCcy,omitempty"`
+		BanAmend     bool                 `json:"banAmend,omitempty"`
+		QuickMgnType okex.QuickMarginType `json:"quickMgnType,omitempty"`
+		StpId        string               `json:"stpId,omitempty"`
+		StpMode      okex.STPMode         `json:"stpMode,omitempty"`
+		// AttachAlgoOrds are the take profit and stop loss orders placed once the order is filled
+		AttachAlgoOrds []AttachAlgoOrder `json:"attachAlgoOrds,omitempty"`
+	}
+
+	AttachAlgoOrder struct {
+		AttachAlgoClOrdId string             `json:"attachAlgoClOrdId,omitempty"`
+		TpTriggerPx       string             `json:"tpTriggerPx,omitempty"`
+		TpOrdPx           string             `json:"tpOrdPx,omitempty"`
+		TpTriggerPxType   okex.TriggerPxType `json:"tpTriggerPxType,omitempty"`
+		SlTriggerPx       string             `json:"slTriggerPx,omitempty"`
+		SlOrdPx           string             `json:"slOrdPx,omitempty"`
+		SlTriggerPxType   okex.TriggerPxType `json:"slTriggerPxType,omitempty"`
+		Sz                string             `json:"sz,omitempty"`
+		// AmendPxOnTriggerType sets whether the stop loss moves to the cost price once the first take profit triggers
+		AmendPxOnTriggerType string `json:"amendPxOnTriggerType,omitempty"`
 	}
 
 	CancelOrderRequest struct {
@@ -21,6 +45,31 @@ type (
 		ClOrdId string `json:"clOrdId,omitempty"`
 	}
 
+	AmendOrderRequest struct {
+		InstID         string                 `json:"instId"`
+		OrdId          string                 `json:"ordId,omitempty"`
+		ClOrdId        string                 `json:"clOrdId,omitempty"`
+		ReqId          string                 `json:"reqId,omitempty"`
+		NewSz          string                 `json:"newSz,omitempty"`
+		NewPx          string                 `json:"newPx,omitempty"`
+		NewPxUsd       string                 `json:"newPxUsd,omitempty"`
+		NewPxVol       string                 `json:"newPxVol,omitempty"`
+		CxlOnFail      bool                   `json:"cxlOnFail,omitempty"`
+		AttachAlgoOrds []AmendAttachAlgoOrder `json:"attachAlgoOrds,omitempty"`
+	}
+
+	AmendAttachAlgoOrder struct {
+		AttachAlgoId       string             `json:"attachAlgoId,omitempty"`
+		AttachAlgoClOrdId  string             `json:"attachAlgoClOrdId,omitempty"`
+		NewTpTriggerPx     string             `json:"newTpTriggerPx,omitempty"`
+		NewTpOrdPx         string             `json:"newTpOrdPx,omitempty"`
+		NewTpTriggerPxType okex.TriggerPxType `json:"newTpTriggerPxType,omitempty"`
+		NewSlTriggerPx     string             `json:"newSlTriggerPx,omitempty"`
+		NewSlOrdPx         string             `json:"newSlOrdPx,omitempty"`
+		NewSlTriggerPxType okex.TriggerPxType `json:"newSlTriggerPxType,omitempty"`
+		Sz                 string             `json:"sz,omitempty"`
+	}
+
 	OrderListRequest struct {
 		InstType okex.InstrumentType `json:"instType,omitempty"`
 		InstID   string              `json:"instId,omitempty"`
@@ -39,11 +88,15 @@ type (
 	}
 
 	TransactionDetailsRequest struct {
-		InstID string `json:"instId,omitempty"`
-		OrdId  string `json:"ordId,omitempty"`
-		After  int64  `json:"after,omitempty,string"`
-		Before int64  `json:"before,omitempty,string"`
-		Limit  int64  `json:"limit,omitempty,string"`
+		InstType okex.InstrumentType `json:"instType,omitempty"`
+		Uly      string              `json:"uly,omitempty"`
+		InstID   string              `json:"instId,omitempty"`
+		OrdId    string              `json:"ordId,omitempty"`
+		After    int64               `json:"after,omitempty,string"`
+		Before   int64               `json:"before,omitempty,string"`
+		Begin    int64               `json:"begin,omitempty,string"`
+		End      int64               `json:"end,omitempty,string"`
+		Limit    int64               `json:"limit,omitempty,string"`
 	}
 
 	ClosePositionRequest struct {
@@ -53,17 +106,65 @@ type (
 	}
 
 	PlaceAlgoOrderRequest struct {
-		InstID      string             `json:"instId"`
-		TdMode      okex.TradeMode     `json:"tdMode"`
-		Side        okex.OrderSide     `json:"side"`
-		OrdType     okex.AlgoOrderType `json:"ordType"`
-		Sz          string             `json:"sz"`
-		Tag         string             `json:"tag,omitempty"`
-		ClOrdId     string             `json:"clOrdId,omitempty"`
-		SlTriggerPx string             `json:"slTriggerPx,omitempty"`
-		SlOrdPx     string             `json:"slOrdPx,omitempty"`
-		TpTriggerPx string             `json:"tpTriggerPx,omitempty"`
-		TpOrdPx     string             `json:"tpOrdPx,omitempty"`
+		InstID       string               `json:"instId"`
+		TdMode       okex.TradeMode       `json:"tdMode"`
+		Ccy          string               `json:"ccy,omitempty"`
+		Side         okex.OrderSide       `json:"side"`
+		PosSide      okex.PositionSide    `json:"posSide,omitempty"`
+		OrdType      okex.AlgoOrderType   `json:"ordType"`
+		Sz           string               `json:"sz,omitempty"`
+		Tag          string               `json:"tag,omitempty"`
+		AlgoClOrdId  string               `json:"algoClOrdId,omitempty"`
+		TgtCcy       okex.QuantityType    `json:"tgtCcy,omitempty"`
+		ReduceOnly   bool                 `json:"reduceOnly,omitempty"`
+		QuickMgnType okex.QuickMarginType `json:"quickMgnType,omitempty"`
+
+		// Take profit and stop loss, for conditional and oco orders. An order price of -1 means a market order.
+		TpTriggerPx     string             `json:"tpTriggerPx,omitempty"`
+		TpTriggerPxType okex.TriggerPxType `json:"tpTriggerPxType,omitempty"`
+		TpOrdPx         string             `json:"tpOrdPx,omitempty"`
+		SlTriggerPx     string             `json:"slTriggerPx,omitempty"`
+		SlTriggerPxType okex.TriggerPxType `json:"slTriggerPxType,omitempty"`
+		SlOrdPx         string             `json:"slOrdPx,omitempty"`
+		// CloseFraction closes the whole position when set to 1, in place of the size
+		CloseFraction string `json:"closeFraction,omitempty"`
+		CxlOnClosePos bool   `json:"cxlOnClosePos,omitempty"`
+
+		// Trigger orders
+		TriggerPx      string             `json:"triggerPx,omitempty"`
+		TriggerPxType  okex.TriggerPxType `json:"triggerPxType,omitempty"`
+		OrderPx        string             `json:"orderPx,omitempty"`
+		AttachAlgoOrds []AttachAlgoOrder  `json:"attachAlgoOrds,omitempty"`
+
+		// Trailing stop orders
+		CallbackRatio  string `json:"callbackRatio,omitempty"`
+		CallbackSpread string `json:"callbackSpread,omitempty"`
+		ActivePx       string `json:"activePx,omitempty"`
+
+		// Iceberg and twap orders
+		PxVar        string `json:"pxVar,omitempty"`
+		PxSpread     string `json:"pxSpread,omitempty"`
+		SzLimit      string `json:"szLimit,omitempty"`
+		PxLimit      string `json:"pxLimit,omitempty"`
+		TimeInterval string `json:"timeInterval,omitempty"`
+	}
+
+	AmendAlgoOrderRequest struct {
+		InstID             string             `json:"instId"`
+		AlgoId             string             `json:"algoId,omitempty"`
+		AlgoClOrdId        string             `json:"algoClOrdId,omitempty"`
+		CxlOnFail          bool               `json:"cxlOnFail,omitempty"`
+		ReqId              string             `json:"reqId,omitempty"`
+		NewSz              string             `json:"newSz,omitempty"`
+		NewTpTriggerPx     string             `json:"newTpTriggerPx,omitempty"`
+		NewTpOrdPx         string             `json:"newTpOrdPx,omitempty"`
+		NewTpTriggerPxType okex.TriggerPxType `json:"newTpTriggerPxType,omitempty"`
+		NewSlTriggerPx     string             `json:"newSlTriggerPx,omitempty"`
+		NewSlOrdPx         string             `json:"newSlOrdPx,omitempty"`
+		NewSlTriggerPxType okex.TriggerPxType `json:"newSlTriggerPxType,omitempty"`
+		NewTriggerPx       string             `json:"newTriggerPx,omitempty"`
+		NewOrdPx           string             `json:"newOrdPx,omitempty"`
+		NewTriggerPxType   okex.TriggerPxType `json:"newTriggerPxType,omitempty"`
 	}
 
 	CancelAlgoOrderRequest struct {
@@ -72,12 +173,24 @@ type (
 		ClOrdId string `json:"clOrdId,omitempty"`
 	}
 
+	AlgoOrderDetailsRequest struct {
+		AlgoId      string `json:"algoId,omitempty"`
+		AlgoClOrdId string `json:"algoClOrdId,omitempty"`
+	}
+
 	AlgoOrderListRequest struct {
-		InstID  string             `json:"instId,omitempty"`
-		OrdType okex.AlgoOrderType `json:"ordType,omitempty"`
-		State   okex.OrderState    `json:"state,omitempty"`
-		After   int64              `json:"after,omitempty,string"`
-		Before  int64              `json:"before,omitempty,string"`
-		Limit   int64              `json:"limit,omitempty,string"`
+		InstType    okex.InstrumentType `json:"instType,omitempty"`
+		InstID      string              `json:"instId,omitempty"`
+		OrdType     okex.AlgoOrderType  `json:"ordType,omitempty"`
+		State       okex.OrderState     `json:"state,omitempty"`
+		AlgoId      string              `json:"algoId,omitempty"`
+		AlgoClOrdId string              `json:"algoClOrdId,omitempty"`
+		After       int64               `json:"after,omitempty,string"`
+		Before      int64               `json:"before,omitempty,string"`
+		Limit       int64               `json:"limit,omitempty,string"`
+	}
+
+	CancelAllAfterRequest struct {
+		TimeOut int64 `json:"timeOut,string"`
 	}
 )
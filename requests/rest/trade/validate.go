@@ -0,0 +1,370 @@
+package trade
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yitech/okex"
+)
+
+// MaxBatchSize is the largest number of orders a batch endpoint accepts
+const MaxBatchSize = 20
+
+// Validate rejects the orders the exchange would reject for their combination of parameters
+func (r PlaceOrderRequest) Validate() error {
+	if r.InstID == "" {
+		return fmt.Errorf("trade: instId is required")
+	}
+	spot, option := kind(r.InstID)
+	switch r.TdMode {
+	case okex.TradeCrossMode, okex.TradeIsolatedMode, okex.TradeCashMode:
+	default:
+		return fmt.Errorf("trade: invalid tdMode %q", r.TdMode)
+	}
+	if r.TdMode == okex.TradeCashMode && !spot {
+		return fmt.Errorf("trade: tdMode cash is not applicable to %s", r.InstID)
+	}
+	switch r.Side {
+	case okex.OrderBuy, okex.OrderSell:
+	default:
+		return fmt.Errorf("trade: invalid side %q", r.Side)
+	}
+	switch r.PosSide {
+	case "", okex.PositionNetSide:
+	case okex.PositionLongSide, okex.PositionShortSide:
+		if spot {
+			return fmt.Errorf("trade: posSide %s is not applicable to %s", r.PosSide, r.InstID)
+		}
+	default:
+		return fmt.Errorf("trade: invalid posSide %q", r.PosSide)
+	}
+	if !positive(r.Sz) {
+		return fmt.Errorf("trade: invalid sz %q", r.Sz)
+	}
+
+	prices := 0
+	for _, p := range []string{r.Px, r.PxUsd, r.PxVol} {
+		if p != "" {
+			prices++
+		}
+	}
+	switch r.OrdType {
+	case okex.OrderMarket, okex.OrderOptimalLimitIoc:
+		if prices > 0 {
+			return fmt.Errorf("trade: px is not applicable to %s orders", r.OrdType)
+		}
+		if r.OrdType == okex.OrderOptimalLimitIoc && (spot || option) {
+			return fmt.Errorf("trade: %s orders are only applicable to futures and swaps", r.OrdType)
+		}
+		if r.OrdType == okex.OrderMarket && option {
+			return fmt.Errorf("trade: market orders are not applicable to options")
+		}
+	case okex.OrderLimit, okex.OrderPostOnly, okex.OrderFOK, okex.OrderIOC,
+		okex.OrderMMP, okex.OrderMMPAndPostOnly, okex.OrderOpFOK:
+		if prices != 1 {
+			return fmt.Errorf("trade: exactly one of px, pxUsd and pxVol is required for %s orders", r.OrdType)
+		}
+		if r.Px != "" && !positive(r.Px) {
+			return fmt.Errorf("trade: invalid px %q", r.Px)
+		}
+		if (r.PxUsd != "" || r.PxVol != "") && !option {
+			return fmt.Errorf("trade: pxUsd and pxVol are only applicable to options")
+		}
+		if (r.OrdType == okex.OrderMMP || r.OrdType == okex.OrderMMPAndPostOnly || r.OrdType == okex.OrderOpFOK) && !option {
+			return fmt.Errorf("trade: %s orders are only applicable to options", r.OrdType)
+		}
+	default:
+		return fmt.Errorf("trade: invalid ordType %q", r.OrdType)
+	}
+
+	switch r.TgtCcy {
+	case "":
+	case okex.QuantityBaseCcy, okex.QuantityQuoteCcy:
+		if !spot || r.OrdType != okex.OrderMarket {
+			return fmt.Errorf("trade: tgtCcy is only applicable to spot market orders")
+		}
+	default:
+		return fmt.Errorf("trade: invalid tgtCcy %q", r.TgtCcy)
+	}
+	if r.ReduceOnly && r.TdMode == okex.TradeCashMode {
+		return fmt.Errorf("trade: reduceOnly is not applicable to cash orders")
+	}
+	if r.Ccy != "" && (!spot || r.TdMode == okex.TradeCashMode) {
+		return fmt.Errorf("trade: ccy is only applicable to margin orders")
+	}
+	switch r.QuickMgnType {
+	case "":
+	case okex.QuickMarginManual, okex.QuickMarginAutoBorrow, okex.QuickMarginAutoRepay:
+		if !spot || r.TdMode == okex.TradeCashMode {
+			return fmt.Errorf("trade: quickMgnType is only applicable to margin orders")
+		}
+	default:
+		return fmt.Errorf("trade: invalid quickMgnType %q", r.QuickMgnType)
+	}
+	switch r.StpMode {
+	case "", okex.STPCancelMaker, okex.STPCancelTaker, okex.STPCancelBoth:
+	default:
+		return fmt.Errorf("trade: invalid stpMode %q", r.StpMode)
+	}
+	if !alphanumeric(r.ClOrdId, 32) {
+		return fmt.Errorf("trade: invalid clOrdId %q", r.ClOrdId)
+	}
+	if !alphanumeric(r.Tag, 16) {
+		return fmt.Errorf("trade: invalid tag %q", r.Tag)
+	}
+
+	for _, a := range r.AttachAlgoOrds {
+		if option {
+			return fmt.Errorf("trade: attachAlgoOrds are not applicable to options")
+		}
+		if err := attached(a.TpTriggerPx, a.TpOrdPx, a.TpTriggerPxType, a.SlTriggerPx, a.SlOrdPx, a.SlTriggerPxType); err != nil {
+			return err
+		}
+		if len(r.AttachAlgoOrds) > 1 && !positive(a.Sz) {
+			return fmt.Errorf("trade: sz is required for split take profits")
+		}
+		if !alphanumeric(a.AttachAlgoClOrdId, 32) {
+			return fmt.Errorf("trade: invalid attachAlgoClOrdId %q", a.AttachAlgoClOrdId)
+		}
+	}
+	return nil
+}
+
+// Validate rejects the amendments the exchange would reject for their combination of parameters
+func (r AmendOrderRequest) Validate() error {
+	if r.InstID == "" {
+		return fmt.Errorf("trade: instId is required")
+	}
+	if r.OrdId == "" && r.ClOrdId == "" {
+		return fmt.Errorf("trade: either ordId or clOrdId is required")
+	}
+	prices := 0
+	for _, p := range []string{r.NewPx, r.NewPxUsd, r.NewPxVol} {
+		if p != "" {
+			prices++
+		}
+	}
+	if prices > 1 {
+		return fmt.Errorf("trade: only one of newPx, newPxUsd and newPxVol can be set")
+	}
+	if prices == 0 && r.NewSz == "" && len(r.AttachAlgoOrds) == 0 {
+		return fmt.Errorf("trade: nothing to amend")
+	}
+	if r.NewSz != "" && !positive(r.NewSz) {
+		return fmt.Errorf("trade: invalid newSz %q", r.NewSz)
+	}
+	if r.NewPx != "" && !positive(r.NewPx) {
+		return fmt.Errorf("trade: invalid newPx %q", r.NewPx)
+	}
+	for _, a := range r.AttachAlgoOrds {
+		if a.AttachAlgoId == "" && a.AttachAlgoClOrdId == "" {
+			return fmt.Errorf("trade: either attachAlgoId or attachAlgoClOrdId is required")
+		}
+		for _, t := range []okex.TriggerPxType{a.NewTpTriggerPxType, a.NewSlTriggerPxType} {
+			if !triggerPxType(t) {
+				return fmt.Errorf("trade: invalid trigger price type %q", t)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate rejects the algo orders missing the parameters of their type or mixing those of other types
+func (r PlaceAlgoOrderRequest) Validate() error {
+	if r.InstID == "" {
+		return fmt.Errorf("trade: instId is required")
+	}
+	switch r.TdMode {
+	case okex.TradeCrossMode, okex.TradeIsolatedMode, okex.TradeCashMode:
+	default:
+		return fmt.Errorf("trade: invalid tdMode %q", r.TdMode)
+	}
+	switch r.Side {
+	case okex.OrderBuy, okex.OrderSell:
+	default:
+		return fmt.Errorf("trade: invalid side %q", r.Side)
+	}
+	switch r.PosSide {
+	case "", okex.PositionNetSide, okex.PositionLongSide, okex.PositionShortSide:
+	default:
+		return fmt.Errorf("trade: invalid posSide %q", r.PosSide)
+	}
+	if r.ReduceOnly && r.TdMode == okex.TradeCashMode {
+		return fmt.Errorf("trade: reduceOnly is not applicable to cash orders")
+	}
+	if !alphanumeric(r.AlgoClOrdId, 32) {
+		return fmt.Errorf("trade: invalid algoClOrdId %q", r.AlgoClOrdId)
+	}
+	if !alphanumeric(r.Tag, 16) {
+		return fmt.Errorf("trade: invalid tag %q", r.Tag)
+	}
+	switch {
+	case r.CloseFraction == "" && !positive(r.Sz):
+		return fmt.Errorf("trade: invalid sz %q", r.Sz)
+	case r.CloseFraction != "" && r.Sz != "":
+		return fmt.Errorf("trade: sz and closeFraction are mutually exclusive")
+	case r.CloseFraction != "" && r.OrdType != okex.AlgoOrderConditional && r.OrdType != okex.AlgoOrderOCO:
+		return fmt.Errorf("trade: closeFraction is only applicable to conditional and oco orders")
+	}
+
+	tpsl := r.TpTriggerPx != "" || r.TpOrdPx != "" || r.SlTriggerPx != "" || r.SlOrdPx != ""
+	trigger := r.TriggerPx != "" || r.OrderPx != "" || len(r.AttachAlgoOrds) > 0
+	trailing := r.CallbackRatio != "" || r.CallbackSpread != "" || r.ActivePx != ""
+	advance := r.PxVar != "" || r.PxSpread != "" || r.SzLimit != "" || r.PxLimit != "" || r.TimeInterval != ""
+	switch r.OrdType {
+	case okex.AlgoOrderConditional, okex.AlgoOrderOCO:
+		if trigger || trailing || advance {
+			return fmt.Errorf("trade: only take profit and stop loss parameters are applicable to %s orders", r.OrdType)
+		}
+		if err := attached(r.TpTriggerPx, r.TpOrdPx, r.TpTriggerPxType, r.SlTriggerPx, r.SlOrdPx, r.SlTriggerPxType); err != nil {
+			return err
+		}
+		if r.OrdType == okex.AlgoOrderOCO && (r.TpTriggerPx == "" || r.SlTriggerPx == "") {
+			return fmt.Errorf("trade: both take profit and stop loss are required for oco orders")
+		}
+	case okex.AlgoOrderTrigger:
+		if tpsl || trailing || advance {
+			return fmt.Errorf("trade: only trigger parameters are applicable to trigger orders")
+		}
+		if !positive(r.TriggerPx) {
+			return fmt.Errorf("trade: invalid triggerPx %q", r.TriggerPx)
+		}
+		if r.OrderPx != "-1" && !positive(r.OrderPx) {
+			return fmt.Errorf("trade: invalid orderPx %q", r.OrderPx)
+		}
+		if !triggerPxType(r.TriggerPxType) {
+			return fmt.Errorf("trade: invalid triggerPxType %q", r.TriggerPxType)
+		}
+		for _, a := range r.AttachAlgoOrds {
+			if err := attached(a.TpTriggerPx, a.TpOrdPx, a.TpTriggerPxType, a.SlTriggerPx, a.SlOrdPx, a.SlTriggerPxType); err != nil {
+				return err
+			}
+		}
+	case okex.AlgoOrderMoveStop:
+		if tpsl || trigger || advance {
+			return fmt.Errorf("trade: only trailing parameters are applicable to move_order_stop orders")
+		}
+		if (r.CallbackRatio == "") == (r.CallbackSpread == "") {
+			return fmt.Errorf("trade: exactly one of callbackRatio and callbackSpread is required")
+		}
+		for _, p := range []string{r.CallbackRatio, r.CallbackSpread, r.ActivePx} {
+			if p != "" && !positive(p) {
+				return fmt.Errorf("trade: invalid trailing parameter %q", p)
+			}
+		}
+	case okex.AlgoOrderIceberg, okex.AlgoOrderTwap:
+		if tpsl || trigger || trailing {
+			return fmt.Errorf("trade: only %s parameters are applicable to %s orders", r.OrdType, r.OrdType)
+		}
+		if (r.PxVar == "") == (r.PxSpread == "") {
+			return fmt.Errorf("trade: exactly one of pxVar and pxSpread is required")
+		}
+		for _, p := range []string{r.PxVar, r.PxSpread} {
+			if p != "" && !positive(p) {
+				return fmt.Errorf("trade: invalid price variance %q", p)
+			}
+		}
+		if !positive(r.SzLimit) {
+			return fmt.Errorf("trade: invalid szLimit %q", r.SzLimit)
+		}
+		if !positive(r.PxLimit) {
+			return fmt.Errorf("trade: invalid pxLimit %q", r.PxLimit)
+		}
+		if r.OrdType == okex.AlgoOrderTwap && !positive(r.TimeInterval) {
+			return fmt.Errorf("trade: invalid timeInterval %q", r.TimeInterval)
+		}
+		if r.OrdType == okex.AlgoOrderIceberg && r.TimeInterval != "" {
+			return fmt.Errorf("trade: timeInterval is not applicable to iceberg orders")
+		}
+	default:
+		return fmt.Errorf("trade: invalid ordType %q", r.OrdType)
+	}
+	return nil
+}
+
+// Validate rejects the algo amendments without an order id or anything to amend
+func (r AmendAlgoOrderRequest) Validate() error {
+	if r.InstID == "" {
+		return fmt.Errorf("trade: instId is required")
+	}
+	if r.AlgoId == "" && r.AlgoClOrdId == "" {
+		return fmt.Errorf("trade: either algoId or algoClOrdId is required")
+	}
+	if r.NewSz == "" && r.NewTpTriggerPx == "" && r.NewTpOrdPx == "" && r.NewSlTriggerPx == "" && r.NewSlOrdPx == "" &&
+		r.NewTriggerPx == "" && r.NewOrdPx == "" {
+		return fmt.Errorf("trade: nothing to amend")
+	}
+	if r.NewSz != "" && !positive(r.NewSz) {
+		return fmt.Errorf("trade: invalid newSz %q", r.NewSz)
+	}
+	for _, t := range []okex.TriggerPxType{r.NewTpTriggerPxType, r.NewSlTriggerPxType, r.NewTriggerPxType} {
+		if !triggerPxType(t) {
+			return fmt.Errorf("trade: invalid trigger price type %q", t)
+		}
+	}
+	return nil
+}
+
+// attached checks a take profit and stop loss pair, an order price of -1 means a market order once triggered
+func attached(tpTrigger, tpOrd string, tpType okex.TriggerPxType, slTrigger, slOrd string, slType okex.TriggerPxType) error {
+	if tpTrigger == "" && slTrigger == "" {
+		return fmt.Errorf("trade: either tpTriggerPx or slTriggerPx is required")
+	}
+	if (tpTrigger == "") != (tpOrd == "") {
+		return fmt.Errorf("trade: tpTriggerPx and tpOrdPx must be set together")
+	}
+	if (slTrigger == "") != (slOrd == "") {
+		return fmt.Errorf("trade: slTriggerPx and slOrdPx must be set together")
+	}
+	for _, p := range []string{tpTrigger, slTrigger} {
+		if p != "" && !positive(p) {
+			return fmt.Errorf("trade: invalid trigger price %q", p)
+		}
+	}
+	for _, p := range []string{tpOrd, slOrd} {
+		if p != "" && p != "-1" && !positive(p) {
+			return fmt.Errorf("trade: invalid order price %q", p)
+		}
+	}
+	if !triggerPxType(tpType) || tpType != "" && tpTrigger == "" {
+		return fmt.Errorf("trade: invalid tpTriggerPxType %q", tpType)
+	}
+	if !triggerPxType(slType) || slType != "" && slTrigger == "" {
+		return fmt.Errorf("trade: invalid slTriggerPxType %q", slType)
+	}
+	return nil
+}
+
+func triggerPxType(t okex.TriggerPxType) bool {
+	switch t {
+	case "", okex.TriggerPxLast, okex.TriggerPxIndex, okex.TriggerPxMark:
+		return true
+	}
+	return false
+}
+
+// kind tells spot and margin pairs (BTC-USDT) and options (BTC-USD-240329-60000-C) apart from futures and swaps
+func kind(instID string) (spot, option bool) {
+	p := strings.Split(instID, "-")
+	spot = len(p) == 2
+	option = len(p) == 5 && (p[4] == string(okex.OptionCall) || p[4] == string(okex.OptionPut))
+	return
+}
+
+func positive(s string) bool {
+	v, err := strconv.ParseFloat(s, 64)
+	return err == nil && v > 0
+}
+
+func alphanumeric(s string, n int) bool {
+	if len(s) > n {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package tradingbot
+
+import "github.com/yitech/okex"
+
+type (
+	TriggerParam struct {
+		TriggerAction   string            `json:"triggerAction"`
+		TriggerStrategy string            `json:"triggerStrategy"`
+		DelaySeconds    int64             `json:"delaySeconds,omitempty,string"`
+		Timeframe       string            `json:"timeframe,omitempty"`
+		Thold           string            `json:"thold,omitempty"`
+		TriggerCond     string            `json:"triggerCond,omitempty"`
+		TimePeriod      string            `json:"timePeriod,omitempty"`
+		TriggerPx       float64           `json:"triggerPx,omitempty,string"`
+		StopType        okex.GridStopType `json:"stopType,omitempty"`
+	}
+	PlaceGridOrder struct {
+		InstID             string                 `json:"instId"`
+		AlgoOrdType        okex.GridAlgoOrderType `json:"algoOrdType"`
+		MaxPx              float64                `json:"maxPx,string"`
+		MinPx              float64                `json:"minPx,string"`
+		GridNum            int64                  `json:"gridNum,string"`
+		RunType            okex.GridRunType       `json:"runType,omitempty"`
+		TpTriggerPx        float64                `json:"tpTriggerPx,omitempty,string"`
+		SlTriggerPx        float64                `json:"slTriggerPx,omitempty,string"`
+		AlgoClOrdID        string                 `json:"algoClOrdId,omitempty"`
+		Tag                string                 `json:"tag,omitempty"`
+		ProfitSharingRatio float64                `json:"profitSharingRatio,omitempty,string"`
+		TriggerParams      []TriggerParam         `json:"triggerParams,omitempty"`
+		QuoteSz            float64                `json:"quoteSz,omitempty,string"`
+		BaseSz             float64                `json:"baseSz,omitempty,string"`
+		Sz                 float64                `json:"sz,omitempty,string"`
+		Direction          okex.GridDirection     `json:"direction,omitempty"`
+		Lever              float64                `json:"lever,omitempty,string"`
+		BasePos            bool                   `json:"basePos,omitempty"`
+		TpRatio            float64                `json:"tpRatio,omitempty,string"`
+		SlRatio            float64                `json:"slRatio,omitempty,string"`
+	}
+	AmendGridOrder struct {
+		AlgoID        string         `json:"algoId"`
+		InstID        string         `json:"instId"`
+		TpTriggerPx   float64        `json:"tpTriggerPx,omitempty,string"`
+		SlTriggerPx   float64        `json:"slTriggerPx,omitempty,string"`
+		TpRatio       float64        `json:"tpRatio,omitempty,string"`
+		SlRatio       float64        `json:"slRatio,omitempty,string"`
+		TriggerParams []TriggerParam `json:"triggerParams,omitempty"`
+	}
+	StopGridOrder struct {
+		AlgoID      string                 `json:"algoId"`
+		InstID      string                 `json:"instId"`
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		StopType    okex.GridStopType      `json:"stopType"`
+	}
+	GetGridOrders struct {
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		AlgoID      string                 `json:"algoId,omitempty"`
+		InstID      string                 `json:"instId,omitempty"`
+		InstType    okex.InstrumentType    `json:"instType,omitempty"`
+		After       string                 `json:"after,omitempty"`
+		Before      string                 `json:"before,omitempty"`
+		Limit       int64                  `json:"limit,omitempty,string"`
+	}
+	GetGridOrderDetails struct {
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		AlgoID      string                 `json:"algoId"`
+	}
+	GetGridSubOrders struct {
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		AlgoID      string                 `json:"algoId"`
+		Type        okex.GridSubOrderType  `json:"type"`
+		GroupID     string                 `json:"groupId,omitempty"`
+		After       string                 `json:"after,omitempty"`
+		Before      string                 `json:"before,omitempty"`
+		Limit       int64                  `json:"limit,omitempty,string"`
+	}
+	GetGridPositions struct {
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		AlgoID      string                 `json:"algoId"`
+	}
+	GetGridAIParam struct {
+		AlgoOrdType okex.GridAlgoOrderType `json:"algoOrdType"`
+		InstID      string                 `json:"instId"`
+		Direction   okex.GridDirection     `json:"direction,omitempty"`
+		Duration    string                 `json:"duration,omitempty"`
+	}
+	Investment struct {
+		Amt float64 `json:"amt,string"`
+		Ccy string  `json:"ccy"`
+	}
+	ComputeMinInvestment struct {
+		InstID         string                 `json:"instId"`
+		AlgoOrdType    okex.GridAlgoOrderType `json:"algoOrdType"`
+		MaxPx          float64                `json:"maxPx,string"`
+		MinPx          float64                `json:"minPx,string"`
+		GridNum        int64                  `json:"gridNum,string"`
+		RunType        okex.GridRunType       `json:"runType"`
+		Direction      okex.GridDirection     `json:"direction,omitempty"`
+		Lever          float64                `json:"lever,omitempty,string"`
+		BasePos        bool                   `json:"basePos,omitempty"`
+		InvestmentData []Investment           `json:"investmentData,omitempty"`
+	}
+)
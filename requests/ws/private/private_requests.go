@@ -6,6 +6,9 @@ type (
 	Account struct {
 		Ccy string `json:"ccy,omitempty"`
 	}
+	DepositInfo struct {
+		Ccy string `json:"ccy,omitempty"`
+	}
 	Position struct {
 		Uly      string              `json:"uly,omitempty"`
 		InstID   string              `json:"instId,omitempty"`
@@ -21,4 +24,20 @@ type (
 		InstID   string              `json:"instId,omitempty"`
 		InstType okex.InstrumentType `json:"instType"`
 	}
+	GridOrders struct {
+		InstID   string              `json:"instId,omitempty"`
+		AlgoID   string              `json:"algoId,omitempty"`
+		InstType okex.InstrumentType `json:"instType"`
+	}
+	GridPositions struct {
+		AlgoID string `json:"algoId"`
+	}
+	GridSubOrders struct {
+		AlgoID string `json:"algoId"`
+	}
+	AdvanceAlgoOrder struct {
+		InstID   string              `json:"instId,omitempty"`
+		AlgoID   string              `json:"algoId,omitempty"`
+		InstType okex.InstrumentType `json:"instType"`
+	}
 )
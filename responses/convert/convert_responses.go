@@ -0,0 +1,25 @@
+package convert
+
+import (
+	models "github.com/yitech/okex/models/convert"
+	"github.com/yitech/okex/responses"
+)
+
+type (
+	GetCurrencies struct {
+		responses.Basic
+		Currencies []*models.Currency `json:"data"`
+	}
+	GetCurrencyPair struct {
+		responses.Basic
+		CurrencyPairs []*models.CurrencyPair `json:"data"`
+	}
+	EstimateQuote struct {
+		responses.Basic
+		Quotes []*models.Quote `json:"data"`
+	}
+	Trade struct {
+		responses.Basic
+		Trades []*models.Trade `json:"data"`
+	}
+)
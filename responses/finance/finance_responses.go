@@ -0,0 +1,45 @@
+package finance
+
+import (
+	models "github.com/yitech/okex/models/finance"
+	"github.com/yitech/okex/responses"
+)
+
+type (
+	GetSavingsBalance struct {
+		responses.Basic
+		Balances []*models.SavingsBalance `json:"data"`
+	}
+	SavingsPurchaseRedemption struct {
+		responses.Basic
+		PurchaseRedemptions []*models.SavingsPurchaseRedemption `json:"data"`
+	}
+	SetLendingRate struct {
+		responses.Basic
+		LendingRates []*models.LendingRate `json:"data"`
+	}
+	GetLendingHistory struct {
+		responses.Basic
+		LendingHistories []*models.LendingHistory `json:"data"`
+	}
+	GetLendingRateSummary struct {
+		responses.Basic
+		Summaries []*models.LendingRateSummary `json:"data"`
+	}
+	GetLendingRateHistory struct {
+		responses.Basic
+		LendingRates []*models.LendingRateHistory `json:"data"`
+	}
+	GetStakingOffers struct {
+		responses.Basic
+		Offers []*models.StakingOffer `json:"data"`
+	}
+	StakingOrderResult struct {
+		responses.Basic
+		Orders []*models.StakingOrderResult `json:"data"`
+	}
+	GetStakingOrders struct {
+		responses.Basic
+		Orders []*models.StakingOrder `json:"data"`
+	}
+)
@@ -34,6 +34,10 @@ type (
 		responses.Basic
 		Withdrawals []*models.Withdrawal `json:"data"`
 	}
+	CancelWithdrawal struct {
+		responses.Basic
+		Withdrawals []*models.Withdrawal `json:"data"`
+	}
 	GetWithdrawalHistory struct {
 		responses.Basic
 		WithdrawalHistories []*models.WithdrawalHistory `json:"data"`
@@ -46,8 +46,18 @@ type (
 		Orders []*trade.CancelAlgoOrder `json:"data,omitempty"`
 	}
 
+	AmendAlgoOrderResponse struct {
+		responses.Basic
+		Orders []*trade.AmendAlgoOrder `json:"data,omitempty"`
+	}
+
 	AlgoOrderListResponse struct {
 		responses.Basic
 		Orders []*trade.AlgoOrder `json:"data,omitempty"`
 	}
+
+	CancelAllAfterResponse struct {
+		responses.Basic
+		Results []*trade.CancelAllAfter `json:"data,omitempty"`
+	}
 )
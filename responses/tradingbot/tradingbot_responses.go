@@ -0,0 +1,33 @@
+package tradingbot
+
+import (
+	models "github.com/yitech/okex/models/tradingbot"
+	"github.com/yitech/okex/responses"
+)
+
+type (
+	GridOrderResult struct {
+		responses.Basic
+		Results []*models.GridOrderResult `json:"data"`
+	}
+	GetGridOrders struct {
+		responses.Basic
+		Orders []*models.GridOrder `json:"data"`
+	}
+	GetGridSubOrders struct {
+		responses.Basic
+		SubOrders []*models.GridSubOrder `json:"data"`
+	}
+	GetGridPositions struct {
+		responses.Basic
+		Positions []*models.GridPosition `json:"data"`
+	}
+	GetGridAIParam struct {
+		responses.Basic
+		Params []*models.AIParam `json:"data"`
+	}
+	ComputeMinInvestment struct {
+		responses.Basic
+		MinInvestments []*models.MinInvestment `json:"data"`
+	}
+)
@@ -0,0 +1,51 @@
+package risk
+
+import "fmt"
+
+// Rule names a pre-trade check
+type Rule string
+
+const (
+	RuleMaxNotional   = Rule("max_notional")
+	RuleMaxPosition   = Rule("max_position")
+	RuleMaxOpenOrders = Rule("max_open_orders")
+	RulePriceBand     = Rule("price_band")
+	RulePriceLimit    = Rule("price_limit")
+	RuleMinSize       = Rule("min_size")
+	RuleLotSize       = Rule("lot_size")
+	RuleFatFinger     = Rule("fat_finger")
+	RuleDailyLoss     = Rule("daily_loss")
+	RuleNoPrice       = Rule("no_price")
+)
+
+// Error is returned when an order is rejected by the risk gate. Nothing is sent to the exchange.
+type Error struct {
+	Rule    Rule
+	InstID  string
+	ClOrdID string
+	Value   float64
+	Limit   float64
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("risk: %s rejected order %s on %s: %g exceeds %g", e.Rule, e.ClOrdID, e.InstID, e.Value, e.Limit)
+}
+
+// Is makes errors.Is match any *Error with the same rule
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Rule == e.Rule && t.InstID == "" && t.ClOrdID == ""
+}
+
+var (
+	ErrMaxNotional   = &Error{Rule: RuleMaxNotional}
+	ErrMaxPosition   = &Error{Rule: RuleMaxPosition}
+	ErrMaxOpenOrders = &Error{Rule: RuleMaxOpenOrders}
+	ErrPriceBand     = &Error{Rule: RulePriceBand}
+	ErrPriceLimit    = &Error{Rule: RulePriceLimit}
+	ErrMinSize       = &Error{Rule: RuleMinSize}
+	ErrLotSize       = &Error{Rule: RuleLotSize}
+	ErrFatFinger     = &Error{Rule: RuleFatFinger}
+	ErrDailyLoss     = &Error{Rule: RuleDailyLoss}
+	ErrNoPrice       = &Error{Rule: RuleNoPrice}
+)
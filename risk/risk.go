@@ -0,0 +1,385 @@
+// Package risk applies configurable pre-trade checks to orders before they leave the process.
+package risk
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/events/public"
+	"github.com/yitech/okex/models/publicdata"
+	"github.com/yitech/okex/oms"
+	"github.com/yitech/okex/position"
+)
+
+type (
+	// Limits are the per-instrument thresholds. A zero value disables the check.
+	Limits struct {
+		// MaxNotional is the largest value of a single order in quote currency
+		MaxNotional float64
+		// MaxPosition is the largest absolute position, in contracts or base currency, an order may lead to once it and
+		// the open orders of the instrument are filled
+		MaxPosition float64
+		// PriceBand is the largest relative distance between a limit price and the last price
+		PriceBand float64
+		// MaxLots is the largest order size expressed in lots
+		MaxLots float64
+	}
+
+	// Config of the risk gate
+	Config struct {
+		Default     Limits
+		Instruments map[string]Limits
+		// MaxOpenOrders is the largest number of orders that may be open at the same time
+		MaxOpenOrders int
+		// DailyLoss stops trading once the PnL since the start of the UTC day falls below its negation
+		DailyLoss float64
+	}
+
+	// Order is the part of an order request the checks look at
+	Order struct {
+		InstID  string
+		ClOrdID string
+		Side    okex.OrderSide
+		OrdType okex.OrderType
+		TgtCcy  okex.QuantityType
+		Px      float64
+		Sz      float64
+		// Amend marks an amendment of the open order PrevSz was the size of, it does not add an open order
+		Amend  bool
+		PrevSz float64
+	}
+
+	// batch is the exposure added by the orders of a batch checked before the current one
+	batch struct {
+		orders int
+		pos    map[string]float64
+	}
+
+	// Decision records the outcome of a check
+	Decision struct {
+		Order   Order
+		Allowed bool
+		Err     error
+		TS      time.Time
+	}
+
+	// DecisionHandler is called for every checked order, whether it was allowed or not
+	DecisionHandler func(d *Decision)
+
+	// OrderSource provides the currently open orders, e.g. an oms.Manager
+	OrderSource interface {
+		OpenOrders() []*oms.Order
+	}
+
+	// PositionSource provides the current positions, e.g. a position.Tracker
+	PositionSource interface {
+		Positions() []*position.Position
+	}
+
+	// Gate evaluates orders against the configured limits
+	Gate struct {
+		mu          sync.RWMutex
+		cfg         Config
+		orders      OrderSource
+		positions   PositionSource
+		instruments map[string]*publicdata.Instrument
+		last        map[string]float64
+		limits      map[string]*publicdata.LimitPrice
+		day         time.Time
+		dayPnl      float64
+		onDecision  []DecisionHandler
+	}
+)
+
+// NewGate returns a pointer to a fresh Gate. Either source may be nil, the checks depending on it are then skipped.
+func NewGate(cfg Config, orders OrderSource, positions PositionSource) *Gate {
+	return &Gate{
+		cfg:         cfg,
+		orders:      orders,
+		positions:   positions,
+		instruments: make(map[string]*publicdata.Instrument),
+		last:        make(map[string]float64),
+		limits:      make(map[string]*publicdata.LimitPrice),
+	}
+}
+
+// SetConfig replaces the configuration
+func (g *Gate) SetConfig(cfg Config) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg
+}
+
+// OnDecision registers an audit handler
+func (g *Gate) OnDecision(h DecisionHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onDecision = append(g.onDecision, h)
+}
+
+// SetInstrument registers the sizes and contract value of an instrument
+func (g *Gate) SetInstrument(i *publicdata.Instrument) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.instruments[i.InstID] = i
+}
+
+// SetLastPrice sets the reference price of an instrument
+func (g *Gate) SetLastPrice(instID string, px float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.last[instID] = px
+}
+
+// HandleTickers updates the last prices from a `tickers` channel push
+func (g *Gate) HandleTickers(e *public.Tickers) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, t := range e.Tickers {
+		g.last[t.InstID] = float64(t.Last)
+	}
+}
+
+// HandlePriceLimit updates the price limits from a `price-limit` channel push
+func (g *Gate) HandlePriceLimit(e *public.PriceLimit) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, l := range e.Limit {
+		g.limits[l.InstID] = l
+	}
+}
+
+// Check evaluates a single order and reports the decision to the audit handlers
+func (g *Gate) Check(o Order) error {
+	return g.CheckAll(o)
+}
+
+// CheckAll evaluates the orders of a batch and returns the first rejection. Each order is checked with the open
+// orders and the position the orders before it in the batch add.
+func (g *Gate) CheckAll(os ...Order) error {
+	var (
+		first error
+		ds    = make([]*Decision, 0, len(os))
+		b     = &batch{pos: make(map[string]float64)}
+	)
+	g.mu.Lock()
+	for _, o := range os {
+		err := g.check(o, b)
+		if err != nil && first == nil {
+			first = err
+		}
+		if !o.Amend {
+			b.orders++
+		}
+		if o.TgtCcy != okex.QuantityQuoteCcy && o.Side != "" {
+			b.pos[o.InstID] += signed(o.Side, o.Sz-o.PrevSz)
+		}
+		ds = append(ds, &Decision{Order: o, Allowed: err == nil, Err: err, TS: time.Now()})
+	}
+	hs := g.onDecision
+	g.mu.Unlock()
+
+	for _, d := range ds {
+		for _, h := range hs {
+			h(d)
+		}
+	}
+	return first
+}
+
+// Amended returns the order an amendment leads to, from the open order it amends. A zero px or sz keeps the one of
+// the open order. The order is only known to the gate with an OrderSource.
+func (g *Gate) Amended(instID, ordID, clOrdID string, px, sz float64) Order {
+	o := Order{InstID: instID, ClOrdID: clOrdID, Px: px, Sz: sz, Amend: true}
+	if g.orders == nil {
+		return o
+	}
+	for _, oo := range g.orders.OpenOrders() {
+		if oo.InstID != instID || (ordID == "" || oo.OrdID != ordID) && (clOrdID == "" || oo.ClOrdID != clOrdID) {
+			continue
+		}
+		o.ClOrdID, o.Side, o.OrdType, o.PrevSz = oo.ClOrdID, oo.Side, oo.OrdType, oo.Sz
+		if o.Px == 0 {
+			o.Px = oo.Px
+		}
+		if o.Sz == 0 {
+			o.Sz = oo.Sz
+		}
+		break
+	}
+	return o
+}
+
+func (g *Gate) check(o Order, b *batch) error {
+	l := g.cfg.Default
+	if il, ok := g.cfg.Instruments[o.InstID]; ok {
+		l = il
+	}
+	reject := func(r Rule, v, lim float64) error {
+		return &Error{Rule: r, InstID: o.InstID, ClOrdID: o.ClOrdID, Value: v, Limit: lim}
+	}
+
+	if g.cfg.DailyLoss > 0 && g.positions != nil {
+		if pnl := g.dailyPnl(); pnl <= -g.cfg.DailyLoss {
+			return reject(RuleDailyLoss, -pnl, g.cfg.DailyLoss)
+		}
+	}
+	if g.cfg.MaxOpenOrders > 0 && g.orders != nil && !o.Amend {
+		if n := len(g.orders.OpenOrders()) + b.orders; n >= g.cfg.MaxOpenOrders {
+			return reject(RuleMaxOpenOrders, float64(n+1), float64(g.cfg.MaxOpenOrders))
+		}
+	}
+
+	inst := g.instruments[o.InstID]
+	if inst != nil && o.TgtCcy != okex.QuantityQuoteCcy {
+		if inst.MinSz > 0 && o.Sz < float64(inst.MinSz) {
+			return reject(RuleMinSize, o.Sz, float64(inst.MinSz))
+		}
+		if lot := float64(inst.LotSz); lot > 0 {
+			lots := o.Sz / lot
+			if math.Abs(lots-math.Round(lots)) > 1e-9*math.Max(1, lots) {
+				return reject(RuleLotSize, o.Sz, lot)
+			}
+			if l.MaxLots > 0 && lots > l.MaxLots {
+				return reject(RuleFatFinger, lots, l.MaxLots)
+			}
+		}
+	}
+
+	last := g.last[o.InstID]
+	px := o.Px
+	if px == 0 {
+		px = last
+	}
+	if px > 0 && last > 0 && o.Px > 0 && l.PriceBand > 0 {
+		if d := math.Abs(o.Px-last) / last; d > l.PriceBand {
+			return reject(RulePriceBand, d, l.PriceBand)
+		}
+	}
+	if lp, ok := g.limits[o.InstID]; ok && o.Px > 0 {
+		if o.Side == okex.OrderBuy && lp.BuyLmt > 0 && o.Px > float64(lp.BuyLmt) {
+			return reject(RulePriceLimit, o.Px, float64(lp.BuyLmt))
+		}
+		if o.Side == okex.OrderSell && lp.SellLmt > 0 && o.Px < float64(lp.SellLmt) {
+			return reject(RulePriceLimit, o.Px, float64(lp.SellLmt))
+		}
+	}
+
+	if l.MaxNotional > 0 {
+		if o.TgtCcy == okex.QuantityQuoteCcy {
+			if o.Sz > l.MaxNotional {
+				return reject(RuleMaxNotional, o.Sz, l.MaxNotional)
+			}
+		} else {
+			if px == 0 {
+				return reject(RuleNoPrice, 0, 0)
+			}
+			if n := notional(inst, px, o.Sz); n > l.MaxNotional {
+				return reject(RuleMaxNotional, n, l.MaxNotional)
+			}
+		}
+	}
+
+	// the side of an amended order is unknown when it is not open in the OrderSource
+	if l.MaxPosition > 0 && g.positions != nil && o.TgtCcy != okex.QuantityQuoteCcy && o.Side != "" {
+		q := signed(o.Side, o.Sz-o.PrevSz)
+		if p := math.Abs(g.netPosition(o.InstID) + g.resting(o.InstID) + b.pos[o.InstID] + q); p > l.MaxPosition {
+			return reject(RuleMaxPosition, p, l.MaxPosition)
+		}
+	}
+	return nil
+}
+
+func (g *Gate) netPosition(instID string) float64 {
+	var n float64
+	for _, p := range g.positions.Positions() {
+		if p.InstID == instID {
+			n += p.Pos
+		}
+	}
+	return n
+}
+
+// resting returns the signed size the open orders of an instrument have left to fill
+func (g *Gate) resting(instID string) float64 {
+	if g.orders == nil {
+		return 0
+	}
+	var n float64
+	for _, o := range g.orders.OpenOrders() {
+		if o.InstID == instID {
+			n += signed(o.Side, o.Sz-o.AccFillSz)
+		}
+	}
+	return n
+}
+
+// Run takes the baseline of the daily loss now and at every start of the UTC day, until the context is done.
+// Without it, the baseline is taken on the first check of the day.
+func (g *Gate) Run(ctx context.Context) error {
+	if g.positions == nil {
+		return nil
+	}
+	for {
+		g.mu.Lock()
+		g.rollover(time.Now())
+		g.mu.Unlock()
+
+		next := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// rollover takes the baseline of the daily loss when the UTC day of now has not got one yet
+func (g *Gate) rollover(now time.Time) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	if !g.day.Equal(today) {
+		g.day = today
+		g.dayPnl = g.totalPnl()
+	}
+}
+
+// dailyPnl returns the PnL since the baseline of the UTC day
+func (g *Gate) dailyPnl() float64 {
+	g.rollover(time.Now())
+	return g.totalPnl() - g.dayPnl
+}
+
+func (g *Gate) totalPnl() float64 {
+	var total float64
+	for _, p := range g.positions.Positions() {
+		total += p.TotalPnl()
+	}
+	return total
+}
+
+func signed(side okex.OrderSide, sz float64) float64 {
+	if side == okex.OrderSell {
+		return -sz
+	}
+	return sz
+}
+
+// notional returns the quote currency value of sz at px. Inverse contracts are already denominated in quote currency.
+func notional(i *publicdata.Instrument, px, sz float64) float64 {
+	if i == nil || i.CtVal == 0 {
+		return px * sz
+	}
+	m := float64(i.CtVal)
+	if i.CtMult > 0 {
+		m *= float64(i.CtMult)
+	}
+	if i.CtType == okex.ContractInverseType {
+		return sz * m
+	}
+	return px * sz * m
+}
@@ -0,0 +1,113 @@
+package risk
+
+import (
+	"strconv"
+
+	"github.com/yitech/okex/api"
+	requests "github.com/yitech/okex/requests/rest/trade"
+	wsRequests "github.com/yitech/okex/requests/ws/trade"
+	responses "github.com/yitech/okex/responses/trade"
+)
+
+var (
+	_ api.TradeAPI    = (*Trade)(nil)
+	_ api.TradeStream = (*WsTrade)(nil)
+)
+
+// Trade is a TradeAPI decorator sending order placement and amendment through the risk gate
+type Trade struct {
+	api.TradeAPI
+	gate *Gate
+}
+
+// NewTrade returns a pointer to a fresh Trade
+func NewTrade(t api.TradeAPI, g *Gate) *Trade {
+	return &Trade{TradeAPI: t, gate: g}
+}
+
+// PlaceOrder checks every order and only sends the batch if all of them are allowed
+func (c *Trade) PlaceOrder(req []requests.PlaceOrderRequest) (response responses.PlaceOrderResponse, err error) {
+	if err = c.gate.CheckAll(fromRest(req)...); err != nil {
+		return
+	}
+	return c.TradeAPI.PlaceOrder(req)
+}
+
+// PlaceMultipleOrders checks every order and only sends the batch if all of them are allowed
+func (c *Trade) PlaceMultipleOrders(req []requests.PlaceOrderRequest) (response responses.PlaceOrderResponse, err error) {
+	if err = c.gate.CheckAll(fromRest(req)...); err != nil {
+		return
+	}
+	return c.TradeAPI.PlaceMultipleOrders(req)
+}
+
+// AmendOrder checks every amended order and only sends the batch if all of them are allowed
+func (c *Trade) AmendOrder(req []requests.AmendOrderRequest) (response responses.AmendOrderResponse, err error) {
+	if err = c.gate.CheckAll(c.gate.fromAmend(req)...); err != nil {
+		return
+	}
+	return c.TradeAPI.AmendOrder(req)
+}
+
+// WsTrade is a TradeStream decorator sending order placement and amendment through the risk gate
+type WsTrade struct {
+	api.TradeStream
+	gate *Gate
+}
+
+// NewWsTrade returns a pointer to a fresh WsTrade
+func NewWsTrade(t api.TradeStream, g *Gate) *WsTrade {
+	return &WsTrade{TradeStream: t, gate: g}
+}
+
+// PlaceOrder checks every order and only sends the batch if all of them are allowed
+func (c *WsTrade) PlaceOrder(req ...wsRequests.PlaceOrder) error {
+	rs := make([]requests.PlaceOrderRequest, len(req))
+	for i, r := range req {
+		rs[i] = r.PlaceOrderRequest
+	}
+	if err := c.gate.CheckAll(fromRest(rs)...); err != nil {
+		return err
+	}
+	return c.TradeStream.PlaceOrder(req...)
+}
+
+// AmendOrder checks every amended order and only sends the batch if all of them are allowed
+func (c *WsTrade) AmendOrder(req ...wsRequests.AmendOrder) error {
+	rs := make([]requests.AmendOrderRequest, len(req))
+	for i, r := range req {
+		rs[i] = r.AmendOrderRequest
+	}
+	if err := c.gate.CheckAll(c.gate.fromAmend(rs)...); err != nil {
+		return err
+	}
+	return c.TradeStream.AmendOrder(req...)
+}
+
+func (g *Gate) fromAmend(req []requests.AmendOrderRequest) []Order {
+	os := make([]Order, len(req))
+	for i, r := range req {
+		px, _ := strconv.ParseFloat(r.NewPx, 64)
+		sz, _ := strconv.ParseFloat(r.NewSz, 64)
+		os[i] = g.Amended(r.InstID, r.OrdId, r.ClOrdId, px, sz)
+	}
+	return os
+}
+
+func fromRest(req []requests.PlaceOrderRequest) []Order {
+	os := make([]Order, len(req))
+	for i, r := range req {
+		px, _ := strconv.ParseFloat(r.Px, 64)
+		sz, _ := strconv.ParseFloat(r.Sz, 64)
+		os[i] = Order{
+			InstID:  r.InstID,
+			ClOrdID: r.ClOrdId,
+			Side:    r.Side,
+			OrdType: r.OrdType,
+			TgtCcy:  r.TgtCcy,
+			Px:      px,
+			Sz:      sz,
+		}
+	}
+	return os
+}
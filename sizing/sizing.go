@@ -0,0 +1,340 @@
+// Package sizing turns a target notional, share of equity or risk budget into an order size the exchange accepts.
+//
+// Sizes are rounded down to the lot size of the instrument and capped by the limits the account endpoints report,
+// so orders are not rejected for their size.
+package sizing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/publicdata"
+	requests "github.com/yitech/okex/requests/rest/account"
+)
+
+// Limit names the exchange limit that capped a size
+type Limit string
+
+const (
+	LimitMaxSize   = Limit("max_size")
+	LimitAvailable = Limit("max_avail_size")
+	LimitLoan      = Limit("max_loan")
+)
+
+var (
+	ErrNoInstrument = errors.New("sizing: instrument is not registered")
+	ErrNoPrice      = errors.New("sizing: no price")
+	ErrBelowMinimum = errors.New("sizing: size is below the minimum order size")
+)
+
+type (
+	// Request describes the order to size. Px is the order price, or the last price for market orders; for options
+	// it is the price of the underlying. Lever is looked up when zero and the trade mode is not cash.
+	Request struct {
+		InstID     string
+		TdMode     okex.TradeMode
+		Side       okex.OrderSide
+		PosSide    okex.PositionSide
+		Ccy        string
+		Px         float64
+		Lever      float64
+		ReduceOnly bool
+	}
+
+	// Limits are the largest sizes of an order reported by the exchange, in contracts for derivatives and base
+	// currency for spot. Loan is only set for margin trades.
+	Limits struct {
+		MaxSize   float64
+		Available float64
+		Loan      float64
+	}
+
+	// Size is a valid order size, in contracts for derivatives and base currency for spot
+	Size struct {
+		InstID string
+		Sz     float64
+		// Target is the size asked for, before rounding and limits
+		Target float64
+		// Max is the largest size the limits allow
+		Max float64
+		// Limit is the limit that capped the size, empty when it was not capped
+		Limit  Limit
+		Lever  float64
+		Limits Limits
+	}
+
+	// Sizer computes order sizes from the instrument specifications and the limits of the account
+	Sizer struct {
+		account     api.AccountAPI
+		mu          sync.RWMutex
+		instruments map[string]*publicdata.Instrument
+	}
+)
+
+// NewSizer returns a pointer to a fresh Sizer
+func NewSizer(a api.AccountAPI) *Sizer {
+	return &Sizer{
+		account:     a,
+		instruments: make(map[string]*publicdata.Instrument),
+	}
+}
+
+// SetInstrument registers the lot size, minimum size and contract value of an instrument
+func (s *Sizer) SetInstrument(i *publicdata.Instrument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instruments[i.InstID] = i
+}
+
+// Size rounds a size down to the lot size and caps it by the limits of the account
+func (s *Sizer) Size(r Request, sz float64) (*Size, error) {
+	inst, err := s.instrument(r.InstID)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.Limits(r)
+	if err != nil {
+		return nil, err
+	}
+	res := &Size{InstID: r.InstID, Target: sz, Lever: r.Lever, Limits: *l, Max: math.Inf(1)}
+	limit := func(name Limit, v float64) {
+		if v < res.Max {
+			res.Max = v
+			if sz > v {
+				res.Limit = name
+			}
+		}
+	}
+	limit(LimitMaxSize, l.MaxSize)
+	if margin(inst, r.TdMode) {
+		limit(LimitLoan, l.Available+l.Loan)
+	} else {
+		limit(LimitAvailable, l.Available)
+	}
+	res.Sz = round(math.Min(sz, res.Max), float64(inst.LotSz))
+	if res.Sz <= 0 || res.Sz < float64(inst.MinSz) {
+		return res, fmt.Errorf("%w: %v of %s, at most %v allowed", ErrBelowMinimum, res.Sz, r.InstID, res.Max)
+	}
+	return res, nil
+}
+
+// ByQuote sizes an order worth an amount of quote currency, or of USD for inverse contracts
+func (s *Sizer) ByQuote(r Request, amt float64) (*Size, error) {
+	inst, err := s.instrument(r.InstID)
+	if err != nil {
+		return nil, err
+	}
+	sz, err := units(inst, r.Px, amt)
+	if err != nil {
+		return nil, err
+	}
+	return s.Size(r, sz)
+}
+
+// ByEquity sizes an order worth a share of the total equity of the account in USD, times the leverage
+func (s *Sizer) ByEquity(r Request, pct float64) (*Size, error) {
+	res, err := s.account.GetBalance(requests.GetBalanceRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("sizing: get balance failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Balances) == 0 {
+		return nil, fmt.Errorf("sizing: no balance")
+	}
+	if r, err = s.leverage(r); err != nil {
+		return nil, err
+	}
+	lever := r.Lever
+	if lever == 0 {
+		lever = 1
+	}
+	return s.ByQuote(r, float64(res.Balances[0].TotalEq)*pct*lever)
+}
+
+// ByVolatility sizes an order so that a price move of vol, relative to the price, e.g. the standard deviation of
+// the returns or the ATR over the price, loses about the risk amount in quote currency
+func (s *Sizer) ByVolatility(r Request, risk, vol float64) (*Size, error) {
+	if vol <= 0 {
+		return nil, fmt.Errorf("sizing: invalid volatility %v", vol)
+	}
+	return s.ByQuote(r, risk/vol)
+}
+
+// Limits returns the largest sizes of an order from the max-size, max-avail-size and, for margin trades, max-loan
+// endpoints
+func (s *Sizer) Limits(r Request) (*Limits, error) {
+	inst, err := s.instrument(r.InstID)
+	if err != nil {
+		return nil, err
+	}
+	buy := r.Side == okex.OrderBuy
+	l := &Limits{}
+
+	mx, err := s.account.GetMaxBuySellAmount(requests.GetMaxBuySellAmountRequest{
+		InstID: []string{r.InstID},
+		TdMode: r.TdMode,
+		Ccy:    r.Ccy,
+		Px:     r.Px,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mx.Code != 0 {
+		return nil, fmt.Errorf("sizing: get max size failed: %d %s", mx.Code, mx.Msg)
+	}
+	for _, m := range mx.MaxBuySellAmounts {
+		if m.InstID == r.InstID {
+			l.MaxSize = float64(m.MaxSell)
+			if buy {
+				l.MaxSize = float64(m.MaxBuy)
+			}
+		}
+	}
+
+	av, err := s.account.GetMaxAvailableTradeAmount(requests.GetMaxAvailableTradeAmountRequest{
+		InstID:     r.InstID,
+		TdMode:     r.TdMode,
+		Ccy:        r.Ccy,
+		ReduceOnly: r.ReduceOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if av.Code != 0 {
+		return nil, fmt.Errorf("sizing: get available size failed: %d %s", av.Code, av.Msg)
+	}
+	for _, a := range av.MaxAvailableTradeAmounts {
+		if a.InstID == r.InstID {
+			l.Available = float64(a.AvailSell)
+			if buy {
+				l.Available = float64(a.AvailBuy)
+			}
+		}
+	}
+	if spot(inst) && buy {
+		// Spot purchases are limited by the quote currency available
+		if l.Available, err = units(inst, r.Px, l.Available); err != nil {
+			return nil, err
+		}
+	}
+
+	if !margin(inst, r.TdMode) || r.ReduceOnly {
+		return l, nil
+	}
+	ln, err := s.account.GetMaxLoan(requests.GetMaxLoanRequest{
+		InstID:  r.InstID,
+		MgnMode: okex.MarginMode(r.TdMode),
+		MgnCcy:  r.Ccy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ln.Code != 0 {
+		return nil, fmt.Errorf("sizing: get max loan failed: %d %s", ln.Code, ln.Msg)
+	}
+	for _, o := range ln.Loans {
+		if o.InstID != r.InstID || (o.Side != "" && o.Side != r.Side) {
+			continue
+		}
+		amt := float64(o.MaxLoan)
+		if o.Ccy == inst.QuoteCcy {
+			if amt, err = units(inst, r.Px, amt); err != nil {
+				return nil, err
+			}
+		}
+		l.Loan = amt
+	}
+	return l, nil
+}
+
+func (s *Sizer) instrument(instID string) (*publicdata.Instrument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	inst, ok := s.instruments[instID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoInstrument, instID)
+	}
+	return inst, nil
+}
+
+// leverage fills in the current leverage of the instrument in the trade mode of the request
+func (s *Sizer) leverage(r Request) (Request, error) {
+	if r.Lever != 0 || r.TdMode == okex.TradeCashMode || r.TdMode == "" {
+		return r, nil
+	}
+	res, err := s.account.GetLeverage(requests.GetLeverageRequest{
+		InstID:  []string{r.InstID},
+		MgnMode: okex.MarginMode(r.TdMode),
+	})
+	if err != nil {
+		return r, err
+	}
+	if res.Code != 0 {
+		return r, fmt.Errorf("sizing: get leverage failed: %d %s", res.Code, res.Msg)
+	}
+	for _, l := range res.Leverages {
+		if l.InstID != r.InstID {
+			continue
+		}
+		if r.PosSide == "" || l.PosSide == r.PosSide || l.PosSide == okex.PositionNetSide {
+			r.Lever = float64(l.Lever)
+			break
+		}
+	}
+	return r, nil
+}
+
+// units converts an amount of quote currency, or of USD for inverse contracts, to an order size
+func units(inst *publicdata.Instrument, px, amt float64) (float64, error) {
+	if spot(inst) {
+		if px <= 0 {
+			return 0, fmt.Errorf("%w for %s", ErrNoPrice, inst.InstID)
+		}
+		return amt / px, nil
+	}
+	m := float64(inst.CtVal)
+	if m == 0 {
+		m = 1
+	}
+	if inst.CtMult > 0 {
+		m *= float64(inst.CtMult)
+	}
+	if inst.CtType == okex.ContractInverseType {
+		return amt / m, nil
+	}
+	if px <= 0 {
+		return 0, fmt.Errorf("%w for %s", ErrNoPrice, inst.InstID)
+	}
+	return amt / (px * m), nil
+}
+
+func spot(inst *publicdata.Instrument) bool {
+	return inst.InstType == okex.SpotInstrument || inst.InstType == okex.MarginInstrument
+}
+
+// margin reports whether an order borrows, i.e. trades spot in a margin mode
+func margin(inst *publicdata.Instrument, mode okex.TradeMode) bool {
+	return spot(inst) && (mode == okex.TradeCrossMode || mode == okex.TradeIsolatedMode)
+}
+
+// round rounds a size down to a whole number of lots, without the float noise of the division
+func round(sz, lot float64) float64 {
+	if lot <= 0 {
+		return sz
+	}
+	n := math.Floor(sz/lot + 1e-9)
+	prec := 0
+	if s := strconv.FormatFloat(lot, 'f', -1, 64); strings.Contains(s, ".") {
+		prec = len(s) - strings.Index(s, ".") - 1
+	}
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(n*lot, 'f', prec, 64), 64)
+	return v
+}
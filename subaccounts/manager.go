@@ -0,0 +1,343 @@
+// Package subaccounts provisions the API keys of the sub-accounts of a master account and hands out ready-to-use
+// clients for them.
+//
+// Credentials are kept in a pluggable SecretStore. The clients share the http connection pool and the rate limiter of
+// the master client; public requests are accounted together, as OKX limits them per IP, and private ones per
+// sub-account, as OKX limits them per user.
+package subaccounts
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	models "github.com/yitech/okex/models/subaccount"
+	requests "github.com/yitech/okex/requests/rest/subaccount"
+)
+
+const listLimit = 100
+
+type (
+	// KeySpec describes the API key of a sub-account
+	KeySpec struct {
+		SubAcct string
+		// Label names the key, the sub-account name when empty
+		Label string
+		Perm  []okex.APIKeyAccess
+		// IP whitelists the addresses the key may be used from, up to 20
+		IP []string
+		// Passphrase of the key, a random one is generated when empty
+		Passphrase string
+	}
+
+	// Manager provisions sub-account keys through the master account and caches a client per sub-account
+	Manager struct {
+		ctx         context.Context
+		master      *api.Client
+		store       SecretStore
+		destination okex.Destination
+		mu          sync.Mutex
+		clients     map[string]*api.Client
+	}
+)
+
+// NewManager returns a pointer to a fresh Manager. The clients it hands out live until ctx is done.
+func NewManager(ctx context.Context, master *api.Client, store SecretStore, destination okex.Destination) *Manager {
+	return &Manager{
+		ctx:         ctx,
+		master:      master,
+		store:       store,
+		destination: destination,
+		clients:     make(map[string]*api.Client),
+	}
+}
+
+// List returns every sub-account of the master, or only the enabled ones
+func (m *Manager) List(enabledOnly bool) ([]*models.SubAccount, error) {
+	var (
+		res   []*models.SubAccount
+		after int64
+	)
+	for {
+		r, err := m.master.SubAccount.ViewList(requests.ViewList{Enable: enabledOnly, After: after, Limit: listLimit})
+		if err != nil {
+			return nil, err
+		}
+		if r.Code != 0 {
+			return nil, fmt.Errorf("subaccounts: view list failed: %d %s", r.Code, r.Msg)
+		}
+		res = append(res, r.SubAccounts...)
+		if len(r.SubAccounts) < listLimit {
+			return res, nil
+		}
+		last := time.Time(r.SubAccounts[len(r.SubAccounts)-1].TS).UnixMilli()
+		if last == after {
+			return res, nil
+		}
+		after = last
+	}
+}
+
+// Provision returns the stored credentials of a sub-account, creating an API key when none are stored
+func (m *Manager) Provision(spec KeySpec) (*Credentials, error) {
+	c, err := m.store.Get(spec.SubAcct)
+	if err == nil {
+		return c, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return m.create(spec)
+}
+
+// Rotate creates a new API key for a sub-account, stores it, then deletes the previous key. Clients handed out
+// before are closed.
+func (m *Manager) Rotate(spec KeySpec) (*Credentials, error) {
+	old, err := m.store.Get(spec.SubAcct)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if old != nil && (spec.Label == "" || spec.Label == old.Label) {
+		// labels are unique per sub-account
+		spec.Label = label(spec) + strconv.FormatInt(time.Now().Unix(), 36)
+	}
+	c, err := m.create(spec)
+	if err != nil {
+		return nil, err
+	}
+	m.drop(spec.SubAcct)
+	if old == nil {
+		return c, nil
+	}
+	if err := m.deleteKey(old); err != nil {
+		return c, fmt.Errorf("subaccounts: new key stored, but deleting the previous one failed: %w", err)
+	}
+	return c, nil
+}
+
+// Update changes the permissions, IP whitelist and label of the stored key of a sub-account
+func (m *Manager) Update(spec KeySpec) (*Credentials, error) {
+	c, err := m.store.Get(spec.SubAcct)
+	if err != nil {
+		return nil, err
+	}
+	r, err := m.master.SubAccount.ResetAPIKey(requests.CreateAPIKey{
+		SubAcct: spec.SubAcct,
+		APIKey:  c.APIKey,
+		Label:   label(spec),
+		Perm:    perm(spec.Perm),
+		IP:      spec.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != 0 {
+		return nil, fmt.Errorf("subaccounts: reset api key failed: %d %s", r.Code, r.Msg)
+	}
+	c.Label, c.Perm, c.IP = label(spec), spec.Perm, spec.IP
+	if err := m.store.Put(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Revoke deletes the stored key of a sub-account on the exchange and from the store, and closes its client
+func (m *Manager) Revoke(subAcct string) error {
+	c, err := m.store.Get(subAcct)
+	if err != nil {
+		return err
+	}
+	m.drop(subAcct)
+	if err := m.deleteKey(c); err != nil {
+		return err
+	}
+	return m.store.Delete(subAcct)
+}
+
+// Verify returns the exchange side of the stored key of a sub-account, failing when the key does not exist anymore
+// or when its IP whitelist differs from the stored one
+func (m *Manager) Verify(subAcct string) (*models.APIKey, error) {
+	c, err := m.store.Get(subAcct)
+	if err != nil {
+		return nil, err
+	}
+	r, err := m.master.SubAccount.QueryAPIKey(requests.QueryAPIKey{SubAcct: subAcct, APIKey: c.APIKey})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != 0 {
+		return nil, fmt.Errorf("subaccounts: query api key failed: %d %s", r.Code, r.Msg)
+	}
+	if len(r.APIKeys) == 0 {
+		return nil, fmt.Errorf("subaccounts: api key of %s not found", subAcct)
+	}
+	k := r.APIKeys[0]
+	if ip := strings.Join(c.IP, ","); !sameIPs(k.IP, ip) {
+		return k, fmt.Errorf("subaccounts: ip whitelist of %s is %q, stored %q", subAcct, k.IP, ip)
+	}
+	return k, nil
+}
+
+// Client returns the client of a sub-account, built from the stored credentials on first use
+func (m *Manager) Client(subAcct string) (*api.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[subAcct]; ok {
+		return c, nil
+	}
+	creds, err := m.store.Get(subAcct)
+	if err != nil {
+		return nil, err
+	}
+	c, err := api.NewClient(m.ctx, creds.APIKey, creds.SecretKey, creds.Passphrase, m.destination)
+	if err != nil {
+		return nil, err
+	}
+	if m.master.Rest != nil {
+		c.Rest.SetHTTPClient(m.master.Rest.HTTPClient())
+		if l := m.master.Rest.Limiter(); l != nil {
+			c.Rest.SetLimiter(l, "sub:"+subAcct)
+		}
+	}
+	m.clients[subAcct] = c
+	return c, nil
+}
+
+func (m *Manager) create(spec KeySpec) (*Credentials, error) {
+	pass := spec.Passphrase
+	if pass == "" {
+		var err error
+		if pass, err = passphrase(); err != nil {
+			return nil, err
+		}
+	}
+	r, err := m.master.SubAccount.CreateAPIKey(requests.CreateAPIKey{
+		SubAcct:    spec.SubAcct,
+		Label:      label(spec),
+		Passphrase: pass,
+		Perm:       perm(spec.Perm),
+		IP:         spec.IP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.Code != 0 {
+		return nil, fmt.Errorf("subaccounts: create api key failed: %d %s", r.Code, r.Msg)
+	}
+	if len(r.APIKeys) == 0 || r.APIKeys[0].SecretKey == "" {
+		return nil, fmt.Errorf("subaccounts: create api key returned no secret for %s", spec.SubAcct)
+	}
+	k := r.APIKeys[0]
+	c := &Credentials{
+		SubAcct:    spec.SubAcct,
+		Label:      label(spec),
+		APIKey:     k.APIKey,
+		SecretKey:  k.SecretKey,
+		Passphrase: pass,
+		Perm:       spec.Perm,
+		IP:         spec.IP,
+		CTime:      time.Now(),
+	}
+	if err := m.store.Put(c); err != nil {
+		return nil, fmt.Errorf("subaccounts: api key %s created but not stored: %w", k.APIKey, err)
+	}
+	return c, nil
+}
+
+func (m *Manager) deleteKey(c *Credentials) error {
+	r, err := m.master.SubAccount.DeleteAPIKey(requests.DeleteAPIKey{SubAcct: c.SubAcct, APIKey: c.APIKey})
+	if err != nil {
+		return err
+	}
+	if r.Code != 0 {
+		return fmt.Errorf("subaccounts: delete api key failed: %d %s", r.Code, r.Msg)
+	}
+	return nil
+}
+
+// drop closes and forgets the client of a sub-account
+func (m *Manager) drop(subAcct string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.clients[subAcct]; ok {
+		if c.Ws != nil {
+			c.Ws.Cancel()
+		}
+		if c.WsBusiness != nil {
+			c.WsBusiness.Cancel()
+		}
+		delete(m.clients, subAcct)
+	}
+}
+
+func label(spec KeySpec) string {
+	if spec.Label != "" {
+		return spec.Label
+	}
+	return spec.SubAcct
+}
+
+func perm(ps []okex.APIKeyAccess) okex.APIKeyAccess {
+	s := make([]string, 0, len(ps))
+	for _, p := range ps {
+		s = append(s, string(p))
+	}
+	return okex.APIKeyAccess(strings.Join(s, ","))
+}
+
+// sameIPs reports whether two comma separated IP lists hold the same addresses, in any order
+func sameIPs(a, b string) bool {
+	split := func(s string) []string {
+		var ips []string
+		for _, ip := range strings.Split(s, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+		sort.Strings(ips)
+		return ips
+	}
+	x, y := split(a), split(b)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// passphrase returns a random passphrase holding the upper case, lower case, digit and special characters OKX asks
+// for
+func passphrase() (string, error) {
+	const (
+		upper   = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+		lower   = "abcdefghijkmnopqrstuvwxyz"
+		digits  = "23456789"
+		special = "!@#$%*"
+	)
+	sets := []string{upper, lower, digits, special}
+	for len(sets) < 20 {
+		sets = append(sets, upper+lower+digits)
+	}
+	var b strings.Builder
+	for _, set := range sets {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(set[n.Int64()])
+	}
+	return b.String(), nil
+}
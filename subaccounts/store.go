@@ -0,0 +1,186 @@
+package subaccounts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+)
+
+// ErrNotFound is returned by secret stores holding no credentials for a sub-account
+var ErrNotFound = errors.New("subaccounts: no credentials stored")
+
+type (
+	// Credentials of a sub-account API key
+	Credentials struct {
+		SubAcct    string              `json:"subAcct"`
+		Label      string              `json:"label"`
+		APIKey     string              `json:"apiKey"`
+		SecretKey  string              `json:"secretKey"`
+		Passphrase string              `json:"passphrase"`
+		Perm       []okex.APIKeyAccess `json:"perm,omitempty"`
+		IP         []string            `json:"ip,omitempty"`
+		CTime      time.Time           `json:"cTime"`
+	}
+
+	// SecretStore keeps the credentials of the sub-accounts, one API key per sub-account. Implementations backed by a
+	// vault or a cloud secret manager plug in here.
+	SecretStore interface {
+		// Get returns ErrNotFound when nothing is stored for the sub-account
+		Get(subAcct string) (*Credentials, error)
+		Put(c *Credentials) error
+		Delete(subAcct string) error
+		List() ([]string, error)
+	}
+
+	// MemoryStore is a SecretStore keeping the credentials in memory only
+	MemoryStore struct {
+		mu    sync.RWMutex
+		creds map[string]Credentials
+	}
+
+	// FileStore is a SecretStore keeping the credentials in a JSON file readable by its owner only
+	FileStore struct {
+		path string
+		mu   sync.Mutex
+	}
+)
+
+// NewMemoryStore returns a pointer to a fresh MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{creds: make(map[string]Credentials)}
+}
+
+// Get implements SecretStore
+func (s *MemoryStore) Get(subAcct string) (*Credentials, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.creds[subAcct]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+// Put implements SecretStore
+func (s *MemoryStore) Put(c *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds[c.SubAcct] = *c
+	return nil
+}
+
+// Delete implements SecretStore
+func (s *MemoryStore) Delete(subAcct string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.creds, subAcct)
+	return nil
+}
+
+// List implements SecretStore
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]string, 0, len(s.creds))
+	for n := range s.creds {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// NewFileStore returns a pointer to a fresh FileStore. The file is created on the first Put.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Get implements SecretStore
+func (s *FileStore) Get(subAcct string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := m[subAcct]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return c, nil
+}
+
+// Put implements SecretStore
+func (s *FileStore) Put(c *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.read()
+	if err != nil {
+		return err
+	}
+	m[c.SubAcct] = c
+	return s.write(m)
+}
+
+// Delete implements SecretStore
+func (s *FileStore) Delete(subAcct string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[subAcct]; !ok {
+		return nil
+	}
+	delete(m, subAcct)
+	return s.write(m)
+}
+
+// List implements SecretStore
+func (s *FileStore) List() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(m))
+	for n := range m {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func (s *FileStore) read() (map[string]*Credentials, error) {
+	m := make(map[string]*Credentials)
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return m, nil
+	}
+	return m, json.Unmarshal(b, &m)
+}
+
+// write replaces the file atomically, so that a crash never leaves it half written
+func (s *FileStore) write(m map[string]*Credentials) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
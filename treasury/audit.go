@@ -0,0 +1,34 @@
+package treasury
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// auditLine is a Record as written to the audit log
+type auditLine struct {
+	TS       time.Time `json:"ts"`
+	PlanID   string    `json:"planId"`
+	ClientID string    `json:"clientId"`
+	TransID  string    `json:"transId,omitempty"`
+	*Transfer
+	Error string `json:"error,omitempty"`
+}
+
+// NewAuditLog returns a RecordHandler writing every record to w as a line of JSON. Write errors are ignored, wrap w
+// to report them.
+func NewAuditLog(w io.Writer) RecordHandler {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(r *Record) {
+		l := auditLine{TS: r.TS, PlanID: r.PlanID, ClientID: r.ClientID, TransID: r.TransID, Transfer: r.Transfer}
+		if r.Err != nil {
+			l.Error = r.Err.Error()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(l)
+	}
+}
@@ -0,0 +1,604 @@
+// Package treasury moves funds between the master account and its sub-accounts according to declarative rules:
+// sweeping profits back to the master, topping up sub-accounts below a floor and keeping target allocations between
+// sub-accounts.
+//
+// Transfers are first planned, the plan is then reviewed and executed. Every transfer is capped per transfer and per
+// UTC day, and reported to the audit handlers with the exchange transfer ID.
+package treasury
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/account"
+	fundingRequests "github.com/yitech/okex/requests/rest/funding"
+	subAccountRequests "github.com/yitech/okex/requests/rest/subaccount"
+)
+
+// Master names the master account in transfers
+const Master = ""
+
+// RuleKind is the kind of rule a transfer was planned by
+type RuleKind string
+
+const (
+	RuleSweep      = RuleKind("sweep")
+	RuleTopUp      = RuleKind("top_up")
+	RuleAllocation = RuleKind("allocation")
+)
+
+var (
+	ErrStalePlan    = errors.New("treasury: plan is too old, plan again")
+	ErrPlanExecuted = errors.New("treasury: plan was already executed")
+)
+
+type (
+	// Sweep moves the balance of a sub-account above Threshold back to the funding account of the master, leaving
+	// Keep, or Threshold when Keep is zero
+	Sweep struct {
+		SubAcct   string  `json:"subAcct"`
+		Ccy       string  `json:"ccy"`
+		Threshold float64 `json:"threshold"`
+		Keep      float64 `json:"keep,omitempty"`
+	}
+
+	// TopUp moves funds from the funding account of the master to a sub-account whose balance is below Floor, up to
+	// Target, or Floor when Target is zero
+	TopUp struct {
+		SubAcct string  `json:"subAcct"`
+		Ccy     string  `json:"ccy"`
+		Floor   float64 `json:"floor"`
+		Target  float64 `json:"target,omitempty"`
+	}
+
+	// Allocation splits the balance held by a group of sub-accounts by weight. Funds are moved once the share of a
+	// sub-account drifts from its target by more than Tolerance, a fraction of the total.
+	Allocation struct {
+		Name      string             `json:"name"`
+		Ccy       string             `json:"ccy"`
+		Weights   map[string]float64 `json:"weights"`
+		Tolerance float64            `json:"tolerance,omitempty"`
+	}
+
+	// Cap limits the transfers of a currency. A zero value disables the limit.
+	Cap struct {
+		// PerTransfer is the largest amount of a single transfer, larger ones are reduced to it
+		PerTransfer float64 `json:"perTransfer,omitempty"`
+		// Daily is the largest amount transferred since the start of the UTC day
+		Daily float64 `json:"daily,omitempty"`
+		// Min is the smallest amount worth a transfer
+		Min float64 `json:"min,omitempty"`
+	}
+
+	// Config of the treasury. Rules are planned in order: sweeps, top-ups, then allocations, each seeing the
+	// balances left by the previous ones.
+	Config struct {
+		Sweeps      []Sweep        `json:"sweeps,omitempty"`
+		TopUps      []TopUp        `json:"topUps,omitempty"`
+		Allocations []Allocation   `json:"allocations,omitempty"`
+		Caps        map[string]Cap `json:"caps,omitempty"`
+		// MaxPlanAge is how long a plan may be executed after it was made, a minute when zero
+		MaxPlanAge time.Duration `json:"maxPlanAge,omitempty"`
+	}
+
+	// Transfer is a planned movement of funds. From and To are sub-account names, or Master.
+	Transfer struct {
+		Kind RuleKind `json:"kind"`
+		Rule string   `json:"rule"`
+		Ccy  string   `json:"ccy"`
+		Amt  float64  `json:"amt"`
+		From string   `json:"from"`
+		To   string   `json:"to"`
+		// Reason tells why the transfer was reduced or skipped
+		Reason string `json:"reason,omitempty"`
+	}
+
+	// Plan is the set of transfers the rules call for at the time it was made
+	Plan struct {
+		ID        string      `json:"id"`
+		Transfers []*Transfer `json:"transfers"`
+		// Skipped are the transfers left out by the caps
+		Skipped []*Transfer `json:"skipped,omitempty"`
+		TS      time.Time   `json:"ts"`
+	}
+
+	// Record is the audit entry of an executed transfer
+	Record struct {
+		PlanID   string
+		ClientID string
+		TransID  string
+		Transfer *Transfer
+		Err      error
+		TS       time.Time
+	}
+
+	// PlanHandler is called with every plan made by Run
+	PlanHandler func(p *Plan)
+
+	// RecordHandler is called for every executed transfer, whether it succeeded or not
+	RecordHandler func(r *Record)
+
+	balanceKey struct {
+		acct string
+		ccy  string
+	}
+
+	// balance of a currency in an account, level is what the rules compare and avail what may be moved out
+	balance struct {
+		level float64
+		avail float64
+	}
+
+	// Treasury plans and executes the transfers
+	Treasury struct {
+		sub      api.SubAccountAPI
+		funding  api.FundingAPI
+		mu       sync.RWMutex
+		exec     sync.Mutex
+		cfg      Config
+		day      time.Time
+		used     map[string]float64
+		executed map[string]bool
+		onPlan   []PlanHandler
+		onRecord []RecordHandler
+	}
+)
+
+// NewTreasury returns a pointer to a fresh Treasury. Both clients belong to the master account.
+func NewTreasury(cfg Config, sub api.SubAccountAPI, funding api.FundingAPI) *Treasury {
+	return &Treasury{
+		sub:      sub,
+		funding:  funding,
+		cfg:      cfg,
+		used:     make(map[string]float64),
+		executed: make(map[string]bool),
+	}
+}
+
+// SetConfig replaces the configuration
+func (t *Treasury) SetConfig(cfg Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg = cfg
+}
+
+// OnPlan registers a handler for the plans made by Run
+func (t *Treasury) OnPlan(h PlanHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPlan = append(t.onPlan, h)
+}
+
+// OnRecord registers an audit handler
+func (t *Treasury) OnRecord(h RecordHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRecord = append(t.onRecord, h)
+}
+
+// Used returns the amount of a currency transferred since the start of the UTC day
+func (t *Treasury) Used(ccy string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollDay(time.Now())
+	return t.used[ccy]
+}
+
+// LoadUsage counts the transfers between the master and the sub-accounts made since the start of the UTC day, so
+// that the daily caps hold across restarts. Transfers between sub-accounts are only counted by the process making
+// them.
+func (t *Treasury) LoadUsage() error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	used := make(map[string]float64)
+	var after int64
+	for {
+		res, err := t.sub.HistoryTransfer(subAccountRequests.HistoryTransfer{After: after, Limit: 100})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("treasury: get transfer history failed: %d %s", res.Code, res.Msg)
+		}
+		done := len(res.HistoryTransfers) < 100
+		for _, h := range res.HistoryTransfers {
+			if time.Time(h.TS).Before(day) {
+				done = true
+				continue
+			}
+			used[h.Ccy] += math.Abs(float64(h.Amt))
+			after = int64(h.BillID)
+		}
+		if done || after == 0 {
+			break
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.day = day
+	t.used = used
+	return nil
+}
+
+// Plan fetches the balances and returns the transfers the rules call for. Nothing is moved.
+func (t *Treasury) Plan() (*Plan, error) {
+	t.mu.RLock()
+	cfg := t.cfg
+	t.mu.RUnlock()
+
+	bals, err := t.balances(cfg)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	t.mu.Lock()
+	t.rollDay(now)
+	used := make(map[string]float64, len(t.used))
+	for ccy, v := range t.used {
+		used[ccy] = v
+	}
+	t.mu.Unlock()
+
+	p := &Plan{ID: "tr" + strconv.FormatInt(now.UnixNano(), 36), TS: now}
+	add := func(tr *Transfer) {
+		c := cfg.Caps[tr.Ccy]
+		if c.PerTransfer > 0 && tr.Amt > c.PerTransfer {
+			tr.Amt, tr.Reason = c.PerTransfer, "per-transfer cap"
+		}
+		if c.Daily > 0 && used[tr.Ccy]+tr.Amt > c.Daily {
+			tr.Amt, tr.Reason = c.Daily-used[tr.Ccy], "daily cap"
+		}
+		tr.Amt = floor(tr.Amt)
+		if tr.Amt <= 0 || tr.Amt < c.Min {
+			if tr.Reason == "" {
+				tr.Reason = "below minimum"
+			}
+			p.Skipped = append(p.Skipped, tr)
+			return
+		}
+		used[tr.Ccy] += tr.Amt
+		from, to := bals[balanceKey{acct: tr.From, ccy: tr.Ccy}], bals[balanceKey{acct: tr.To, ccy: tr.Ccy}]
+		from.level -= tr.Amt
+		from.avail -= tr.Amt
+		to.level += tr.Amt
+		to.avail += tr.Amt
+		p.Transfers = append(p.Transfers, tr)
+	}
+
+	for _, s := range cfg.Sweeps {
+		b := bals[balanceKey{acct: s.SubAcct, ccy: s.Ccy}]
+		keep := s.Keep
+		if keep == 0 {
+			keep = s.Threshold
+		}
+		if b.level <= s.Threshold {
+			continue
+		}
+		if amt := math.Min(b.level-keep, b.avail); amt > 0 {
+			add(&Transfer{Kind: RuleSweep, Rule: "sweep " + s.SubAcct, Ccy: s.Ccy, Amt: amt, From: s.SubAcct, To: Master})
+		}
+	}
+	for _, u := range cfg.TopUps {
+		b := bals[balanceKey{acct: u.SubAcct, ccy: u.Ccy}]
+		target := math.Max(u.Target, u.Floor)
+		if b.level >= u.Floor {
+			continue
+		}
+		m := bals[balanceKey{acct: Master, ccy: u.Ccy}]
+		if amt := math.Min(target-b.level, m.avail); amt > 0 {
+			add(&Transfer{Kind: RuleTopUp, Rule: "top-up " + u.SubAcct, Ccy: u.Ccy, Amt: amt, From: Master, To: u.SubAcct})
+		} else {
+			p.Skipped = append(p.Skipped, &Transfer{Kind: RuleTopUp, Rule: "top-up " + u.SubAcct, Ccy: u.Ccy,
+				Amt: target - b.level, From: Master, To: u.SubAcct, Reason: "master funding balance exhausted"})
+		}
+	}
+	for _, a := range cfg.Allocations {
+		for _, tr := range allocate(a, bals) {
+			add(tr)
+		}
+	}
+	return p, nil
+}
+
+// Execute makes the transfers of a plan in order and stops at the first failure, as the following transfers were
+// planned on its outcome. A plan is executed at most once, and only while it is younger than MaxPlanAge.
+func (t *Treasury) Execute(p *Plan) ([]*Record, error) {
+	t.exec.Lock()
+	defer t.exec.Unlock()
+
+	t.mu.Lock()
+	maxAge := t.cfg.MaxPlanAge
+	if maxAge == 0 {
+		maxAge = time.Minute
+	}
+	if t.executed[p.ID] {
+		t.mu.Unlock()
+		return nil, ErrPlanExecuted
+	}
+	if time.Since(p.TS) > maxAge {
+		t.mu.Unlock()
+		return nil, ErrStalePlan
+	}
+	t.executed[p.ID] = true
+	caps := t.cfg.Caps
+	t.mu.Unlock()
+
+	var res []*Record
+	for i, tr := range p.Transfers {
+		r := &Record{PlanID: p.ID, ClientID: p.ID + strconv.Itoa(i), Transfer: tr}
+		t.mu.Lock()
+		t.rollDay(time.Now())
+		c := caps[tr.Ccy]
+		switch {
+		case c.PerTransfer > 0 && tr.Amt > c.PerTransfer:
+			r.Err = fmt.Errorf("treasury: transfer of %g %s exceeds the per-transfer cap of %g", tr.Amt, tr.Ccy, c.PerTransfer)
+		case c.Daily > 0 && t.used[tr.Ccy]+tr.Amt > c.Daily:
+			r.Err = fmt.Errorf("treasury: transfer of %g %s exceeds the daily cap of %g, %g used", tr.Amt, tr.Ccy, c.Daily, t.used[tr.Ccy])
+		default:
+			// counted before the call, a failed request may still have moved the funds
+			t.used[tr.Ccy] += tr.Amt
+		}
+		t.mu.Unlock()
+		if r.Err == nil {
+			r.TransID, r.Err = t.transfer(tr, r.ClientID)
+		}
+		r.TS = time.Now()
+		t.record(r)
+		res = append(res, r)
+		if r.Err != nil {
+			return res, r.Err
+		}
+	}
+	return res, nil
+}
+
+// Run plans on every tick and executes the plans unless dryRun is set, until the context is done. Plans are handed to
+// the plan handlers before they are executed.
+func (t *Treasury) Run(ctx context.Context, interval time.Duration, dryRun bool) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p, err := t.Plan()
+			if err != nil {
+				continue
+			}
+			t.mu.RLock()
+			hs := t.onPlan
+			t.mu.RUnlock()
+			for _, h := range hs {
+				h(p)
+			}
+			if !dryRun && len(p.Transfers) > 0 {
+				_, _ = t.Execute(p)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Treasury) transfer(tr *Transfer, clientID string) (string, error) {
+	switch {
+	case tr.From == Master:
+		return t.fundsTransfer(fundingRequests.FundsTransfer{
+			Ccy:      tr.Ccy,
+			Amt:      tr.Amt,
+			SubAcct:  tr.To,
+			ClientID: clientID,
+			Type:     okex.MasterAccountToSubAccount,
+			From:     okex.FundingAccount,
+			To:       okex.UnifiedAccount,
+		})
+	case tr.To == Master:
+		return t.fundsTransfer(fundingRequests.FundsTransfer{
+			Ccy:      tr.Ccy,
+			Amt:      tr.Amt,
+			SubAcct:  tr.From,
+			ClientID: clientID,
+			Type:     okex.MasterSubAccountToAccount,
+			From:     okex.UnifiedAccount,
+			To:       okex.FundingAccount,
+		})
+	}
+	res, err := t.sub.ManageTransfers(subAccountRequests.ManageTransfers{
+		Ccy:            tr.Ccy,
+		Amt:            tr.Amt,
+		FromSubAccount: tr.From,
+		ToSubAccount:   tr.To,
+		From:           okex.UnifiedAccount,
+		To:             okex.UnifiedAccount,
+	})
+	if err != nil {
+		return "", err
+	}
+	if res.Code != 0 {
+		return "", fmt.Errorf("treasury: transfer between sub-accounts failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Transfers) == 0 {
+		return "", nil
+	}
+	return strconv.FormatInt(int64(res.Transfers[0].TransID), 10), nil
+}
+
+func (t *Treasury) fundsTransfer(req fundingRequests.FundsTransfer) (string, error) {
+	res, err := t.funding.FundsTransfer(req)
+	if err != nil {
+		return "", err
+	}
+	if res.Code != 0 {
+		return "", fmt.Errorf("treasury: funds transfer failed: %d %s", res.Code, res.Msg)
+	}
+	if len(res.Transfers) == 0 {
+		return "", nil
+	}
+	return res.Transfers[0].TransID, nil
+}
+
+// balances fetches the trading balances of the sub-accounts named by the rules and the funding balance of the master
+func (t *Treasury) balances(cfg Config) (map[balanceKey]*balance, error) {
+	subs := make(map[string]bool)
+	ccys := make(map[string]bool)
+	for _, s := range cfg.Sweeps {
+		subs[s.SubAcct], ccys[s.Ccy] = true, true
+	}
+	for _, u := range cfg.TopUps {
+		subs[u.SubAcct], ccys[u.Ccy] = true, true
+	}
+	for _, a := range cfg.Allocations {
+		ccys[a.Ccy] = true
+		for s := range a.Weights {
+			subs[s] = true
+		}
+	}
+
+	res := make(map[balanceKey]*balance)
+	get := func(k balanceKey) *balance {
+		b, ok := res[k]
+		if !ok {
+			b = &balance{}
+			res[k] = b
+		}
+		return b
+	}
+	for k := range ccys {
+		get(balanceKey{acct: Master, ccy: k})
+	}
+	for s := range subs {
+		r, err := t.sub.GetBalance(subAccountRequests.GetBalance{SubAcct: s})
+		if err != nil {
+			return nil, err
+		}
+		if r.Code != 0 {
+			return nil, fmt.Errorf("treasury: get balance of %s failed: %d %s", s, r.Code, r.Msg)
+		}
+		for k := range ccys {
+			get(balanceKey{acct: s, ccy: k})
+		}
+		for _, b := range r.Balances {
+			for _, d := range b.Details {
+				addDetails(get(balanceKey{acct: s, ccy: d.Ccy}), d)
+			}
+		}
+	}
+	if len(ccys) > 0 {
+		req := fundingRequests.GetBalance{}
+		for k := range ccys {
+			req.Ccy = append(req.Ccy, k)
+		}
+		sort.Strings(req.Ccy)
+		r, err := t.funding.GetBalance(req)
+		if err != nil {
+			return nil, err
+		}
+		if r.Code != 0 {
+			return nil, fmt.Errorf("treasury: get funding balance failed: %d %s", r.Code, r.Msg)
+		}
+		for _, b := range r.Balances {
+			m := get(balanceKey{acct: Master, ccy: b.Ccy})
+			bal, _ := strconv.ParseFloat(b.Bal, 64)
+			avail, _ := strconv.ParseFloat(b.AvailBal, 64)
+			m.level += bal
+			m.avail += avail
+		}
+	}
+	return res, nil
+}
+
+func (t *Treasury) record(r *Record) {
+	t.mu.RLock()
+	hs := t.onRecord
+	t.mu.RUnlock()
+	for _, h := range hs {
+		h(r)
+	}
+}
+
+func (t *Treasury) rollDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if day.After(t.day) {
+		t.day = day
+		t.used = make(map[string]float64)
+	}
+}
+
+// allocate returns the transfers bringing the sub-accounts of an allocation back to their weights, from the most
+// over-allocated to the most under-allocated
+func allocate(a Allocation, bals map[balanceKey]*balance) []*Transfer {
+	type share struct {
+		acct  string
+		drift float64
+	}
+	var total, weights float64
+	for s, w := range a.Weights {
+		total += bals[balanceKey{acct: s, ccy: a.Ccy}].level
+		weights += w
+	}
+	if total <= 0 || weights <= 0 {
+		return nil
+	}
+	var over, under []*share
+	var worst float64
+	for s, w := range a.Weights {
+		b := bals[balanceKey{acct: s, ccy: a.Ccy}]
+		d := b.level - total*w/weights
+		worst = math.Max(worst, math.Abs(d)/total)
+		if d > 0 {
+			over = append(over, &share{acct: s, drift: math.Min(d, b.avail)})
+		} else if d < 0 {
+			under = append(under, &share{acct: s, drift: -d})
+		}
+	}
+	if worst <= a.Tolerance {
+		return nil
+	}
+	sort.Slice(over, func(i, j int) bool {
+		if over[i].drift != over[j].drift {
+			return over[i].drift > over[j].drift
+		}
+		return over[i].acct < over[j].acct
+	})
+	sort.Slice(under, func(i, j int) bool {
+		if under[i].drift != under[j].drift {
+			return under[i].drift > under[j].drift
+		}
+		return under[i].acct < under[j].acct
+	})
+
+	var res []*Transfer
+	for i, j := 0, 0; i < len(over) && j < len(under); {
+		amt := math.Min(over[i].drift, under[j].drift)
+		if amt > 0 {
+			res = append(res, &Transfer{Kind: RuleAllocation, Rule: "allocation " + a.Name, Ccy: a.Ccy, Amt: amt,
+				From: over[i].acct, To: under[j].acct})
+		}
+		over[i].drift -= amt
+		under[j].drift -= amt
+		if over[i].drift <= 0 {
+			i++
+		}
+		if under[j].drift <= 0 {
+			j++
+		}
+	}
+	return res
+}
+
+func addDetails(b *balance, d *account.BalanceDetails) {
+	b.level += float64(d.CashBal)
+	b.avail += float64(d.AvailBal)
+}
+
+// floor rounds an amount down to 8 decimals, the finest precision transfers accept
+func floor(v float64) float64 {
+	return math.Floor(v*1e8+1e-6) / 1e8
+}
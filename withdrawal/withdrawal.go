@@ -0,0 +1,671 @@
+// Package withdrawal guards withdrawals behind an allow-list of destinations, per-currency limits and a two-step
+// propose and approve flow, and tracks the submitted withdrawals until they complete, fail or are canceled.
+//
+// Proposals are checked against the chain metadata of the currencies: withdrawals must be enabled, within the chain
+// minimum and maximum, a multiple of its tick size, and pay a fee between the minimum and maximum fee.
+package withdrawal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yitech/okex"
+	"github.com/yitech/okex/api"
+	"github.com/yitech/okex/models/funding"
+	requests "github.com/yitech/okex/requests/rest/funding"
+)
+
+// State of a withdrawal in the pipeline
+type State string
+
+const (
+	StateProposed  = State("proposed")
+	StateApproved  = State("approved")
+	StateRejected  = State("rejected")
+	StateExpired   = State("expired")
+	StateDryRun    = State("dry_run")
+	StateSubmitted = State("submitted")
+	StateCanceling = State("canceling")
+	StateCompleted = State("completed")
+	StateFailed    = State("failed")
+	StateCanceled  = State("canceled")
+)
+
+var (
+	ErrNotFound      = errors.New("withdrawal: not found")
+	ErrNotAllowed    = errors.New("withdrawal: destination is not allow-listed")
+	ErrUnknownChain  = errors.New("withdrawal: unknown chain")
+	ErrChainDisabled = errors.New("withdrawal: withdrawals are disabled on the chain")
+	ErrAmount        = errors.New("withdrawal: amount out of the chain bounds")
+	ErrFee           = errors.New("withdrawal: fee out of the chain bounds")
+	ErrLimit         = errors.New("withdrawal: limit exceeded")
+	ErrRejected      = errors.New("withdrawal: rejected")
+	ErrState         = errors.New("withdrawal: not allowed in the current state")
+	ErrNoApprovers   = errors.New("withdrawal: no approvers configured")
+)
+
+type (
+	// Destination is an allow-listed address. Chain is the OKX chain name, e.g. USDT-TRC20; internal destinations,
+	// an OKX account email, phone or UID, leave it empty.
+	Destination struct {
+		Ccy   string                     `json:"ccy"`
+		Chain string                     `json:"chain,omitempty"`
+		Addr  string                     `json:"addr"`
+		Dest  okex.WithdrawalDestination `json:"dest,omitempty"`
+		Label string                     `json:"label,omitempty"`
+	}
+
+	// Limit bounds the withdrawals of a currency. A zero value disables the limit.
+	Limit struct {
+		PerWithdrawal float64 `json:"perWithdrawal,omitempty"`
+		// Daily bounds the amount proposed, pending and sent since the start of the UTC day
+		Daily float64 `json:"daily,omitempty"`
+	}
+
+	// Config of the pipeline
+	Config struct {
+		AllowList []Destination    `json:"allowList"`
+		Limits    map[string]Limit `json:"limits,omitempty"`
+		// ProposalTTL is how long a proposal may wait for approval and execution, an hour when zero
+		ProposalTTL time.Duration `json:"proposalTtl,omitempty"`
+		// DryRun runs every check but never sends a withdrawal
+		DryRun bool `json:"dryRun,omitempty"`
+	}
+
+	// Request is what a withdrawal is proposed with. The fee defaults to the chain minimum.
+	Request struct {
+		Ccy      string
+		Chain    string
+		Addr     string
+		Amt      float64
+		Fee      float64
+		Proposer string
+		Memo     string
+	}
+
+	// Vote is the decision of an approver
+	Vote struct {
+		Approver string    `json:"approver"`
+		Approved bool      `json:"approved"`
+		Reason   string    `json:"reason,omitempty"`
+		TS       time.Time `json:"ts"`
+	}
+
+	// Withdrawal is a withdrawal through the pipeline. ID is sent as the client ID of the withdrawal.
+	Withdrawal struct {
+		ID            string                     `json:"id"`
+		Ccy           string                     `json:"ccy"`
+		Chain         string                     `json:"chain,omitempty"`
+		Addr          string                     `json:"addr"`
+		Dest          okex.WithdrawalDestination `json:"dest"`
+		Amt           float64                    `json:"amt"`
+		Fee           float64                    `json:"fee"`
+		Proposer      string                     `json:"proposer,omitempty"`
+		Memo          string                     `json:"memo,omitempty"`
+		Votes         []Vote                     `json:"votes,omitempty"`
+		State         State                      `json:"state"`
+		WdID          int64                      `json:"wdId,omitempty"`
+		TxID          string                     `json:"txId,omitempty"`
+		ExchangeState okex.WithdrawalState       `json:"exchangeState"`
+		Err           string                     `json:"error,omitempty"`
+		CTime         time.Time                  `json:"cTime"`
+		UTime         time.Time                  `json:"uTime"`
+	}
+
+	// Approver decides on proposals, e.g. by asking an operator or a policy service. A withdrawal is approved once
+	// every approver approved it.
+	Approver interface {
+		Name() string
+		Approve(w Withdrawal) (approved bool, reason string, err error)
+	}
+
+	// UpdateHandler is called with a copy of a withdrawal whenever its state changes
+	UpdateHandler func(w *Withdrawal)
+
+	// Pipeline proposes, approves, sends and tracks withdrawals
+	Pipeline struct {
+		funding    api.FundingAPI
+		mu         sync.RWMutex
+		cfg        Config
+		chains     map[string]*funding.Currency
+		approvers  []Approver
+		ws         map[string]*Withdrawal
+		day        time.Time
+		sent       map[string]float64
+		onUpdate   []UpdateHandler
+		pending    []*Withdrawal
+		submitting sync.Mutex
+	}
+)
+
+// NewPipeline returns a pointer to a fresh Pipeline
+func NewPipeline(cfg Config, f api.FundingAPI) *Pipeline {
+	return &Pipeline{
+		funding: f,
+		cfg:     cfg,
+		chains:  make(map[string]*funding.Currency),
+		ws:      make(map[string]*Withdrawal),
+		sent:    make(map[string]float64),
+	}
+}
+
+// SetConfig replaces the configuration
+func (p *Pipeline) SetConfig(cfg Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg = cfg
+}
+
+// SetApprovers replaces the approvers
+func (p *Pipeline) SetApprovers(as ...Approver) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.approvers = as
+}
+
+// OnUpdate registers a handler for state changes
+func (p *Pipeline) OnUpdate(h UpdateHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onUpdate = append(p.onUpdate, h)
+}
+
+// LoadCurrencies fetches the chain metadata of the currencies
+func (p *Pipeline) LoadCurrencies() error {
+	res, err := p.funding.GetCurrencies()
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("withdrawal: get currencies failed: %d %s", res.Code, res.Msg)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range res.Currencies {
+		p.chains[c.Chain] = c
+	}
+	return nil
+}
+
+// LoadUsage counts the withdrawals sent since the start of the UTC day towards the daily limits, so that they hold
+// across restarts. Failed and canceled withdrawals are left out.
+func (p *Pipeline) LoadUsage() error {
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	sent := make(map[string]float64)
+	var after int64
+	for {
+		res, err := p.funding.GetWithdrawalHistory(requests.GetWithdrawalHistory{After: after, Limit: 100})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("withdrawal: get withdrawal history failed: %d %s", res.Code, res.Msg)
+		}
+		done := len(res.WithdrawalHistories) < 100
+		for _, h := range res.WithdrawalHistories {
+			ts := time.Time(h.TS)
+			if ts.Before(day) {
+				done = true
+				continue
+			}
+			after = ts.UnixMilli()
+			if h.State != okex.WithdrawalFailed && h.State != okex.WithdrawalCanceled {
+				sent[h.Ccy] += float64(h.Amt)
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.day = day
+	p.sent = sent
+	return nil
+}
+
+// Propose checks a request and records it as a proposal awaiting approval
+func (p *Pipeline) Propose(req Request) (*Withdrawal, error) {
+	defer p.flush()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.expire(now)
+
+	d, ok := p.allowed(req.Ccy, req.Chain, req.Addr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s %s", ErrNotAllowed, req.Ccy, req.Chain, req.Addr)
+	}
+	w := &Withdrawal{
+		ID:       "wd" + strconv.FormatInt(now.UnixNano(), 36),
+		Ccy:      req.Ccy,
+		Chain:    req.Chain,
+		Addr:     req.Addr,
+		Dest:     d.Dest,
+		Amt:      req.Amt,
+		Fee:      req.Fee,
+		Proposer: req.Proposer,
+		Memo:     req.Memo,
+		State:    StateProposed,
+		CTime:    now,
+		UTime:    now,
+	}
+	if w.Dest == 0 {
+		w.Dest = okex.WithdrawalDigitalAddressDestination
+	}
+	if err := p.checkChain(w); err != nil {
+		return nil, err
+	}
+	if err := p.checkLimits(w, now); err != nil {
+		return nil, err
+	}
+	p.ws[w.ID] = w
+	c := *w
+	p.notify(&c)
+	return &c, nil
+}
+
+// Approve asks every approver about a proposal. It is approved when all of them approve, and rejected as soon as one
+// of them rejects it. Without approvers it fails and the proposal stays proposed.
+func (p *Pipeline) Approve(id string) (*Withdrawal, error) {
+	defer p.flush()
+	p.mu.Lock()
+	p.expire(time.Now())
+	w, ok := p.ws[id]
+	if !ok {
+		p.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if w.State != StateProposed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is %s", ErrState, id, w.State)
+	}
+	as := p.approvers
+	if len(as) == 0 {
+		p.mu.Unlock()
+		return nil, ErrNoApprovers
+	}
+	c := *w
+	p.mu.Unlock()
+
+	votes := make([]Vote, 0, len(as))
+	var rejected error
+	for _, a := range as {
+		ok, reason, err := a.Approve(c)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, Vote{Approver: a.Name(), Approved: ok, Reason: reason, TS: time.Now()})
+		if !ok {
+			rejected = fmt.Errorf("%w by %s: %s", ErrRejected, a.Name(), reason)
+			break
+		}
+	}
+
+	p.mu.Lock()
+	if w.State != StateProposed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is %s", ErrState, id, w.State)
+	}
+	w.Votes = append(w.Votes, votes...)
+	w.State = StateApproved
+	if rejected != nil {
+		w.State, w.Err = StateRejected, rejected.Error()
+	}
+	w.UTime = time.Now()
+	c = *w
+	p.notify(&c)
+	p.mu.Unlock()
+	return &c, rejected
+}
+
+// Execute sends an approved withdrawal, checking the allow-list and the limits again. In dry-run mode the
+// withdrawal ends in StateDryRun without being sent.
+func (p *Pipeline) Execute(id string) (*Withdrawal, error) {
+	defer p.flush()
+	p.submitting.Lock()
+	defer p.submitting.Unlock()
+
+	p.mu.Lock()
+	now := time.Now()
+	p.expire(now)
+	w, ok := p.ws[id]
+	if !ok {
+		p.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	if w.State != StateApproved {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is %s", ErrState, id, w.State)
+	}
+	var err error
+	if _, ok := p.allowed(w.Ccy, w.Chain, w.Addr); !ok {
+		err = fmt.Errorf("%w: %s %s %s", ErrNotAllowed, w.Ccy, w.Chain, w.Addr)
+	} else if err = p.checkChain(w); err == nil {
+		err = p.checkLimits(w, now)
+	}
+	if err != nil {
+		w.State, w.Err, w.UTime = StateFailed, err.Error(), now
+		c := *w
+		p.notify(&c)
+		p.mu.Unlock()
+		return &c, err
+	}
+	if p.cfg.DryRun {
+		w.State, w.UTime = StateDryRun, now
+		c := *w
+		p.notify(&c)
+		p.mu.Unlock()
+		return &c, nil
+	}
+	// counted before the call, a failed request may still have been sent
+	p.sent[w.Ccy] += w.Amt
+	req := requests.Withdrawal{
+		Ccy:      w.Ccy,
+		Chain:    w.Chain,
+		ToAddr:   w.Addr,
+		Amt:      w.Amt,
+		Fee:      w.Fee,
+		Dest:     w.Dest,
+		ClientID: w.ID,
+	}
+	p.mu.Unlock()
+
+	res, err := p.funding.Withdrawal(req)
+	if err == nil && res.Code != 0 {
+		err = fmt.Errorf("withdrawal: withdrawal failed: %d %s", res.Code, res.Msg)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w.UTime = time.Now()
+	switch {
+	case err != nil:
+		// the outcome is unknown after a transport error, tracking finds the withdrawal by its client ID
+		w.State, w.Err = StateSubmitted, err.Error()
+		if res.Code != 0 {
+			w.State = StateFailed
+			p.sent[w.Ccy] -= w.Amt
+		}
+	case len(res.Withdrawals) > 0:
+		w.State, w.WdID = StateSubmitted, int64(res.Withdrawals[0].WdID)
+	default:
+		w.State = StateSubmitted
+	}
+	c := *w
+	p.notify(&c)
+	return &c, err
+}
+
+// Cancel drops a proposal, or asks the exchange to cancel a submitted withdrawal. Canceled withdrawals reach
+// StateCanceled once tracking sees them canceled.
+func (p *Pipeline) Cancel(id string) (*Withdrawal, error) {
+	defer p.flush()
+	p.mu.Lock()
+	w, ok := p.ws[id]
+	if !ok {
+		p.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	switch w.State {
+	case StateProposed, StateApproved:
+		w.State, w.UTime = StateCanceled, time.Now()
+		c := *w
+		p.notify(&c)
+		p.mu.Unlock()
+		return &c, nil
+	case StateSubmitted:
+	default:
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is %s", ErrState, id, w.State)
+	}
+	if w.WdID == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s has no withdrawal ID yet, track it first", ErrState, id)
+	}
+	wdID := w.WdID
+	p.mu.Unlock()
+
+	res, err := p.funding.CancelWithdrawal(requests.CancelWithdrawal{WdID: wdID})
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != 0 {
+		return nil, fmt.Errorf("withdrawal: cancel withdrawal failed: %d %s", res.Code, res.Msg)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w.State == StateSubmitted {
+		w.State, w.UTime = StateCanceling, time.Now()
+	}
+	c := *w
+	p.notify(&c)
+	return &c, nil
+}
+
+// Track refreshes the submitted withdrawals from the withdrawal history
+func (p *Pipeline) Track() error {
+	defer p.flush()
+	p.mu.RLock()
+	var ids []string
+	for id, w := range p.ws {
+		if w.State == StateSubmitted || w.State == StateCanceling {
+			ids = append(ids, id)
+		}
+	}
+	p.mu.RUnlock()
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		res, err := p.funding.GetWithdrawalHistory(requests.GetWithdrawalHistory{ClientID: id})
+		if err != nil {
+			return err
+		}
+		if res.Code != 0 {
+			return fmt.Errorf("withdrawal: get withdrawal history failed: %d %s", res.Code, res.Msg)
+		}
+		if len(res.WithdrawalHistories) == 0 {
+			continue
+		}
+		p.apply(id, res.WithdrawalHistories[0])
+	}
+	return nil
+}
+
+// Run tracks the submitted withdrawals on every tick until the context is done
+func (p *Pipeline) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = p.Track()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Get returns a copy of a withdrawal
+func (p *Pipeline) Get(id string) (*Withdrawal, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	w, ok := p.ws[id]
+	if !ok {
+		return nil, false
+	}
+	c := *w
+	return &c, true
+}
+
+// Withdrawals returns copies of every withdrawal, the oldest first
+func (p *Pipeline) Withdrawals() []*Withdrawal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	res := make([]*Withdrawal, 0, len(p.ws))
+	for _, w := range p.ws {
+		c := *w
+		res = append(res, &c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CTime.Before(res[j].CTime)
+	})
+	return res
+}
+
+func (p *Pipeline) apply(id string, h *funding.WithdrawalHistory) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w, ok := p.ws[id]
+	if !ok {
+		return
+	}
+	prev, prevExchange, prevTx := w.State, w.ExchangeState, w.TxID
+	w.WdID, w.TxID, w.ExchangeState = int64(h.WdID), h.TxID, h.State
+	switch h.State {
+	case okex.WithdrawalSent:
+		w.State = StateCompleted
+	case okex.WithdrawalFailed:
+		w.State = StateFailed
+		p.unsend(w)
+	case okex.WithdrawalCanceled:
+		w.State = StateCanceled
+		p.unsend(w)
+	}
+	if w.State == prev && h.State == prevExchange && h.TxID == prevTx {
+		return
+	}
+	w.UTime = time.Now()
+	c := *w
+	p.notify(&c)
+}
+
+// unsend gives the amount of a withdrawal that never left back to the daily limit
+func (p *Pipeline) unsend(w *Withdrawal) {
+	if !w.CTime.Before(p.day) {
+		p.sent[w.Ccy] = math.Max(p.sent[w.Ccy]-w.Amt, 0)
+	}
+}
+
+func (p *Pipeline) allowed(ccy, chain, addr string) (Destination, bool) {
+	for _, d := range p.cfg.AllowList {
+		if d.Ccy != ccy || d.Chain != chain {
+			continue
+		}
+		if d.Addr == addr || strings.HasPrefix(addr, "0x") && strings.EqualFold(d.Addr, addr) {
+			return d, true
+		}
+	}
+	return Destination{}, false
+}
+
+func (p *Pipeline) checkChain(w *Withdrawal) error {
+	if w.Dest == okex.WithdrawalOkexDestination {
+		w.Fee = 0
+		return nil
+	}
+	c, ok := p.chains[w.Chain]
+	if !ok || c.Ccy != w.Ccy {
+		return fmt.Errorf("%w: %s %s, load the currencies first", ErrUnknownChain, w.Ccy, w.Chain)
+	}
+	if !c.CanWd {
+		return fmt.Errorf("%w: %s", ErrChainDisabled, w.Chain)
+	}
+	minWd, maxWd, tick, minFee, maxFee := num(c.MinWd), num(c.MaxWd), num(c.WdTickSz), num(c.MinFee), num(c.MaxFee)
+	if w.Amt <= 0 || w.Amt < minWd || maxWd > 0 && w.Amt > maxWd {
+		return fmt.Errorf("%w: %g %s, between %g and %g", ErrAmount, w.Amt, w.Ccy, minWd, maxWd)
+	}
+	if tick > 0 && !multiple(w.Amt, tick) {
+		return fmt.Errorf("%w: %g %s is not a multiple of %g", ErrAmount, w.Amt, w.Ccy, tick)
+	}
+	if w.Fee == 0 {
+		w.Fee = minFee
+	}
+	if w.Fee < minFee || maxFee > 0 && w.Fee > maxFee {
+		return fmt.Errorf("%w: %g %s, between %g and %g", ErrFee, w.Fee, w.Ccy, minFee, maxFee)
+	}
+	return nil
+}
+
+// checkLimits counts the proposals and approved withdrawals waiting besides w against the daily limit
+func (p *Pipeline) checkLimits(w *Withdrawal, now time.Time) error {
+	p.rollDay(now)
+	l := p.cfg.Limits[w.Ccy]
+	if l.PerWithdrawal > 0 && w.Amt > l.PerWithdrawal {
+		return fmt.Errorf("%w: %g %s above %g per withdrawal", ErrLimit, w.Amt, w.Ccy, l.PerWithdrawal)
+	}
+	if l.Daily <= 0 {
+		return nil
+	}
+	used := p.sent[w.Ccy]
+	for _, o := range p.ws {
+		if o.ID != w.ID && o.Ccy == w.Ccy && (o.State == StateProposed || o.State == StateApproved) {
+			used += o.Amt
+		}
+	}
+	if used+w.Amt > l.Daily {
+		return fmt.Errorf("%w: %g %s above the daily %g, %g used or waiting", ErrLimit, w.Amt, w.Ccy, l.Daily, used)
+	}
+	return nil
+}
+
+// expire ends the proposals older than the TTL
+func (p *Pipeline) expire(now time.Time) {
+	ttl := p.cfg.ProposalTTL
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	for _, w := range p.ws {
+		if (w.State == StateProposed || w.State == StateApproved) && now.Sub(w.CTime) > ttl {
+			w.State, w.UTime = StateExpired, now
+			c := *w
+			p.notify(&c)
+		}
+	}
+}
+
+func (p *Pipeline) rollDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if day.After(p.day) {
+		p.day = day
+		p.sent = make(map[string]float64)
+	}
+}
+
+// notify queues an update, it is called with the lock held
+func (p *Pipeline) notify(w *Withdrawal) {
+	p.pending = append(p.pending, w)
+}
+
+// flush hands the queued updates to the handlers, outside the lock
+func (p *Pipeline) flush() {
+	p.mu.Lock()
+	ws, hs := p.pending, p.onUpdate
+	p.pending = nil
+	p.mu.Unlock()
+	for _, w := range ws {
+		for _, h := range hs {
+			h(w)
+		}
+	}
+}
+
+// num parses a limit of the currencies endpoint, an empty one is zero
+func num(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func multiple(v, step float64) bool {
+	r := v / step
+	return math.Abs(r-math.Round(r)) < 1e-9
+}